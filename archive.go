@@ -0,0 +1,106 @@
+package merkle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+)
+
+var ErrDuplicateEntry = errors.New("duplicate archive entry")
+
+// FileLeaf encodes a file as leaf data: the uvarint length of its
+// slash-separated path, the path, and the SHA-256 of its contents
+func FileLeaf(name string, content []byte) []byte {
+	sum := sha256.Sum256(content)
+	leaf := binary.AppendUvarint(nil, uint64(len(name)))
+	leaf = append(leaf, name...)
+	return append(leaf, sum[:]...)
+}
+
+// NewFromTar creates a Merkle tree with a FileLeaf for every regular file of
+// a tar stream, ordered by path, and returns the paths in leaf order
+func NewFromTar(r io.Reader, opts ...Option) (*MerkleTree, []string, error) {
+	files := map[string][]byte{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := addFile(files, hdr.Name, content); err != nil {
+			return nil, nil, err
+		}
+	}
+	return newFromFiles(files, opts...)
+}
+
+// NewFromZip creates a Merkle tree with a FileLeaf for every file of a zip
+// archive, ordered by path, and returns the paths in leaf order
+func NewFromZip(r *zip.Reader, opts ...Option) (*MerkleTree, []string, error) {
+	files := map[string][]byte{}
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := addFile(files, f.Name, content); err != nil {
+			return nil, nil, err
+		}
+	}
+	return newFromFiles(files, opts...)
+}
+
+// addFile records the leaf of an archive entry under its cleaned path
+func addFile(files map[string][]byte, name string, content []byte) error {
+	name = path.Clean(name)
+	if _, ok := files[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateEntry, name)
+	}
+	files[name] = FileLeaf(name, content)
+	return nil
+}
+
+// newFromFiles creates a tree over file leaves ordered by path
+func newFromFiles(files map[string][]byte, opts ...Option) (*MerkleTree, []string, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	data := make([][]byte, len(names))
+	for i, name := range names {
+		data[i] = files[name]
+	}
+	tree, err := New(data, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tree, names, nil
+}
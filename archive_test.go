@@ -0,0 +1,79 @@
+package merkle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var archiveFiles = []struct{ name, content string }{
+	{"bin/tool", "binary"},
+	{"README", "readme"},
+	{"./docs/guide.md", "guide"},
+}
+
+func Test_NewFromTar(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "bin/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	for _, f := range archiveFiles {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: f.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(f.content))}))
+		_, err := tw.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	t.Run("should order entries by path", func(t *testing.T) {
+		tree, names, err := NewFromTar(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		require.Equal(t, []string{"README", "bin/tool", "docs/guide.md"}, names)
+
+		leaf := FileLeaf("docs/guide.md", []byte("guide"))
+		proof, err := tree.GenerateProofAt(2)
+		require.NoError(t, err)
+		require.True(t, tree.VerifyData(leaf, proof))
+	})
+
+	t.Run("should return error for duplicate entries", func(t *testing.T) {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, name := range []string{"a", "./a"} {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644}))
+		}
+		require.NoError(t, tw.Close())
+
+		_, _, err := NewFromTar(&buf)
+		require.ErrorIs(t, err, ErrDuplicateEntry)
+	})
+}
+
+func Test_NewFromZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range archiveFiles {
+		w, err := zw.Create(f.name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	t.Run("should match the tree of the same files as a tar", func(t *testing.T) {
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		tree, names, err := NewFromZip(zr)
+		require.NoError(t, err)
+		require.Equal(t, []string{"README", "bin/tool", "docs/guide.md"}, names)
+
+		want, err := New([][]byte{
+			FileLeaf("README", []byte("readme")),
+			FileLeaf("bin/tool", []byte("binary")),
+			FileLeaf("docs/guide.md", []byte("guide")),
+		})
+		require.NoError(t, err)
+		require.Equal(t, want.Root(), tree.Root())
+	})
+}
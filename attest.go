@@ -0,0 +1,104 @@
+package merkle
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidSignature = errors.New("invalid attestation signature")
+
+// Statement is the signed part of an attestation
+type Statement struct {
+	Root      []byte    `json:"root"`
+	Files     int       `json:"files"`
+	Builder   string    `json:"builder"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Attestation is a signed statement about the root of a directory tree, with
+// a proof for each of its files
+type Attestation struct {
+	Statement Statement        `json:"statement"`
+	Signature []byte           `json:"signature"`
+	Proofs    map[string]Proof `json:"proofs"`
+}
+
+// AttestConfig identifies the builder and key an attestation is made with
+type AttestConfig struct {
+	Builder string
+	// Time is the statement's timestamp; defaults to now
+	Time    time.Time
+	Key     ed25519.PrivateKey
+	Options []Option
+}
+
+// Attest hashes every file below dir as NewFromDir does, without a prefix,
+// and signs a statement about the root with a proof for every file
+func Attest(dir string, cfg AttestConfig) (Attestation, error) {
+	tree, names, err := NewFromDir(dir, "", cfg.Options...)
+	if err != nil {
+		return Attestation{}, err
+	}
+
+	if cfg.Time.IsZero() {
+		cfg.Time = time.Now()
+	}
+	a := Attestation{
+		Statement: Statement{Root: tree.Root(), Files: len(names), Builder: cfg.Builder, Timestamp: cfg.Time.UTC()},
+		Proofs:    make(map[string]Proof, len(names)),
+	}
+	for i, name := range names {
+		if a.Proofs[name], err = tree.GenerateProofAt(i); err != nil {
+			return Attestation{}, err
+		}
+	}
+
+	msg, err := json.Marshal(a.Statement)
+	if err != nil {
+		return Attestation{}, err
+	}
+	a.Signature = ed25519.Sign(cfg.Key, msg)
+
+	return a, nil
+}
+
+// VerifySignature checks the statement's signature
+func (a Attestation) VerifySignature(pub ed25519.PublicKey) error {
+	msg, err := json.Marshal(a.Statement)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, msg, a.Signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyFile checks the statement's signature and that the file with the
+// given relative name and content is included under its root
+func (a Attestation) VerifyFile(pub ed25519.PublicKey, name string, content []byte, opts ...Option) error {
+	if err := a.VerifySignature(pub); err != nil {
+		return err
+	}
+
+	proof, ok := a.Proofs[name]
+	if !ok {
+		return ErrNotFoundData
+	}
+	leaf, err := DirhashLeaf(name, content)
+	if err != nil {
+		return err
+	}
+
+	m := newVerifier(opts...)
+	hash, err := m.hashData(leaf)
+	if err != nil {
+		return err
+	}
+	if !m.provesRoot(m.newHash(), hash, proof, a.Statement.Root) {
+		return ErrInvalidProof
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package merkle
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Attest(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bin", "tool"), []byte("binary"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "LICENSE"), []byte("license"), 0o644))
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	a, err := Attest(dir, AttestConfig{Builder: "ci@example.com", Time: at, Key: priv})
+	require.NoError(t, err)
+
+	t.Run("should sign a statement about the directory root", func(t *testing.T) {
+		require.Equal(t, Statement{Root: a.Statement.Root, Files: 2, Builder: "ci@example.com", Timestamp: at}, a.Statement)
+		require.NoError(t, a.VerifySignature(pub))
+		require.Len(t, a.Proofs, 2)
+	})
+
+	t.Run("should verify files after a round trip", func(t *testing.T) {
+		b, err := json.Marshal(a)
+		require.NoError(t, err)
+		var decoded Attestation
+		require.NoError(t, json.Unmarshal(b, &decoded))
+
+		require.NoError(t, decoded.VerifyFile(pub, "bin/tool", []byte("binary")))
+		require.ErrorIs(t, decoded.VerifyFile(pub, "bin/tool", []byte("tampered")), ErrInvalidProof)
+		require.ErrorIs(t, decoded.VerifyFile(pub, "missing", nil), ErrNotFoundData)
+	})
+
+	t.Run("should return error for tampered statements", func(t *testing.T) {
+		tampered := a
+		tampered.Statement.Builder = "attacker"
+		require.ErrorIs(t, tampered.VerifySignature(pub), ErrInvalidSignature)
+		require.ErrorIs(t, tampered.VerifyFile(pub, "LICENSE", []byte("license")), ErrInvalidSignature)
+	})
+}
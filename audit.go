@@ -0,0 +1,62 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+)
+
+var ErrInteriorPreimage = errors.New("leaf data has the length of an interior node preimage")
+
+// WithLogger sets the logger the tree reports warnings to, instead of slog's default logger
+func WithLogger(l *slog.Logger) Option {
+	return func(m *MerkleTree) {
+		m.logger = l
+	}
+}
+
+// WithPreimageAudit guards trees kept without domain separation for legacy
+// compatibility against second preimage attacks. Without distinct leaf and
+// node prefixes, the preimage left || right of an interior node is also a
+// valid leaf, so a proof shortened by one level "proves" it. The audit rejects
+// leaf data exactly two hashes long when building, adding, updating, proving
+// and verifying, and logs a warning for each rejection. It has no effect on
+// domain separated trees.
+func WithPreimageAudit() Option {
+	return func(m *MerkleTree) {
+		m.preimageAudit = true
+	}
+}
+
+// auditLeaf rejects leaf data that could be an interior node preimage, when
+// the preimage audit is on and leaves are not domain separated
+func (m *MerkleTree) auditLeaf(data []byte) error {
+	if !m.preimageAudit || len(data) != 2*m.hashSize() {
+		return nil
+	}
+	if m.scheme == nil && !bytes.Equal(m.leafPrefix, m.nodePrefix) {
+		return nil
+	}
+	if m.scheme != nil && m.scheme.DomainSeparated {
+		return nil
+	}
+
+	logger := m.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Warn("merkle: rejected leaf with the length of an interior node preimage", "size", len(data))
+	return ErrInteriorPreimage
+}
+
+// hashSize returns the size of the tree's leaf and node hashes
+func (m *MerkleTree) hashSize() int {
+	size := m.hashFn().Size()
+	if m.scheme != nil {
+		size = m.scheme.HashSize
+	}
+	if m.truncate > 0 && m.truncate < size {
+		size = m.truncate
+	}
+	return size
+}
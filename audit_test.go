@@ -0,0 +1,119 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithPreimageAudit(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
+
+	// the preimage of the interior node over a and b, and the proof of that
+	// node, which verifies it as a leaf one level up
+	forge := func(t *testing.T, tree *MerkleTree) ([]byte, Proof) {
+		proof, err := tree.GenerateProof([]byte("a"))
+		require.NoError(t, err)
+		leaf := tree.hashLeaf([]byte("a"))
+		return append(leaf, proof[0].Hash...), proof[1:]
+	}
+
+	t.Run("should document the attack without domain separation", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		forged, proof := forge(t, tree)
+		require.True(t, tree.VerifyData(forged, proof))
+	})
+
+	t.Run("should reject interior node preimages when verifying", func(t *testing.T) {
+		var logs bytes.Buffer
+		opts := []Option{WithPreimageAudit(), WithLogger(slog.New(slog.NewTextHandler(&logs, nil)))}
+		tree, err := New(data, opts...)
+		require.NoError(t, err)
+		forged, proof := forge(t, tree)
+
+		require.False(t, tree.VerifyData(forged, proof))
+		require.False(t, VerifyDataProof(tree.Root(), forged, proof, opts...))
+		require.Contains(t, logs.String(), "interior node preimage")
+	})
+
+	t.Run("should reject leaves of two hash lengths", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+		long := bytes.Repeat([]byte("x"), 2*sha256.Size)
+
+		_, err := New([][]byte{long}, WithPreimageAudit(), WithLogger(logger))
+		require.ErrorIs(t, err, ErrInteriorPreimage)
+
+		tree, err := New(data, WithPreimageAudit(), WithLogger(logger))
+		require.NoError(t, err)
+		require.ErrorIs(t, tree.UpdateLeaf([]byte("a"), long), ErrInteriorPreimage)
+		_, err = tree.GenerateProof(long)
+		require.ErrorIs(t, err, ErrInteriorPreimage)
+
+		_, err = NewBuilder(WithPreimageAudit(), WithLogger(logger)).Add([]byte("a")).Add(long).Build()
+		require.ErrorIs(t, err, ErrInteriorPreimage)
+		store := NewChunkStore(WithPreimageAudit(), WithLogger(logger))
+		_, err = store.NewTree([][]byte{[]byte("a"), long})
+		require.ErrorIs(t, err, ErrInteriorPreimage)
+		require.Zero(t, store.Len())
+
+		require.ErrorIs(t, tree.AddLeaves([][]byte{[]byte("e"), long}), ErrInteriorPreimage)
+		require.ErrorIs(t, tree.AddLeaf(long), ErrInteriorPreimage)
+		require.Len(t, tree.Leaves(), 4)
+	})
+
+	t.Run("should reject leaves of two hash lengths on every entry point", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+		opts := []Option{WithPreimageAudit(), WithLogger(logger)}
+		long := bytes.Repeat([]byte("x"), 2*sha256.Size)
+
+		_, err := NewPersistent([][]byte{long}, opts...)
+		require.ErrorIs(t, err, ErrInteriorPreimage)
+		p, err := NewPersistent(data, opts...)
+		require.NoError(t, err)
+		_, err = p.AddLeaf(long)
+		require.ErrorIs(t, err, ErrInteriorPreimage)
+		_, err = p.UpdateLeaf([]byte("a"), long)
+		require.ErrorIs(t, err, ErrInteriorPreimage)
+
+		require.ErrorIs(t, NewCompactAppender(opts...).Append(long), ErrInteriorPreimage)
+		_, err = RootFromSource(&sliceSource{leaves: [][]byte{[]byte("a"), long}}, opts...)
+		require.ErrorIs(t, err, ErrInteriorPreimage)
+		_, err = NewNary([][]byte{long}, opts...)
+		require.ErrorIs(t, err, ErrInteriorPreimage)
+		_, err = ExtendSquare([][]byte{long}, opts...)
+		require.ErrorIs(t, err, ErrInteriorPreimage)
+
+		tree, err := New(data)
+		require.NoError(t, err)
+		forged, proof := forge(t, tree)
+		require.False(t, VerifyUnion(tree.Root(), nil, forged, UnionProof{InFirst: true, Proof: proof}, opts...))
+		require.False(t, VerifySpanProof(tree.Root(), forged, SpanProof{Proof: proof}, opts...))
+		require.False(t, VerifyShare(tree.Root(), forged, proof, opts...))
+	})
+
+	t.Run("should use the truncated hash size", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+		_, err := New([][]byte{make([]byte, 40)}, WithPreimageAudit(), WithHashTruncation(20), WithLogger(logger))
+		require.ErrorIs(t, err, ErrInteriorPreimage)
+	})
+
+	t.Run("should not apply to domain separated trees", func(t *testing.T) {
+		tree, err := New(data, WithPreimageAudit(), WithRFC6962())
+		require.NoError(t, err)
+		forged, proof := forge(t, tree)
+		require.False(t, tree.VerifyData(forged, proof))
+
+		_, err = New([][]byte{make([]byte, 2*sha256.Size)}, WithPreimageAudit(), WithRFC6962())
+		require.NoError(t, err)
+		_, err = New([][]byte{make([]byte, 2*sha256.Size)}, WithPreimageAudit(), WithScheme(SchemeRFC6962))
+		require.NoError(t, err)
+
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+		_, err = New([][]byte{make([]byte, 2*sha256.Size)}, WithPreimageAudit(), WithScheme(SchemeBitcoin), WithLogger(logger))
+		require.ErrorIs(t, err, ErrInteriorPreimage)
+	})
+}
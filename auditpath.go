@@ -0,0 +1,61 @@
+package merkle
+
+import "bytes"
+
+// AuditPath is a proof in the audit path convention of RFC 6962 and most
+// on-chain verifiers: only the sibling hashes, with every side derived from the
+// leaf index and the tree size
+type AuditPath struct {
+	Index    int
+	TreeSize int
+	Hashes   [][]byte
+}
+
+// GenerateAuditPath generates an audit path for a given leaf
+func (m *MerkleTree) GenerateAuditPath(data []byte) (AuditPath, error) {
+	index := m.leafIndex(data)
+	if index < 0 {
+		return AuditPath{}, ErrNotFoundData
+	}
+	return m.GenerateAuditPathAt(index)
+}
+
+// GenerateAuditPathAt generates an audit path for the leaf at the given index
+func (m *MerkleTree) GenerateAuditPathAt(index int) (AuditPath, error) {
+	proof, err := m.GenerateProofAt(index)
+	if err != nil {
+		return AuditPath{}, err
+	}
+
+	path := AuditPath{Index: index, TreeSize: len(m.leafs), Hashes: make([][]byte, len(proof))}
+	for i, pe := range proof {
+		path.Hashes[i] = pe.Hash
+	}
+	return path, nil
+}
+
+// VerifyAuditPath verifies an audit path for the given leaf hash
+func (m *MerkleTree) VerifyAuditPath(hash []byte, path AuditPath) bool {
+	proof, err := m.AuditPathProof(path)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(m.rootFromProof(m.newHash(), hash, proof), m.root.hash)
+}
+
+// AuditPathProof expands an audit path into a Proof with explicit sides
+func (m *MerkleTree) AuditPathProof(path AuditPath) (Proof, error) {
+	sides, err := proofSides(path.Index, path.TreeSize, m.promoteOdd)
+	if err != nil {
+		return nil, err
+	}
+	if len(sides) != len(path.Hashes) {
+		return nil, ErrMalformed
+	}
+
+	proof := make(Proof, len(sides))
+	for i, side := range sides {
+		proof[i] = ProofElement{Hash: path.Hashes[i], Side: side}
+	}
+	return proof, nil
+}
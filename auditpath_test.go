@@ -0,0 +1,52 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GenerateAuditPath(t *testing.T) {
+	for name, opts := range map[string][]Option{"default": nil, "rfc6962": {WithRFC6962()}} {
+		t.Run("should verify audit paths for every leaf in "+name+" mode", func(t *testing.T) {
+			var data [][]byte
+			for i := 0; i < 11; i++ {
+				data = append(data, []byte(fmt.Sprint(i)))
+			}
+			tree, err := New(data, opts...)
+			require.NoError(t, err)
+
+			for i, item := range data {
+				path, err := tree.GenerateAuditPath(item)
+				require.NoError(t, err)
+				require.Equal(t, i, path.Index)
+				require.Equal(t, len(data), path.TreeSize)
+				require.True(t, tree.VerifyAuditPath(tree.hashLeaf(item), path))
+
+				proof, err := tree.AuditPathProof(path)
+				require.NoError(t, err)
+				expected, err := tree.GenerateProof(item)
+				require.NoError(t, err)
+				require.Equal(t, expected, proof)
+			}
+		})
+	}
+
+	t.Run("should not verify an audit path at the wrong index", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}, WithHashFunction(mockHash))
+		require.NoError(t, err)
+		path, err := tree.GenerateAuditPathAt(1)
+		require.NoError(t, err)
+
+		path.Index = 0
+		require.False(t, tree.VerifyAuditPath([]byte("hash(b)"), path))
+	})
+
+	t.Run("should return error for non-existent data", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a")})
+		require.NoError(t, err)
+		_, err = tree.GenerateAuditPath([]byte("b"))
+		require.ErrorIs(t, err, ErrNotFoundData)
+	})
+}
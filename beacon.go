@@ -0,0 +1,61 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/bits"
+)
+
+// Generalized indices of the Ethereum light-client branches in the beacon state
+const (
+	FinalizedRootGindex        uint64 = 105
+	CurrentSyncCommitteeGindex uint64 = 54
+	NextSyncCommitteeGindex    uint64 = 55
+
+	FinalizedRootGindexElectra        uint64 = 169
+	CurrentSyncCommitteeGindexElectra uint64 = 86
+	NextSyncCommitteeGindexElectra    uint64 = 87
+)
+
+// BranchProof converts an SSZ Merkle branch for the node at a generalized index
+// into a Proof, taking each sibling's side from the bits of the index
+func BranchProof(branch [][]byte, gindex uint64) (Proof, error) {
+	if gindex == 0 {
+		return nil, ErrOutOfRange
+	}
+	if depth := bits.Len64(gindex) - 1; len(branch) != depth {
+		return nil, ErrMalformed
+	}
+
+	proof := make(Proof, len(branch))
+	for i, hash := range branch {
+		if gindex>>i&1 == 1 {
+			proof[i] = ProofElement{Hash: hash, Side: Left}
+		} else {
+			proof[i] = ProofElement{Hash: hash, Side: Right}
+		}
+	}
+	return proof, nil
+}
+
+// VerifyBranch verifies an Ethereum light-client branch (such as a finality or
+// sync committee branch) for a 32-byte leaf at a generalized index against a
+// state root, using SSZ merkleization
+func VerifyBranch(leaf []byte, branch [][]byte, gindex uint64, root []byte) bool {
+	proof, err := BranchProof(branch, gindex)
+	if err != nil {
+		return false
+	}
+
+	// SSZ chunks are committed as-is, so the leaf is the first node hash
+	m := &MerkleTree{hashFn: sha256.New}
+	hash := leaf
+	for _, pe := range proof {
+		if pe.Side == Left {
+			hash = m.hashNode(pe.Hash, hash)
+		} else {
+			hash = m.hashNode(hash, pe.Hash)
+		}
+	}
+	return bytes.Equal(hash, root)
+}
@@ -0,0 +1,57 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_VerifyBranch(t *testing.T) {
+	// build an SSZ-style tree over 8 chunks, where generalized index 1 is the root
+	nodes := make([][]byte, 16)
+	for i := 8; i < 16; i++ {
+		chunk := sha256.Sum256([]byte{byte(i)})
+		nodes[i] = chunk[:]
+	}
+	for i := 7; i >= 1; i-- {
+		h := sha256.Sum256(append(append([]byte{}, nodes[2*i]...), nodes[2*i+1]...))
+		nodes[i] = h[:]
+	}
+	branch := func(gindex uint64) [][]byte {
+		var b [][]byte
+		for ; gindex > 1; gindex /= 2 {
+			b = append(b, nodes[gindex^1])
+		}
+		return b
+	}
+
+	t.Run("should verify a valid branch", func(t *testing.T) {
+		for gindex := uint64(2); gindex < 16; gindex++ {
+			require.True(t, VerifyBranch(nodes[gindex], branch(gindex), gindex, nodes[1]))
+		}
+	})
+
+	t.Run("should not verify a branch at the wrong index", func(t *testing.T) {
+		require.False(t, VerifyBranch(nodes[13], branch(13), 12, nodes[1]))
+	})
+
+	t.Run("should not verify a branch of the wrong depth", func(t *testing.T) {
+		require.False(t, VerifyBranch(nodes[13], branch(13)[1:], 13, nodes[1]))
+	})
+}
+
+func Test_BranchProof(t *testing.T) {
+	t.Run("should derive sides from the generalized index", func(t *testing.T) {
+		proof, err := BranchProof([][]byte{[]byte("a"), []byte("b"), []byte("c")}, 13) // 0b1101
+		require.NoError(t, err)
+		require.Equal(t, Left, proof[0].Side)
+		require.Equal(t, Right, proof[1].Side)
+		require.Equal(t, Left, proof[2].Side)
+	})
+
+	t.Run("should return error for generalized index zero", func(t *testing.T) {
+		_, err := BranchProof(nil, 0)
+		require.ErrorIs(t, err, ErrOutOfRange)
+	})
+}
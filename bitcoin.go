@@ -0,0 +1,340 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+)
+
+var ErrBadPartialTree = errors.New("invalid partial merkle tree")
+
+const (
+	// maxBlockTransactions bounds the transaction count of a block, as in Bitcoin Core
+	maxBlockTransactions = 4000000 / 240
+
+	blockHeaderSize = 80
+)
+
+// PartialMerkleTree is Bitcoin's CPartialMerkleTree: a pruned block Merkle tree
+// encoded as depth-first traversal flag bits plus the hashes of pruned subtrees,
+// as carried by merkleblock messages. Txids are in internal byte order.
+type PartialMerkleTree struct {
+	Transactions uint32
+	Hashes       [][]byte
+	Flags        []bool
+
+	hasher *MerkleTree
+}
+
+// NewPartialMerkleTree builds a partial tree over the block's txids that
+// proves the inclusion of the txids whose matches entry is true
+func NewPartialMerkleTree(txids [][]byte, matches []bool) (*PartialMerkleTree, error) {
+	if len(txids) == 0 {
+		return nil, ErrEmptyData
+	}
+	if len(matches) != len(txids) {
+		return nil, ErrBadPartialTree
+	}
+
+	p := &PartialMerkleTree{Transactions: uint32(len(txids)), hasher: bitcoinHasher()}
+	p.traverseAndBuild(p.height(), 0, txids, matches)
+	return p, nil
+}
+
+// ParsePartialMerkleTree decodes a serialized partial merkle tree
+func ParsePartialMerkleTree(b []byte) (*PartialMerkleTree, error) {
+	r := bytes.NewReader(b)
+	p, err := readPartialMerkleTree(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, ErrBadPartialTree
+	}
+	return p, nil
+}
+
+// MarshalBinary encodes the partial tree in Bitcoin's wire format
+func (p *PartialMerkleTree) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, p.Transactions)
+
+	writeCompactSize(&buf, uint64(len(p.Hashes)))
+	for _, h := range p.Hashes {
+		buf.Write(h)
+	}
+
+	flags := make([]byte, (len(p.Flags)+7)/8)
+	for i, bit := range p.Flags {
+		if bit {
+			flags[i/8] |= 1 << (i % 8)
+		}
+	}
+	writeCompactSize(&buf, uint64(len(flags)))
+	buf.Write(flags)
+
+	return buf.Bytes(), nil
+}
+
+// ExtractMatches validates the partial tree and returns its Merkle root along
+// with the matched txids and their positions in the block
+func (p *PartialMerkleTree) ExtractMatches() (root []byte, matches [][]byte, indexes []int, err error) {
+	if p.Transactions == 0 || p.Transactions > maxBlockTransactions {
+		return nil, nil, nil, ErrBadPartialTree
+	}
+	if len(p.Hashes) > int(p.Transactions) || len(p.Flags) < len(p.Hashes) {
+		return nil, nil, nil, ErrBadPartialTree
+	}
+	if p.hasher == nil {
+		p.hasher = bitcoinHasher()
+	}
+
+	e := &partialExtractor{tree: p}
+	root = e.traverseAndExtract(p.height(), 0)
+	if e.bad {
+		return nil, nil, nil, ErrBadPartialTree
+	}
+	// every hash and all but the padding bits of the last flag byte must be consumed
+	if (e.bitsUsed+7)/8 != (len(p.Flags)+7)/8 || e.hashUsed != len(p.Hashes) {
+		return nil, nil, nil, ErrBadPartialTree
+	}
+
+	return root, e.matches, e.indexes, nil
+}
+
+// MerkleBlock is a decoded Bitcoin merkleblock message
+type MerkleBlock struct {
+	Header [blockHeaderSize]byte
+	Tree   *PartialMerkleTree
+}
+
+// ParseMerkleBlock decodes a merkleblock message payload
+func ParseMerkleBlock(b []byte) (*MerkleBlock, error) {
+	r := bytes.NewReader(b)
+
+	mb := &MerkleBlock{}
+	if _, err := io.ReadFull(r, mb.Header[:]); err != nil {
+		return nil, ErrBadPartialTree
+	}
+
+	tree, err := readPartialMerkleTree(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, ErrBadPartialTree
+	}
+	mb.Tree = tree
+
+	return mb, nil
+}
+
+// MerkleRoot returns the Merkle root committed to by the block header
+func (mb *MerkleBlock) MerkleRoot() []byte {
+	return mb.Header[36:68]
+}
+
+// ExtractMatches returns the matched txids and their positions, after checking
+// that the partial tree hashes up to the header's Merkle root
+func (mb *MerkleBlock) ExtractMatches() ([][]byte, []int, error) {
+	root, matches, indexes, err := mb.Tree.ExtractMatches()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(root, mb.MerkleRoot()) {
+		return nil, nil, ErrBadPartialTree
+	}
+	return matches, indexes, nil
+}
+
+// height returns the height of the full block tree
+func (p *PartialMerkleTree) height() int {
+	height := 0
+	for p.width(height) > 1 {
+		height++
+	}
+	return height
+}
+
+// width returns the number of nodes at the given height
+func (p *PartialMerkleTree) width(height int) int {
+	return int((uint64(p.Transactions) + 1<<height - 1) >> height)
+}
+
+// calcHash computes the hash of the node at the given height and position
+func (p *PartialMerkleTree) calcHash(height, pos int, txids [][]byte) []byte {
+	if height == 0 {
+		return txids[pos]
+	}
+
+	left := p.calcHash(height-1, pos*2, txids)
+	right := left
+	if pos*2+1 < p.width(height-1) {
+		right = p.calcHash(height-1, pos*2+1, txids)
+	}
+	return p.hasher.hashNode(left, right)
+}
+
+// traverseAndBuild records the flag bits and pruned hashes of the subtree at the given position
+func (p *PartialMerkleTree) traverseAndBuild(height, pos int, txids [][]byte, matches []bool) {
+	parentOfMatch := false
+	for i := pos << height; i < (pos+1)<<height && i < len(txids); i++ {
+		parentOfMatch = parentOfMatch || matches[i]
+	}
+	p.Flags = append(p.Flags, parentOfMatch)
+
+	if height == 0 || !parentOfMatch {
+		p.Hashes = append(p.Hashes, p.calcHash(height, pos, txids))
+		return
+	}
+
+	p.traverseAndBuild(height-1, pos*2, txids, matches)
+	if pos*2+1 < p.width(height-1) {
+		p.traverseAndBuild(height-1, pos*2+1, txids, matches)
+	}
+}
+
+// partialExtractor holds the traversal state while extracting matches
+type partialExtractor struct {
+	tree     *PartialMerkleTree
+	bitsUsed int
+	hashUsed int
+	matches  [][]byte
+	indexes  []int
+	bad      bool
+}
+
+// traverseAndExtract consumes flag bits and hashes to recompute the subtree at the given position
+func (e *partialExtractor) traverseAndExtract(height, pos int) []byte {
+	p := e.tree
+	if e.bitsUsed >= len(p.Flags) {
+		e.bad = true
+		return nil
+	}
+	parentOfMatch := p.Flags[e.bitsUsed]
+	e.bitsUsed++
+
+	if height == 0 || !parentOfMatch {
+		if e.hashUsed >= len(p.Hashes) {
+			e.bad = true
+			return nil
+		}
+		hash := p.Hashes[e.hashUsed]
+		e.hashUsed++
+		if height == 0 && parentOfMatch {
+			e.matches = append(e.matches, hash)
+			e.indexes = append(e.indexes, pos)
+		}
+		return hash
+	}
+
+	left := e.traverseAndExtract(height-1, pos*2)
+	right := left
+	if pos*2+1 < p.width(height-1) {
+		right = e.traverseAndExtract(height-1, pos*2+1)
+		// identical siblings allow forging a tree with duplicated txids (CVE-2012-2459)
+		if bytes.Equal(left, right) {
+			e.bad = true
+		}
+	}
+	return p.hasher.hashNode(left, right)
+}
+
+// readPartialMerkleTree decodes a partial tree from the reader
+func readPartialMerkleTree(r *bytes.Reader) (*PartialMerkleTree, error) {
+	p := &PartialMerkleTree{hasher: bitcoinHasher()}
+	if err := binary.Read(r, binary.LittleEndian, &p.Transactions); err != nil {
+		return nil, ErrBadPartialTree
+	}
+
+	count, err := readCompactSize(r)
+	if err != nil || count > uint64(r.Len())/sha256.Size {
+		return nil, ErrBadPartialTree
+	}
+	for i := uint64(0); i < count; i++ {
+		h := make([]byte, sha256.Size)
+		if _, err := io.ReadFull(r, h); err != nil {
+			return nil, ErrBadPartialTree
+		}
+		p.Hashes = append(p.Hashes, h)
+	}
+
+	size, err := readCompactSize(r)
+	if err != nil || size > uint64(r.Len()) {
+		return nil, ErrBadPartialTree
+	}
+	flags := make([]byte, size)
+	if _, err := io.ReadFull(r, flags); err != nil {
+		return nil, ErrBadPartialTree
+	}
+	for i := 0; i < len(flags)*8; i++ {
+		p.Flags = append(p.Flags, flags[i/8]&(1<<(i%8)) != 0)
+	}
+
+	return p, nil
+}
+
+// writeCompactSize writes a Bitcoin variable length integer
+func writeCompactSize(buf *bytes.Buffer, n uint64) {
+	switch {
+	case n < 0xfd:
+		buf.WriteByte(byte(n))
+	case n <= 0xffff:
+		buf.WriteByte(0xfd)
+		binary.Write(buf, binary.LittleEndian, uint16(n))
+	case n <= 0xffffffff:
+		buf.WriteByte(0xfe)
+		binary.Write(buf, binary.LittleEndian, uint32(n))
+	default:
+		buf.WriteByte(0xff)
+		binary.Write(buf, binary.LittleEndian, n)
+	}
+}
+
+// readCompactSize reads a Bitcoin variable length integer
+func readCompactSize(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	switch prefix {
+	case 0xfd:
+		var n uint16
+		err = binary.Read(r, binary.LittleEndian, &n)
+		return uint64(n), err
+	case 0xfe:
+		var n uint32
+		err = binary.Read(r, binary.LittleEndian, &n)
+		return uint64(n), err
+	case 0xff:
+		var n uint64
+		err = binary.Read(r, binary.LittleEndian, &n)
+		return n, err
+	default:
+		return uint64(prefix), nil
+	}
+}
+
+// bitcoinHasher returns a tree configured for Bitcoin's double SHA-256 node hashing
+func bitcoinHasher() *MerkleTree {
+	return &MerkleTree{hashFn: newDoubleSHA256}
+}
+
+// doubleSHA256 is a hash.Hash computing SHA256(SHA256(data))
+type doubleSHA256 struct {
+	hash.Hash
+}
+
+func newDoubleSHA256() hash.Hash {
+	return doubleSHA256{sha256.New()}
+}
+
+func (d doubleSHA256) Sum(b []byte) []byte {
+	first := d.Hash.Sum(nil)
+	second := sha256.Sum256(first)
+	return append(b, second[:]...)
+}
@@ -0,0 +1,110 @@
+package merkle
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// txids of block 100000, in the usual reversed display order
+var block100000 = []string{
+	"8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87",
+	"fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4",
+	"6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4",
+	"e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d",
+}
+
+const block100000Root = "f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766"
+
+func Test_PartialMerkleTree(t *testing.T) {
+	var txids [][]byte
+	for _, s := range block100000 {
+		txids = append(txids, reversedHex(t, s))
+	}
+	root := reversedHex(t, block100000Root)
+
+	t.Run("should extract matches and the block root", func(t *testing.T) {
+		p, err := NewPartialMerkleTree(txids, []bool{false, true, false, true})
+		require.NoError(t, err)
+
+		b, err := p.MarshalBinary()
+		require.NoError(t, err)
+		parsed, err := ParsePartialMerkleTree(b)
+		require.NoError(t, err)
+
+		gotRoot, matches, indexes, err := parsed.ExtractMatches()
+		require.NoError(t, err)
+		require.Equal(t, root, gotRoot)
+		require.Equal(t, [][]byte{txids[1], txids[3]}, matches)
+		require.Equal(t, []int{1, 3}, indexes)
+	})
+
+	t.Run("should prune subtrees without matches", func(t *testing.T) {
+		p, err := NewPartialMerkleTree(txids, []bool{false, false, false, false})
+		require.NoError(t, err)
+		require.Len(t, p.Hashes, 1)
+		require.Equal(t, root, p.Hashes[0])
+	})
+
+	t.Run("should reject duplicated txids", func(t *testing.T) {
+		forged := [][]byte{txids[0], txids[1], txids[2], txids[2]}
+		p, err := NewPartialMerkleTree(forged, []bool{true, true, true, true})
+		require.NoError(t, err)
+		_, _, _, err = p.ExtractMatches()
+		require.ErrorIs(t, err, ErrBadPartialTree)
+	})
+
+	t.Run("should reject unconsumed hashes", func(t *testing.T) {
+		p, err := NewPartialMerkleTree(txids, []bool{true, false, false, false})
+		require.NoError(t, err)
+		p.Hashes = append(p.Hashes, txids[0])
+		_, _, _, err = p.ExtractMatches()
+		require.ErrorIs(t, err, ErrBadPartialTree)
+	})
+}
+
+func Test_ParseMerkleBlock(t *testing.T) {
+	var txids [][]byte
+	for _, s := range block100000 {
+		txids = append(txids, reversedHex(t, s))
+	}
+	p, err := NewPartialMerkleTree(txids, []bool{true, false, false, false})
+	require.NoError(t, err)
+	tree, err := p.MarshalBinary()
+	require.NoError(t, err)
+
+	header := make([]byte, blockHeaderSize)
+	copy(header[36:68], reversedHex(t, block100000Root))
+
+	t.Run("should verify matches against the header root", func(t *testing.T) {
+		mb, err := ParseMerkleBlock(append(header, tree...))
+		require.NoError(t, err)
+		matches, indexes, err := mb.ExtractMatches()
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{txids[0]}, matches)
+		require.Equal(t, []int{0}, indexes)
+	})
+
+	t.Run("should return error for a mismatched header root", func(t *testing.T) {
+		bad := make([]byte, blockHeaderSize)
+		mb, err := ParseMerkleBlock(append(bad, tree...))
+		require.NoError(t, err)
+		_, _, err = mb.ExtractMatches()
+		require.ErrorIs(t, err, ErrBadPartialTree)
+	})
+
+	t.Run("should return error for truncated messages", func(t *testing.T) {
+		_, err := ParseMerkleBlock(append(header, tree[:len(tree)-1]...))
+		require.ErrorIs(t, err, ErrBadPartialTree)
+	})
+}
+
+func reversedHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
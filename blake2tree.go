@@ -0,0 +1,244 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+var ErrTreeParams = errors.New("invalid tree hash parameters")
+
+// Blake2Params are the BLAKE2b tree hashing parameters of the BLAKE2 spec. The
+// data is split into LeafSize leaves and every interior node hashes the
+// concatenated InnerSize digests of up to Fanout children.
+type Blake2Params struct {
+	Size      int // digest length of the root, 1 to 64
+	Fanout    int // at least 2
+	Depth     int // maximal depth, 0 for the depth of the tree built
+	LeafSize  int
+	InnerSize int // digest length of the other nodes, 1 to 64
+}
+
+// Blake2Tree is a BLAKE2b tree hash whose root matches BLAKE2 implementations
+// of the same parameters, such as Python's hashlib.blake2b
+type Blake2Tree struct {
+	p      Blake2Params
+	levels [][][]byte // levels[0] are the leaf digests, the last level holds the root
+}
+
+// NewBlake2Tree computes the BLAKE2b tree hash of data
+func NewBlake2Tree(data []byte, p Blake2Params) (*Blake2Tree, error) {
+	leafCount := max((len(data)+p.LeafSize-1)/max(p.LeafSize, 1), 1)
+	p, err := p.sized(leafCount)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Blake2Tree{p: p}
+	level := make([][]byte, leafCount)
+	for i := range level {
+		leaf := data[min(i*p.LeafSize, len(data)):min((i+1)*p.LeafSize, len(data))]
+		level[i] = p.node(leaf, i, 0, leafCount)
+	}
+	t.levels = append(t.levels, level)
+
+	for depth := 1; len(level) > 1; depth++ {
+		parents := make([][]byte, (len(level)+p.Fanout-1)/p.Fanout)
+		for j := range parents {
+			children := level[j*p.Fanout : min((j+1)*p.Fanout, len(level))]
+			parents[j] = p.node(bytes.Join(children, nil), j, depth, len(parents))
+		}
+		level = parents
+		t.levels = append(t.levels, level)
+	}
+
+	return t, nil
+}
+
+// Root returns the root digest of the tree
+func (t *Blake2Tree) Root() []byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Len returns the number of leaves
+func (t *Blake2Tree) Len() int {
+	return len(t.levels[0])
+}
+
+// GenerateProofAt generates a proof for the leaf at the given index, with the
+// other children of every node on its path
+func (t *Blake2Tree) GenerateProofAt(index int) (NaryProof, error) {
+	if index < 0 || index >= t.Len() {
+		return nil, ErrOutOfRange
+	}
+
+	var proof NaryProof
+	for _, level := range t.levels[:len(t.levels)-1] {
+		start := index / t.p.Fanout * t.p.Fanout
+		group := level[start:min(start+t.p.Fanout, len(level))]
+		pos := index - start
+		siblings := append(append([][]byte{}, group[:pos]...), group[pos+1:]...)
+		proof = append(proof, NaryProofStep{Index: pos, Siblings: siblings})
+		index /= t.p.Fanout
+	}
+	return proof, nil
+}
+
+// VerifyBlake2Proof verifies a proof for the data of the leaf at index in a
+// tree of leafCount leaves. The position is needed since every node digest
+// depends on its offset and depth.
+func VerifyBlake2Proof(root, leaf []byte, index, leafCount int, proof NaryProof, p Blake2Params) bool {
+	p, err := p.sized(leafCount)
+	if err != nil || index < 0 || index >= leafCount || len(leaf) > p.LeafSize {
+		return false
+	}
+
+	width := leafCount
+	hash := p.node(leaf, index, 0, width)
+	for depth, step := range proof {
+		// the step must hold exactly the other children of the node's group
+		start := index / p.Fanout * p.Fanout
+		if width == 1 || step.Index != index-start || len(step.Siblings) != min(p.Fanout, width-start)-1 {
+			return false
+		}
+		group := make([][]byte, 0, len(step.Siblings)+1)
+		group = append(group, step.Siblings[:step.Index]...)
+		group = append(group, hash)
+		group = append(group, step.Siblings[step.Index:]...)
+
+		index /= p.Fanout
+		width = (width + p.Fanout - 1) / p.Fanout
+		hash = p.node(bytes.Join(group, nil), index, depth+1, width)
+	}
+	return width == 1 && bytes.Equal(hash, root)
+}
+
+// sized validates the parameters and sets the depth of a tree over leafCount leaves
+func (p Blake2Params) sized(leafCount int) (Blake2Params, error) {
+	if p.Size < 1 || p.Size > 64 || p.InnerSize < 1 || p.InnerSize > 64 ||
+		p.Fanout < 2 || p.Fanout > 255 || p.LeafSize < 1 || uint64(p.LeafSize) > 1<<32-1 || leafCount < 1 {
+		return p, ErrTreeParams
+	}
+
+	depth := 1
+	for width := leafCount; width > 1; width = (width + p.Fanout - 1) / p.Fanout {
+		depth++
+	}
+	if p.Depth == 0 {
+		p.Depth = depth
+	}
+	if p.Depth < depth || p.Depth > 255 {
+		return p, ErrTreeParams
+	}
+	return p, nil
+}
+
+// node hashes the node at offset and depth of a level holding width nodes; the
+// last node of the level is flagged, and a level of one node is the root
+func (p Blake2Params) node(data []byte, offset, depth, width int) []byte {
+	var param [64]byte
+	param[0] = byte(p.InnerSize)
+	if width == 1 {
+		param[0] = byte(p.Size)
+	}
+	param[2] = byte(p.Fanout)
+	param[3] = byte(p.Depth)
+	binary.LittleEndian.PutUint32(param[4:], uint32(p.LeafSize))
+	binary.LittleEndian.PutUint64(param[8:], uint64(offset))
+	param[16] = byte(depth)
+	param[17] = byte(p.InnerSize)
+	return blake2bSum(data, &param, offset == width-1)
+}
+
+var blake2bIV = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+var blake2bSigma = [12][16]byte{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+}
+
+// blake2bSum computes an unkeyed BLAKE2b digest under the given parameter block,
+// setting the last node flag if lastNode; x/crypto/blake2b does not expose the
+// tree parameters
+func blake2bSum(data []byte, param *[64]byte, lastNode bool) []byte {
+	var h [8]uint64
+	for i := range h {
+		h[i] = blake2bIV[i] ^ binary.LittleEndian.Uint64(param[i*8:])
+	}
+
+	var block [128]byte
+	var counter uint64
+	for len(data) > len(block) {
+		counter += uint64(len(block))
+		copy(block[:], data)
+		blake2bCompress(&h, &block, counter, false, false)
+		data = data[len(block):]
+	}
+	block = [128]byte{}
+	copy(block[:], data)
+	blake2bCompress(&h, &block, counter+uint64(len(data)), true, lastNode)
+
+	var out [64]byte
+	for i, v := range h {
+		binary.LittleEndian.PutUint64(out[i*8:], v)
+	}
+	return out[:param[0]]
+}
+
+// blake2bCompress is the BLAKE2b compression function F
+func blake2bCompress(h *[8]uint64, block *[128]byte, counter uint64, last, lastNode bool) {
+	var m [16]uint64
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint64(block[i*8:])
+	}
+
+	var v [16]uint64
+	copy(v[:8], h[:])
+	copy(v[8:], blake2bIV[:])
+	v[12] ^= counter
+	if last {
+		v[14] = ^v[14]
+	}
+	if lastNode {
+		v[15] = ^v[15]
+	}
+
+	g := func(a, b, c, d int, x, y uint64) {
+		v[a] += v[b] + x
+		v[d] = bits.RotateLeft64(v[d]^v[a], -32)
+		v[c] += v[d]
+		v[b] = bits.RotateLeft64(v[b]^v[c], -24)
+		v[a] += v[b] + y
+		v[d] = bits.RotateLeft64(v[d]^v[a], -16)
+		v[c] += v[d]
+		v[b] = bits.RotateLeft64(v[b]^v[c], -63)
+	}
+	for _, s := range blake2bSigma {
+		g(0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	for i := range h {
+		h[i] ^= v[i] ^ v[i+8]
+	}
+}
@@ -0,0 +1,67 @@
+package merkle
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+func Test_Blake2Tree(t *testing.T) {
+	t.Run("should match hashlib tree hashes", func(t *testing.T) {
+		for _, c := range []struct {
+			size     int
+			params   Blake2Params
+			expected string
+		}{
+			{0, Blake2Params{Size: 32, Fanout: 2, LeafSize: 4096, InnerSize: 64}, "162f9176948cb1ada36ce82c52f0351061ba326298df19333d143d15c5d628ba"},
+			{100, Blake2Params{Size: 32, Fanout: 2, LeafSize: 4096, InnerSize: 64}, "fd292f312e2006dbaaacd33f9f9f29b16bbfdc2af72bb56154322bc12ccc9cdd"},
+			{8192, Blake2Params{Size: 32, Fanout: 2, Depth: 2, LeafSize: 4096, InnerSize: 64}, "ed91821b2be10fc8d2bb5eb61a8d7567b0f90b2c768e22488e7e7a103482840b"},
+			{10000, Blake2Params{Size: 64, Fanout: 4, LeafSize: 1024, InnerSize: 32}, "dfa74135760e004ed15204dee7654e000f254f7ba8be9dd323d730d8c6dc10a9f2ce3961f9cd80e53664a2f23f0544a17f5623f8a5a5fd95e9511a873bbf9258"},
+			{5000, Blake2Params{Size: 32, Fanout: 2, LeafSize: 64, InnerSize: 64}, "18afe97b2360f03743de4d4ae21329533789676fbf2479eadca5418d307cbdfd"},
+		} {
+			tree, err := NewBlake2Tree(k12Pattern(c.size), c.params)
+			require.NoError(t, err)
+			require.Equal(t, c.expected, hex.EncodeToString(tree.Root()))
+		}
+	})
+
+	t.Run("should verify leaf proofs", func(t *testing.T) {
+		data := k12Pattern(10000)
+		params := Blake2Params{Size: 32, Fanout: 4, LeafSize: 256, InnerSize: 32}
+		tree, err := NewBlake2Tree(data, params)
+		require.NoError(t, err)
+		require.Equal(t, 40, tree.Len())
+
+		for i := 0; i < tree.Len(); i++ {
+			leaf := data[i*256 : min((i+1)*256, len(data))]
+			proof, err := tree.GenerateProofAt(i)
+			require.NoError(t, err)
+			require.True(t, VerifyBlake2Proof(tree.Root(), leaf, i, tree.Len(), proof, params))
+			require.False(t, VerifyBlake2Proof(tree.Root(), leaf, (i+1)%tree.Len(), tree.Len(), proof, params))
+			require.False(t, VerifyBlake2Proof(tree.Root(), leaf, i, tree.Len(), proof[:len(proof)-1], params))
+		}
+	})
+
+	t.Run("should reject invalid parameters", func(t *testing.T) {
+		for _, params := range []Blake2Params{
+			{Size: 0, Fanout: 2, LeafSize: 64, InnerSize: 64},
+			{Size: 32, Fanout: 1, LeafSize: 64, InnerSize: 64},
+			{Size: 32, Fanout: 2, LeafSize: 0, InnerSize: 64},
+			{Size: 32, Fanout: 2, LeafSize: 64, InnerSize: 65},
+			{Size: 32, Fanout: 2, Depth: 2, LeafSize: 64, InnerSize: 64},
+		} {
+			_, err := NewBlake2Tree(k12Pattern(1000), params)
+			require.ErrorIs(t, err, ErrTreeParams)
+		}
+	})
+
+	t.Run("should match BLAKE2b in sequential mode", func(t *testing.T) {
+		param := [64]byte{32, 0, 1, 1}
+		for _, n := range []int{0, 1, 128, 129, 1000} {
+			expected := blake2b.Sum256(k12Pattern(n))
+			require.Equal(t, expected[:], blake2bSum(k12Pattern(n), &param, false))
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+)
+
+var ErrUnblindedSibling = errors.New("proof would expose an unblinded sibling leaf")
+
+// BlindSize is the size in bytes of a leaf blind
+const BlindSize = 32
+
+// WithBlinding commits every leaf as H(blind || data) with a fresh random
+// blind per leaf, so published proofs don't expose sibling leaf hashes to
+// dictionary attacks. The blinds are stored with the leaves.
+func WithBlinding() Option {
+	return func(m *MerkleTree) {
+		m.blinding = true
+	}
+}
+
+// WithAuditedProofs enables blinding and guarantees that the direct sibling in
+// every generated proof is a blinded commitment: proofs whose sibling leaf was
+// added by hash without a blind fail with ErrUnblindedSibling instead of
+// leaking a dictionary-attackable hash of a neighbour's data
+func WithAuditedProofs() Option {
+	return func(m *MerkleTree) {
+		m.blinding = true
+		m.audited = true
+	}
+}
+
+// Blind returns the blind of the leaf holding the given data, which its owner
+// needs alongside the proof to verify inclusion
+func (m *MerkleTree) Blind(data []byte) ([]byte, error) {
+	node := m.findLeaf(data)
+	if node == nil {
+		return nil, ErrNotFoundData
+	}
+	return node.blind, nil
+}
+
+// VerifyBlindedData verifies a Merkle proof for data committed with the given blind
+func (m *MerkleTree) VerifyBlindedData(data, blind []byte, proof Proof) bool {
+	return bytes.Equal(m.rootFromProof(m.newHash(), m.hashBlindedLeaf(blind, data), proof), m.root.hash)
+}
+
+// auditedProof generates the proof for a leaf, checking its sibling leaf in
+// audited mode; a promoted leaf is paired with an interior node, which has no
+// blind to check
+func (m *MerkleTree) auditedProof(node *Node) (Proof, error) {
+	if m.audited && node.parent != nil {
+		sibling := node.parent.left
+		if sibling == node {
+			sibling = node.parent.right
+		}
+		if sibling != node && sibling.left == nil && sibling.blind == nil {
+			return nil, ErrUnblindedSibling
+		}
+	}
+	return m.proofFor(node), nil
+}
+
+// hashBlindedLeaf computes the hash of a leaf value committed with a blind;
+// a nil blind hashes the plain value
+func (m *MerkleTree) hashBlindedLeaf(blind, data []byte) []byte {
+	if blind == nil {
+		return m.hashLeaf(data)
+	}
+	return m.hashLeaf(append(append(make([]byte, 0, len(blind)+len(data)), blind...), data...))
+}
+
+// newBlind creates a random leaf blind
+func newBlind() []byte {
+	blind := make([]byte, BlindSize)
+	if _, err := rand.Read(blind); err != nil {
+		panic(err)
+	}
+	return blind
+}
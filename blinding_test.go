@@ -0,0 +1,96 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithBlinding(t *testing.T) {
+	data := [][]byte{[]byte("alice"), []byte("bob"), []byte("carol")}
+	tree, err := New(data, WithBlinding())
+	require.NoError(t, err)
+
+	t.Run("should commit to fresh blinds", func(t *testing.T) {
+		other, err := New(data, WithBlinding())
+		require.NoError(t, err)
+		require.NotEqual(t, tree.Root(), other.Root())
+	})
+
+	t.Run("should not expose plain sibling leaf hashes", func(t *testing.T) {
+		proof, err := tree.GenerateProof([]byte("alice"))
+		require.NoError(t, err)
+		require.NotEqual(t, tree.hashLeaf([]byte("bob")), proof[0].Hash)
+	})
+
+	t.Run("should verify data with its blind", func(t *testing.T) {
+		proof, err := tree.GenerateProof([]byte("bob"))
+		require.NoError(t, err)
+		blind, err := tree.Blind([]byte("bob"))
+		require.NoError(t, err)
+		require.Len(t, blind, BlindSize)
+
+		require.True(t, tree.VerifyBlindedData([]byte("bob"), blind, proof))
+		require.True(t, tree.VerifyData([]byte("bob"), proof))
+		require.False(t, tree.VerifyBlindedData([]byte("bob"), make([]byte, BlindSize), proof))
+	})
+
+	t.Run("should reblind updated leaves", func(t *testing.T) {
+		old, err := tree.Blind([]byte("carol"))
+		require.NoError(t, err)
+		require.NoError(t, tree.UpdateLeaf([]byte("carol"), []byte("dave")))
+
+		blind, err := tree.Blind([]byte("dave"))
+		require.NoError(t, err)
+		require.NotEqual(t, old, blind)
+
+		proof, err := tree.GenerateProof([]byte("dave"))
+		require.NoError(t, err)
+		require.True(t, tree.VerifyBlindedData([]byte("dave"), blind, proof))
+	})
+
+	t.Run("should return error for non-existent data", func(t *testing.T) {
+		_, err := tree.Blind([]byte("eve"))
+		require.ErrorIs(t, err, ErrNotFoundData)
+	})
+}
+
+func Test_WithAuditedProofs(t *testing.T) {
+	t.Run("should prove leaves with blinded siblings", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("alice"), []byte("bob"), []byte("carol")}, WithAuditedProofs())
+		require.NoError(t, err)
+
+		for _, data := range []string{"alice", "bob", "carol"} {
+			proof, err := tree.GenerateProof([]byte(data))
+			require.NoError(t, err)
+			require.True(t, tree.VerifyData([]byte(data), proof))
+		}
+	})
+
+	t.Run("should prove promoted leaves paired with interior nodes", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("alice"), []byte("bob"), []byte("carol")}, WithAuditedProofs(), WithOddNodePromotion())
+		require.NoError(t, err)
+
+		proof, err := tree.GenerateProof([]byte("carol"))
+		require.NoError(t, err)
+		require.True(t, tree.VerifyData([]byte("carol"), proof))
+	})
+
+	t.Run("should refuse to expose unblinded siblings", func(t *testing.T) {
+		tree, err := NewBuilder(WithAuditedProofs()).
+			Add([]byte("alice")).
+			AddHash([]byte("hash(bob)")).
+			Build()
+		require.NoError(t, err)
+
+		_, err = tree.GenerateProof([]byte("alice"))
+		require.ErrorIs(t, err, ErrUnblindedSibling)
+		_, err = tree.GenerateProofAt(0)
+		require.ErrorIs(t, err, ErrUnblindedSibling)
+		_, err = tree.GenerateBundle("tree", []byte("alice"))
+		require.ErrorIs(t, err, ErrUnblindedSibling)
+
+		_, err = tree.GenerateProofAt(1)
+		require.NoError(t, err)
+	})
+}
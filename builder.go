@@ -0,0 +1,66 @@
+package merkle
+
+// Builder accumulates leaves and options for staged construction of a tree,
+// separating configuration and accumulation from the built tree
+type Builder struct {
+	opts    []Option
+	entries []builderEntry
+}
+
+// builderEntry is a pending leaf, given either as data or as its leaf hash
+type builderEntry struct {
+	data   []byte
+	hash   []byte
+	hashed bool
+}
+
+// NewBuilder creates an empty builder with the given options
+func NewBuilder(opts ...Option) *Builder {
+	return &Builder{opts: opts}
+}
+
+// SetOptions adds options to apply when building; leaves are hashed at Build
+// time, so options may be set after leaves were added
+func (b *Builder) SetOptions(opts ...Option) *Builder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Grow pre-sizes the builder for n more leaves
+func (b *Builder) Grow(n int) *Builder {
+	if n > 0 && cap(b.entries)-len(b.entries) < n {
+		entries := make([]builderEntry, len(b.entries), len(b.entries)+n)
+		copy(entries, b.entries)
+		b.entries = entries
+	}
+	return b
+}
+
+// Add appends a leaf holding the given data
+func (b *Builder) Add(data []byte) *Builder {
+	b.entries = append(b.entries, builderEntry{data: data})
+	return b
+}
+
+// AddHash appends a leaf given only its already computed leaf hash
+func (b *Builder) AddHash(h []byte) *Builder {
+	b.entries = append(b.entries, builderEntry{hash: h, hashed: true})
+	return b
+}
+
+// Len returns the number of leaves added so far
+func (b *Builder) Len() int {
+	return len(b.entries)
+}
+
+// Build creates the Merkle tree from the accumulated leaves; the builder can
+// keep being used afterwards without affecting the built tree
+func (b *Builder) Build() (*MerkleTree, error) {
+	return build(len(b.entries), b.opts, func(m *MerkleTree, i int) (*Node, error) {
+		e := b.entries[i]
+		if e.hashed {
+			return &Node{hash: e.hash}, nil
+		}
+		return m.newLeaf(e.data)
+	})
+}
@@ -0,0 +1,45 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Builder(t *testing.T) {
+	t.Run("should build the same tree as New", func(t *testing.T) {
+		tree, err := NewBuilder().Grow(3).Add([]byte("a")).Add([]byte("b")).
+			SetOptions(WithHashFunction(mockHash)).Add([]byte("c")).Build()
+		require.NoError(t, err)
+		require.Equal(t, "hash(hash(hash(a)hash(b))hash(hash(c)hash(c)))", string(tree.Root()))
+	})
+
+	t.Run("should accept pre-hashed leaves", func(t *testing.T) {
+		tree, err := NewBuilder(WithHashFunction(mockHash)).Add([]byte("a")).AddHash([]byte("hash(b)")).Build()
+		require.NoError(t, err)
+		require.Equal(t, "hash(hash(a)hash(b))", string(tree.Root()))
+
+		proof, err := tree.GenerateProofByHash([]byte("hash(b)"))
+		require.NoError(t, err)
+		require.True(t, tree.VerifyProof([]byte("hash(b)"), proof))
+	})
+
+	t.Run("should not change built trees when adding more leaves", func(t *testing.T) {
+		b := NewBuilder(WithHashFunction(mockHash)).Add([]byte("a"))
+		first, err := b.Build()
+		require.NoError(t, err)
+
+		b.Add([]byte("b"))
+		second, err := b.Build()
+		require.NoError(t, err)
+
+		require.Equal(t, "hash(a)", string(first.Root()))
+		require.Equal(t, "hash(hash(a)hash(b))", string(second.Root()))
+		require.Equal(t, 2, b.Len())
+	})
+
+	t.Run("should return error for empty builder", func(t *testing.T) {
+		_, err := NewBuilder().Build()
+		require.ErrorIs(t, err, ErrEmptyData)
+	})
+}
@@ -0,0 +1,74 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"sync"
+)
+
+var (
+	ErrUnknownRoot  = errors.New("no root for the referenced tree")
+	ErrInvalidProof = errors.New("proof does not verify against the root")
+)
+
+// ProofBundle is a proof for a leaf hash in the tree identified by TreeID
+type ProofBundle struct {
+	TreeID string `json:"tree_id"`
+	Leaf   []byte `json:"leaf"`
+	Proof  Proof  `json:"proof"`
+	Value  any    `json:"value,omitempty"`
+	// HashAlgorithm is the tree's HashAlgorithm
+	HashAlgorithm string `json:"hash_algorithm,omitempty"`
+}
+
+// GenerateBundle generates a proof bundle for the given data, tagged with a
+// tree ID and carrying the leaf's attached value
+func (m *MerkleTree) GenerateBundle(treeID string, data []byte) (ProofBundle, error) {
+	node := m.findLeaf(data)
+	if node == nil {
+		return ProofBundle{}, ErrNotFoundData
+	}
+	proof, err := m.auditedProof(node)
+	if err != nil {
+		return ProofBundle{}, err
+	}
+	return ProofBundle{TreeID: treeID, Leaf: node.hash, Proof: proof, Value: node.value, HashAlgorithm: m.HashAlgorithm()}, nil
+}
+
+// VerifyAcross verifies a batch of proof bundles that may reference different
+// trees, looking up each bundle's root by its tree ID. All trees must share the
+// hashing configuration given by opts. The returned error joins every failure.
+func VerifyAcross(roots map[string][]byte, bundles []ProofBundle, opts ...Option) error {
+	m := newVerifier(opts...)
+	pool := sync.Pool{New: func() any { return m.newHash() }}
+
+	var errs []error
+	for i, b := range bundles {
+		root, ok := roots[b.TreeID]
+		if !ok {
+			errs = append(errs, fmt.Errorf("bundle %d (%s): %w", i, b.TreeID, ErrUnknownRoot))
+			continue
+		}
+
+		h := pool.Get().(hash.Hash)
+		valid := m.provesRoot(h, b.Leaf, b.Proof, root)
+		pool.Put(h)
+
+		if !valid {
+			errs = append(errs, fmt.Errorf("bundle %d (%s): %w", i, b.TreeID, ErrInvalidProof))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// newVerifier creates an empty tree carrying only the hashing configuration of opts
+func newVerifier(opts ...Option) *MerkleTree {
+	m := &MerkleTree{hashFn: sha256.New}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
@@ -0,0 +1,41 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_VerifyAcross(t *testing.T) {
+	a, err := New([][]byte{[]byte("a"), []byte("b"), []byte("c")}, WithHashFunction(mockHash))
+	require.NoError(t, err)
+	b, err := New([][]byte{[]byte("x"), []byte("y")}, WithHashFunction(mockHash))
+	require.NoError(t, err)
+
+	roots := map[string][]byte{"a": a.Root(), "b": b.Root()}
+
+	bundleA, err := a.GenerateBundle("a", []byte("c"))
+	require.NoError(t, err)
+	bundleB, err := b.GenerateBundle("b", []byte("x"))
+	require.NoError(t, err)
+
+	t.Run("should verify bundles for different trees", func(t *testing.T) {
+		err := VerifyAcross(roots, []ProofBundle{bundleA, bundleB}, WithHashFunction(mockHash))
+		require.NoError(t, err)
+	})
+
+	t.Run("should report bundles for unknown trees", func(t *testing.T) {
+		unknown := bundleA
+		unknown.TreeID = "c"
+		err := VerifyAcross(roots, []ProofBundle{bundleB, unknown}, WithHashFunction(mockHash))
+		require.ErrorIs(t, err, ErrUnknownRoot)
+		require.NotErrorIs(t, err, ErrInvalidProof)
+	})
+
+	t.Run("should report bundles checked against the wrong root", func(t *testing.T) {
+		swapped := bundleA
+		swapped.TreeID = "b"
+		err := VerifyAcross(roots, []ProofBundle{swapped}, WithHashFunction(mockHash))
+		require.ErrorIs(t, err, ErrInvalidProof)
+	})
+}
@@ -0,0 +1,65 @@
+package merkle
+
+import "bytes"
+
+// Canopy is the top of a tree that clients cache: the hashes of every node at
+// Level, from which all levels above up to the root follow
+type Canopy struct {
+	Level  int      `json:"level"`
+	Hashes [][]byte `json:"hashes"`
+}
+
+// Canopy returns the top depth levels of the tree below the root, so proofs
+// can be truncated to stop at its lower boundary
+func (m *MerkleTree) Canopy(depth int) (Canopy, error) {
+	if depth < 0 || depth > m.Height() {
+		return Canopy{}, ErrOutOfRange
+	}
+	level := m.Height() - depth
+	return Canopy{Level: level, Hashes: m.LevelHashes(level)}, nil
+}
+
+// GenerateTruncatedProof generates a proof for the leaf at index that stops at
+// the canopy boundary, leaving out the elements a client with the canopy
+// already knows
+func (m *MerkleTree) GenerateTruncatedProof(index int, c Canopy) (Proof, error) {
+	if c.Level < 0 || c.Level > m.Height() {
+		return nil, ErrOutOfRange
+	}
+	proof, err := m.GenerateProofAt(index)
+	if err != nil {
+		return nil, err
+	}
+
+	// the elements above the boundary are those of the boundary node's proof
+	// in the tree over the canopy level
+	above, err := proofSides(index>>c.Level, len(m.levels[c.Level]), m.promoteOdd)
+	if err != nil {
+		return nil, err
+	}
+	return proof[:len(proof)-len(above)], nil
+}
+
+// VerifyCanopy checks that a canopy hashes up to the root
+func VerifyCanopy(root []byte, c Canopy, opts ...Option) bool {
+	if len(c.Hashes) == 0 {
+		return false
+	}
+	m := newVerifier(opts...)
+	nodes := make([]*Node, len(c.Hashes))
+	for i, h := range c.Hashes {
+		nodes[i] = &Node{hash: h}
+	}
+	return bytes.Equal(m.buildTree(nodes).hash, root)
+}
+
+// VerifyTruncatedProof verifies a truncated proof for the leaf hash at index
+// against a canopy, which the client must have checked with VerifyCanopy
+func VerifyTruncatedProof(c Canopy, hash []byte, index int, proof Proof, opts ...Option) bool {
+	boundary := index >> c.Level
+	if index < 0 || boundary >= len(c.Hashes) {
+		return false
+	}
+	m := newVerifier(opts...)
+	return m.provesRoot(m.newHash(), hash, proof, c.Hashes[boundary])
+}
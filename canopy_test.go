@@ -0,0 +1,62 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Canopy(t *testing.T) {
+	var data [][]byte
+	for i := 0; i < 11; i++ {
+		data = append(data, []byte(fmt.Sprint(i)))
+	}
+
+	for _, mode := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "duplicate odd nodes"},
+		{name: "promote odd nodes", opts: []Option{WithOddNodePromotion()}},
+	} {
+		tree, err := New(data, mode.opts...)
+		require.NoError(t, err)
+
+		t.Run("should verify truncated proofs against the canopy with "+mode.name, func(t *testing.T) {
+			for depth := 0; depth <= tree.Height(); depth++ {
+				c, err := tree.Canopy(depth)
+				require.NoError(t, err)
+				require.True(t, VerifyCanopy(tree.Root(), c, mode.opts...))
+
+				for i, leaf := range data {
+					proof, err := tree.GenerateTruncatedProof(i, c)
+					require.NoError(t, err)
+					full, err := tree.GenerateProofAt(i)
+					require.NoError(t, err)
+					require.LessOrEqual(t, len(proof), len(full))
+					require.Equal(t, full[:len(proof)], proof)
+
+					require.True(t, VerifyTruncatedProof(c, tree.hashLeaf(leaf), i, proof, mode.opts...))
+					require.False(t, VerifyTruncatedProof(c, tree.hashLeaf([]byte("x")), i, proof, mode.opts...))
+				}
+			}
+		})
+	}
+
+	t.Run("should reject canopies not matching the root", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		c, err := tree.Canopy(2)
+		require.NoError(t, err)
+		c.Hashes[0] = []byte("forged")
+		require.False(t, VerifyCanopy(tree.Root(), c))
+	})
+
+	t.Run("should return error for depths outside the tree", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		_, err = tree.Canopy(tree.Height() + 1)
+		require.ErrorIs(t, err, ErrOutOfRange)
+	})
+}
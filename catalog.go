@@ -0,0 +1,179 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+)
+
+var (
+	ErrUnknownSnapshot   = errors.New("unknown snapshot")
+	ErrDuplicateSnapshot = errors.New("snapshot already recorded")
+)
+
+// Catalog records the root of every backup snapshot, with a FileLeaf per file
+// ordered by path as for archives, so snapshots can be diffed and each file's
+// presence in a snapshot proven
+type Catalog struct {
+	opts      []Option
+	ids       []string
+	snapshots map[string]*catalogSnapshot
+	recorded  int // snapshots recorded so far, including pruned ones
+
+	retention RetentionPolicy
+	pinned    map[string]bool
+	pruned    map[string][]byte // roots of pruned snapshots
+}
+
+// catalogSnapshot is the tree of a snapshot and the leaf of each file, by
+// path; the tree of an empty snapshot is nil
+type catalogSnapshot struct {
+	tree  *MerkleTree
+	files map[string][]byte
+	seq   int // position in recording order
+}
+
+// root returns the root of the snapshot, or nil if it is empty
+func (s *catalogSnapshot) root() []byte {
+	if s.tree == nil {
+		return nil
+	}
+	return s.tree.Root()
+}
+
+// SnapshotDiff lists the paths that changed between two snapshots
+type SnapshotDiff struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+// NewCatalog creates an empty catalog whose trees use the given options
+func NewCatalog(opts ...Option) *Catalog {
+	return &Catalog{opts: opts, snapshots: map[string]*catalogSnapshot{}, pinned: map[string]bool{}, pruned: map[string][]byte{}}
+}
+
+// AddSnapshot records a snapshot of the files, by path, and returns its root;
+// an empty snapshot has a nil root
+func (c *Catalog) AddSnapshot(id string, files map[string][]byte) ([]byte, error) {
+	_, ok := c.snapshots[id]
+	if _, pruned := c.pruned[id]; ok || pruned {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateSnapshot, id)
+	}
+
+	s := &catalogSnapshot{files: make(map[string][]byte, len(files)), seq: c.recorded}
+	for name, content := range files {
+		if err := addFile(s.files, name, content); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.files) > 0 {
+		tree, _, err := newFromFiles(s.files, c.opts...)
+		if err != nil {
+			return nil, err
+		}
+		s.tree = tree
+	}
+
+	c.snapshots[id] = s
+	c.ids = append(c.ids, id)
+	c.recorded++
+	c.Prune()
+
+	return s.root(), nil
+}
+
+// Snapshots returns the IDs of all retained snapshots in the order they were recorded
+func (c *Catalog) Snapshots() []string {
+	return append([]string{}, c.ids...)
+}
+
+// Root returns the root of a snapshot, which outlives its pruning
+func (c *Catalog) Root(id string) ([]byte, error) {
+	if root, ok := c.pruned[id]; ok {
+		return root, nil
+	}
+	s, err := c.snapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.root(), nil
+}
+
+// Diff compares two snapshots by the leaf of each path, which holds the hash
+// of the file content; snapshots with equal roots are identical and are not
+// compared file by file
+func (c *Catalog) Diff(from, to string) (SnapshotDiff, error) {
+	a, err := c.snapshot(from)
+	if err != nil {
+		return SnapshotDiff{}, err
+	}
+	b, err := c.snapshot(to)
+	if err != nil {
+		return SnapshotDiff{}, err
+	}
+
+	var diff SnapshotDiff
+	if a.tree != nil && b.tree != nil && a.tree.RootEqual(b.tree) {
+		return diff, nil
+	}
+
+	for name, leaf := range a.files {
+		other, ok := b.files[name]
+		switch {
+		case !ok:
+			diff.Removed = append(diff.Removed, name)
+		case !bytes.Equal(leaf, other):
+			diff.Modified = append(diff.Modified, name)
+		}
+	}
+	for name := range b.files {
+		if _, ok := a.files[name]; !ok {
+			diff.Added = append(diff.Added, name)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Modified)
+	return diff, nil
+}
+
+// ProveFile generates a proof that the file with the given path is in a snapshot
+func (c *Catalog) ProveFile(id, name string) (Proof, error) {
+	s, err := c.snapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	leaf, ok := s.files[path.Clean(name)]
+	if !ok {
+		return nil, ErrNotFoundData
+	}
+	return s.tree.GenerateProof(leaf)
+}
+
+// VerifyFile verifies that a snapshot holds the file with the given path and
+// content, using a proof from ProveFile
+func (c *Catalog) VerifyFile(id, name string, content []byte, proof Proof) (bool, error) {
+	s, err := c.snapshot(id)
+	if err != nil {
+		return false, err
+	}
+	if s.tree == nil {
+		return false, nil
+	}
+	return s.tree.VerifyData(FileLeaf(path.Clean(name), content), proof), nil
+}
+
+// snapshot looks up a snapshot by ID
+func (c *Catalog) snapshot(id string) (*catalogSnapshot, error) {
+	s, ok := c.snapshots[id]
+	if _, pruned := c.pruned[id]; pruned {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotPruned, id)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSnapshot, id)
+	}
+	return s, nil
+}
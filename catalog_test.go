@@ -0,0 +1,107 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Catalog(t *testing.T) {
+	c := NewCatalog()
+	monday, err := c.AddSnapshot("monday", map[string][]byte{
+		"etc/app.conf": []byte("v1"),
+		"var/db":       []byte("rows"),
+		"tmp/scratch":  []byte("scratch"),
+	})
+	require.NoError(t, err)
+	_, err = c.AddSnapshot("tuesday", map[string][]byte{
+		"etc/app.conf": []byte("v2"),
+		"var/db":       []byte("rows"),
+		"var/log":      []byte("log"),
+	})
+	require.NoError(t, err)
+	_, err = c.AddSnapshot("wednesday", map[string][]byte{
+		"etc/app.conf": []byte("v2"),
+		"var/db":       []byte("rows"),
+		"var/log":      []byte("log"),
+	})
+	require.NoError(t, err)
+
+	t.Run("should record a root per snapshot", func(t *testing.T) {
+		require.Equal(t, []string{"monday", "tuesday", "wednesday"}, c.Snapshots())
+		root, err := c.Root("monday")
+		require.NoError(t, err)
+		require.Equal(t, monday, root)
+	})
+
+	t.Run("should diff snapshots", func(t *testing.T) {
+		diff, err := c.Diff("monday", "tuesday")
+		require.NoError(t, err)
+		require.Equal(t, SnapshotDiff{
+			Added:    []string{"var/log"},
+			Removed:  []string{"tmp/scratch"},
+			Modified: []string{"etc/app.conf"},
+		}, diff)
+
+		diff, err = c.Diff("tuesday", "wednesday")
+		require.NoError(t, err)
+		require.Equal(t, SnapshotDiff{}, diff)
+	})
+
+	t.Run("should prove a file in a snapshot", func(t *testing.T) {
+		proof, err := c.ProveFile("monday", "etc/app.conf")
+		require.NoError(t, err)
+
+		ok, err := c.VerifyFile("monday", "etc/app.conf", []byte("v1"), proof)
+		require.NoError(t, err)
+		require.True(t, ok)
+		ok, err = c.VerifyFile("monday", "etc/app.conf", []byte("v2"), proof)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("should return error for unknown snapshots and files", func(t *testing.T) {
+		_, err := c.Root("sunday")
+		require.ErrorIs(t, err, ErrUnknownSnapshot)
+		_, err = c.ProveFile("monday", "var/log")
+		require.ErrorIs(t, err, ErrNotFoundData)
+		_, err = c.AddSnapshot("monday", map[string][]byte{"a": nil})
+		require.ErrorIs(t, err, ErrDuplicateSnapshot)
+	})
+
+	t.Run("should diff snapshots whose leaves are sorted or blinded", func(t *testing.T) {
+		for _, opt := range []Option{WithSortedLeaves(), WithBlinding()} {
+			c := NewCatalog(opt)
+			_, err := c.AddSnapshot("before", map[string][]byte{"a": []byte("1"), "b": []byte("2"), "c": []byte("3")})
+			require.NoError(t, err)
+			_, err = c.AddSnapshot("after", map[string][]byte{"a": []byte("1"), "b": []byte("two"), "c": []byte("3")})
+			require.NoError(t, err)
+
+			diff, err := c.Diff("before", "after")
+			require.NoError(t, err)
+			require.Equal(t, SnapshotDiff{Modified: []string{"b"}}, diff)
+
+			proof, err := c.ProveFile("after", "b")
+			require.NoError(t, err)
+			ok, err := c.VerifyFile("after", "b", []byte("two"), proof)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+	})
+
+	t.Run("should diff against an empty snapshot", func(t *testing.T) {
+		root, err := c.AddSnapshot("empty", map[string][]byte{})
+		require.NoError(t, err)
+		require.Nil(t, root)
+
+		diff, err := c.Diff("empty", "wednesday")
+		require.NoError(t, err)
+		require.Equal(t, SnapshotDiff{Added: []string{"etc/app.conf", "var/db", "var/log"}}, diff)
+		diff, err = c.Diff("wednesday", "empty")
+		require.NoError(t, err)
+		require.Equal(t, SnapshotDiff{Removed: []string{"etc/app.conf", "var/db", "var/log"}}, diff)
+
+		_, err = c.ProveFile("empty", "var/db")
+		require.ErrorIs(t, err, ErrNotFoundData)
+	})
+}
@@ -0,0 +1,85 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrBrokenChain = errors.New("broken root chain")
+
+// ChainBlock is a signed tree root linked to the block before it
+type ChainBlock struct {
+	Height    uint64    `json:"height"`
+	Prev      []byte    `json:"prev"`
+	Root      []byte    `json:"root"`
+	Timestamp time.Time `json:"timestamp"`
+	Signature []byte    `json:"signature"`
+}
+
+// Hash returns the hash of the block without its signature, which the
+// signature covers and the next block points to
+func (b ChainBlock) Hash() []byte {
+	msg := binary.BigEndian.AppendUint64(nil, b.Height)
+	msg = binary.AppendUvarint(msg, uint64(len(b.Prev)))
+	msg = append(msg, b.Prev...)
+	msg = binary.AppendUvarint(msg, uint64(len(b.Root)))
+	msg = append(msg, b.Root...)
+	msg = binary.BigEndian.AppendUint64(msg, uint64(b.Timestamp.UnixNano()))
+	sum := sha256.Sum256(msg)
+	return sum[:]
+}
+
+// Chain is a minimal ledger of tree states: each block signs a root and
+// points to the hash of the previous block
+type Chain struct {
+	key    ed25519.PrivateKey
+	blocks []ChainBlock
+}
+
+// NewChain creates an empty chain whose blocks are signed with key
+func NewChain(key ed25519.PrivateKey) *Chain {
+	return &Chain{key: key}
+}
+
+// Append signs a root as the next block of the chain
+func (c *Chain) Append(root []byte, at time.Time) ChainBlock {
+	b := ChainBlock{Height: uint64(len(c.blocks)), Root: root, Timestamp: at.UTC()}
+	if len(c.blocks) > 0 {
+		b.Prev = c.blocks[len(c.blocks)-1].Hash()
+	}
+	b.Signature = ed25519.Sign(c.key, b.Hash())
+	c.blocks = append(c.blocks, b)
+	return b
+}
+
+// Blocks returns every block of the chain from genesis
+func (c *Chain) Blocks() []ChainBlock {
+	return append([]ChainBlock{}, c.blocks...)
+}
+
+// VerifyChain checks that the blocks form a chain from genesis signed by pub,
+// with consecutive heights, matching links and non-decreasing timestamps
+func VerifyChain(blocks []ChainBlock, pub ed25519.PublicKey) error {
+	var prev *ChainBlock
+	for i, b := range blocks {
+		switch {
+		case b.Height != uint64(i):
+			return fmt.Errorf("%w: block %d has height %d", ErrBrokenChain, i, b.Height)
+		case prev == nil && b.Prev != nil:
+			return fmt.Errorf("%w: genesis block has a previous block", ErrBrokenChain)
+		case prev != nil && !bytes.Equal(b.Prev, prev.Hash()):
+			return fmt.Errorf("%w: block %d does not link to block %d", ErrBrokenChain, i, i-1)
+		case prev != nil && b.Timestamp.Before(prev.Timestamp):
+			return fmt.Errorf("%w: block %d predates block %d", ErrBrokenChain, i, i-1)
+		case !ed25519.Verify(pub, b.Hash(), b.Signature):
+			return fmt.Errorf("%w: block %d: %w", ErrBrokenChain, i, ErrInvalidSignature)
+		}
+		prev = &blocks[i]
+	}
+	return nil
+}
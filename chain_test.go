@@ -0,0 +1,59 @@
+package merkle
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Chain(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := NewChain(priv)
+	for i, leaves := range [][][]byte{{[]byte("a")}, {[]byte("a"), []byte("b")}, {[]byte("a"), []byte("b"), []byte("c")}} {
+		tree, err := New(leaves)
+		require.NoError(t, err)
+		c.Append(tree.Root(), at.Add(time.Duration(i)*time.Hour))
+	}
+	blocks := c.Blocks()
+
+	t.Run("should link blocks", func(t *testing.T) {
+		require.Len(t, blocks, 3)
+		require.Nil(t, blocks[0].Prev)
+		require.Equal(t, blocks[1].Hash(), blocks[2].Prev)
+		require.NoError(t, VerifyChain(blocks, pub))
+	})
+
+	t.Run("should verify after a round trip", func(t *testing.T) {
+		b, err := json.Marshal(blocks)
+		require.NoError(t, err)
+		var decoded []ChainBlock
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		require.NoError(t, VerifyChain(decoded, pub))
+	})
+
+	t.Run("should return error for tampered chains", func(t *testing.T) {
+		tampered := c.Blocks()
+		tampered[1].Root = []byte("forged")
+		require.ErrorIs(t, VerifyChain(tampered, pub), ErrBrokenChain)
+
+		require.ErrorIs(t, VerifyChain(blocks[1:], pub), ErrBrokenChain)
+		require.ErrorIs(t, VerifyChain([]ChainBlock{blocks[0], blocks[2]}, pub), ErrBrokenChain)
+
+		other, _, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+		require.ErrorIs(t, VerifyChain(blocks, other), ErrInvalidSignature)
+	})
+
+	t.Run("should return error for timestamps going backwards", func(t *testing.T) {
+		c := NewChain(priv)
+		c.Append([]byte("a"), at)
+		c.Append([]byte("b"), at.Add(-time.Second))
+		require.ErrorIs(t, VerifyChain(c.Blocks(), pub), ErrBrokenChain)
+	})
+}
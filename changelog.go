@@ -0,0 +1,65 @@
+package merkle
+
+import "bytes"
+
+// NodeChange is the change of the node at a level and index between two
+// commits; OldHash is nil for a node that did not exist before, and NewHash is
+// nil for a node that no longer exists, as after a RollbackTo
+type NodeChange struct {
+	Level   int    `json:"level"`
+	Index   int    `json:"index"`
+	OldHash []byte `json:"old_hash,omitempty"`
+	NewHash []byte `json:"new_hash"`
+}
+
+// WithChangelog calls fn after every commit of the tree, from its construction
+// on, with the nodes whose hash changed, ordered by level and index, so
+// external indexes, caches and replicated stores can apply deltas instead of
+// re-reading the tree. fn runs with the tree locked and must not call into it.
+func WithChangelog(fn func([]NodeChange)) Option {
+	return func(m *MerkleTree) {
+		m.changelog = fn
+	}
+}
+
+// commit starts a new version of the tree and emits the node changes since
+// the last commit, if a changelog is set
+func (m *MerkleTree) commit() {
+	m.version++
+	m.recordVersion()
+	if m.changelog == nil {
+		return
+	}
+
+	var changes []NodeChange
+	committed := make([][][]byte, len(m.levels))
+	for k := 0; k < max(len(m.levels), len(m.committed)); k++ {
+		var level []*Node
+		if k < len(m.levels) {
+			level = m.levels[k]
+			committed[k] = make([][]byte, len(level))
+		}
+		var old [][]byte
+		if k < len(m.committed) {
+			old = m.committed[k]
+		}
+		for j, node := range level {
+			committed[k][j] = node.hash
+			if j >= len(old) || !bytes.Equal(old[j], node.hash) {
+				var oldHash []byte
+				if j < len(old) {
+					oldHash = old[j]
+				}
+				changes = append(changes, NodeChange{Level: k, Index: j, OldHash: oldHash, NewHash: node.hash})
+			}
+		}
+		for j := len(level); j < len(old); j++ {
+			changes = append(changes, NodeChange{Level: k, Index: j, OldHash: old[j]})
+		}
+	}
+	m.committed = committed
+
+	if len(changes) > 0 {
+		m.changelog(changes)
+	}
+}
@@ -0,0 +1,62 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithChangelog(t *testing.T) {
+	var commits [][]NodeChange
+	tree, err := New([][]byte{[]byte("a"), []byte("b"), []byte("c")}, WithHashFunction(mockHash), WithChangelog(func(changes []NodeChange) {
+		commits = append(commits, changes)
+	}))
+	require.NoError(t, err)
+
+	t.Run("should emit every node on construction", func(t *testing.T) {
+		require.Len(t, commits, 1)
+		require.Len(t, commits[0], 6)
+		require.Equal(t, NodeChange{Level: 2, Index: 0, NewHash: tree.Root()}, commits[0][5])
+	})
+
+	t.Run("should emit the changed path on update", func(t *testing.T) {
+		require.NoError(t, tree.UpdateLeaf([]byte("b"), []byte("x")))
+		require.Equal(t, []NodeChange{
+			{Level: 0, Index: 1, OldHash: []byte("hash(b)"), NewHash: []byte("hash(x)")},
+			{Level: 1, Index: 0, OldHash: []byte("hash(hash(a)hash(b))"), NewHash: []byte("hash(hash(a)hash(x))")},
+			{Level: 2, Index: 0, OldHash: []byte("hash(hash(hash(a)hash(b))hash(hash(c)hash(c)))"), NewHash: []byte("hash(hash(hash(a)hash(x))hash(hash(c)hash(c)))")},
+		}, commits[1])
+	})
+
+	t.Run("should emit new nodes on append", func(t *testing.T) {
+		tree.AddLeaf([]byte("d"))
+		require.Equal(t, []NodeChange{
+			{Level: 0, Index: 3, NewHash: []byte("hash(d)")},
+			{Level: 1, Index: 1, OldHash: []byte("hash(hash(c)hash(c))"), NewHash: []byte("hash(hash(c)hash(d))")},
+			{Level: 2, Index: 0, OldHash: []byte("hash(hash(hash(a)hash(x))hash(hash(c)hash(c)))"), NewHash: []byte("hash(hash(hash(a)hash(x))hash(hash(c)hash(d)))")},
+		}, commits[2])
+	})
+
+	t.Run("should let a replica replay the deltas", func(t *testing.T) {
+		require.NoError(t, tree.ApplyUpdates([]LeafUpdate{{Index: 0, Data: []byte("y")}, {Index: 3, Data: []byte("z")}}))
+		require.Len(t, commits, 4)
+
+		replica := map[[2]int][]byte{}
+		for _, changes := range commits {
+			for _, c := range changes {
+				require.Equal(t, replica[[2]int{c.Level, c.Index}], c.OldHash)
+				replica[[2]int{c.Level, c.Index}] = c.NewHash
+			}
+		}
+		for k, level := range tree.Dump().Levels {
+			for j, hash := range level {
+				require.Equal(t, hash, replica[[2]int{k, j}])
+			}
+		}
+	})
+
+	t.Run("should not emit empty commits", func(t *testing.T) {
+		require.NoError(t, tree.ApplyUpdates(nil))
+		require.Len(t, commits, 4)
+	})
+}
@@ -0,0 +1,108 @@
+package merkle
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// NewFromReader creates a Merkle tree whose leaves are the consecutive
+// chunkSize chunks read from r; the last chunk may be shorter
+func NewFromReader(r io.Reader, chunkSize int, opts ...Option) (*MerkleTree, error) {
+	if chunkSize <= 0 {
+		return nil, ErrOutOfRange
+	}
+
+	var chunks [][]byte
+	for {
+		chunk := make([]byte, chunkSize)
+		n, err := io.ReadFull(r, chunk)
+		if n > 0 {
+			chunks = append(chunks, chunk[:n])
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return New(chunks, opts...)
+}
+
+// ChunkVerifier verifies random access reads from a file against the root of
+// its chunk tree, as built by NewFromReader
+type ChunkVerifier struct {
+	m         *MerkleTree
+	root      []byte
+	size      int64
+	chunkSize int64
+}
+
+// NewChunkVerifier creates a verifier for a file of the given size whose chunk
+// tree has the given root
+func NewChunkVerifier(root []byte, size, chunkSize int64, opts ...Option) *ChunkVerifier {
+	return &ChunkVerifier{m: newVerifier(opts...), root: root, size: size, chunkSize: chunkSize}
+}
+
+// VerifySlice reads only the chunks covering [offset, offset+length) from r,
+// verifies each against its proof and returns the requested bytes. proofs[i]
+// is the proof of the i-th chunk covering the range.
+func (v *ChunkVerifier) VerifySlice(r io.ReaderAt, offset, length int64, proofs []Proof) ([]byte, error) {
+	if v.chunkSize <= 0 || offset < 0 || length < 0 || offset+length > v.size {
+		return nil, ErrOutOfRange
+	}
+	if length == 0 {
+		return []byte{}, nil
+	}
+
+	first, last := offset/v.chunkSize, (offset+length-1)/v.chunkSize
+	if int64(len(proofs)) != last-first+1 {
+		return nil, ErrMalformed
+	}
+
+	chunks := int((v.size + v.chunkSize - 1) / v.chunkSize)
+	h := v.m.newHash()
+
+	var data []byte
+	for i := first; i <= last; i++ {
+		chunk := make([]byte, min(v.chunkSize, v.size-i*v.chunkSize))
+		if n, err := r.ReadAt(chunk, i*v.chunkSize); n != len(chunk) {
+			return nil, fmt.Errorf("chunk %d: %w", i, err)
+		}
+
+		// the sides must describe the chunk's own position, not another chunk with the same content
+		proof := proofs[i-first]
+		sides, err := proofSides(int(i), chunks, v.m.promoteOdd)
+		if err != nil {
+			return nil, err
+		}
+		if !sidesMatch(proof, sides) {
+			return nil, fmt.Errorf("chunk %d: %w", i, ErrInvalidProof)
+		}
+
+		hash, err := v.m.hashData(chunk)
+		if err != nil || !v.m.provesRoot(h, hash, proof, v.root) {
+			return nil, fmt.Errorf("chunk %d: %w", i, ErrInvalidProof)
+		}
+
+		data = append(data, chunk...)
+	}
+
+	start := offset - first*v.chunkSize
+	return data[start : start+length], nil
+}
+
+// sidesMatch reports whether the proof elements have exactly the given sides
+func sidesMatch(proof Proof, sides []Side) bool {
+	if len(proof) != len(sides) {
+		return false
+	}
+	for i, pe := range proof {
+		if pe.Side != sides[i] {
+			return false
+		}
+	}
+	return true
+}
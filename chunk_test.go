@@ -0,0 +1,68 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewFromReader(t *testing.T) {
+	t.Run("should split the input into chunks", func(t *testing.T) {
+		tree, err := NewFromReader(bytes.NewReader([]byte("abcdefg")), 3, WithHashFunction(mockHash))
+		require.NoError(t, err)
+		require.Equal(t, "hash(hash(hash(abc)hash(def))hash(hash(g)hash(g)))", string(tree.Root()))
+	})
+
+	t.Run("should return error for empty input", func(t *testing.T) {
+		_, err := NewFromReader(bytes.NewReader(nil), 3)
+		require.ErrorIs(t, err, ErrEmptyData)
+	})
+}
+
+func Test_VerifySlice(t *testing.T) {
+	file := bytes.Repeat([]byte("0123456789"), 10) // 100 bytes, 13 chunks of 8
+	tree, err := NewFromReader(bytes.NewReader(file), 8)
+	require.NoError(t, err)
+
+	proofs := func(first, last int) []Proof {
+		var ps []Proof
+		for i := first; i <= last; i++ {
+			p, err := tree.GenerateProofAt(i)
+			require.NoError(t, err)
+			ps = append(ps, p)
+		}
+		return ps
+	}
+	v := NewChunkVerifier(tree.Root(), int64(len(file)), 8)
+
+	t.Run("should return the verified bytes of a slice", func(t *testing.T) {
+		data, err := v.VerifySlice(bytes.NewReader(file), 13, 20, proofs(1, 4))
+		require.NoError(t, err)
+		require.Equal(t, file[13:33], data)
+	})
+
+	t.Run("should verify the short last chunk", func(t *testing.T) {
+		data, err := v.VerifySlice(bytes.NewReader(file), 95, 5, proofs(11, 12))
+		require.NoError(t, err)
+		require.Equal(t, file[95:], data)
+	})
+
+	t.Run("should reject tampered content", func(t *testing.T) {
+		tampered := append([]byte{}, file...)
+		tampered[20] = 'x'
+		_, err := v.VerifySlice(bytes.NewReader(tampered), 16, 8, proofs(2, 2))
+		require.ErrorIs(t, err, ErrInvalidProof)
+	})
+
+	t.Run("should reject a proof for another chunk with the same content", func(t *testing.T) {
+		// chunk 0 and chunk 5 both hold "01234567"
+		_, err := v.VerifySlice(bytes.NewReader(file), 40, 8, proofs(0, 0))
+		require.ErrorIs(t, err, ErrInvalidProof)
+	})
+
+	t.Run("should return error for ranges beyond the file", func(t *testing.T) {
+		_, err := v.VerifySlice(bytes.NewReader(file), 96, 8, proofs(12, 12))
+		require.ErrorIs(t, err, ErrOutOfRange)
+	})
+}
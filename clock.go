@@ -0,0 +1,188 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrMissingParent = errors.New("event references an unknown parent")
+
+// Order is the causal order of two clock events
+type Order int
+
+const (
+	Equal Order = iota
+	Before
+	After
+	Concurrent
+)
+
+// Event is a node of a Merkle clock: a payload linked to the hashes of the
+// events it causally follows
+type Event struct {
+	Parents [][]byte `json:"parents"`
+	Payload []byte   `json:"payload"`
+}
+
+// Clock is a Merkle clock, the causally ordered event DAG of Merkle-CRDTs.
+// Every event is identified by its hash, so replicas can merge by exchanging
+// the events missing below each other's heads.
+type Clock struct {
+	m      *MerkleTree
+	events map[string]Event
+	heads  [][]byte
+}
+
+// NewClock creates an empty clock hashing events with the given options
+func NewClock(opts ...Option) *Clock {
+	return &Clock{m: newVerifier(opts...), events: map[string]Event{}}
+}
+
+// Hash returns the hash identifying an event
+func (c *Clock) Hash(e Event) []byte {
+	b := binary.AppendUvarint(nil, uint64(len(e.Parents)))
+	for _, p := range e.Parents {
+		b = binary.AppendUvarint(b, uint64(len(p)))
+		b = append(b, p...)
+	}
+	return c.m.hashLeaf(append(b, e.Payload...))
+}
+
+// Add records a local event following every current head and returns its hash
+func (c *Clock) Add(payload []byte) []byte {
+	hash, _ := c.Put(Event{Parents: c.Heads(), Payload: payload})
+	return hash
+}
+
+// Put records an event whose parents are all known and returns its hash
+func (c *Clock) Put(e Event) ([]byte, error) {
+	hash := c.Hash(e)
+	if _, ok := c.events[string(hash)]; ok {
+		return hash, nil
+	}
+	for _, p := range e.Parents {
+		if _, ok := c.events[string(p)]; !ok {
+			return nil, fmt.Errorf("%w: %x", ErrMissingParent, p)
+		}
+	}
+
+	c.events[string(hash)] = e
+	heads := [][]byte{hash}
+	for _, head := range c.heads {
+		if !containsHash(e.Parents, head) {
+			heads = append(heads, head)
+		}
+	}
+	sort.Slice(heads, func(i, j int) bool { return bytes.Compare(heads[i], heads[j]) < 0 })
+	c.heads = heads
+
+	return hash, nil
+}
+
+// Heads returns the hashes of the events no other event follows, in order
+func (c *Clock) Heads() [][]byte {
+	return append([][]byte{}, c.heads...)
+}
+
+// Get returns the event with the given hash
+func (c *Clock) Get(hash []byte) (Event, error) {
+	e, ok := c.events[string(hash)]
+	if !ok {
+		return Event{}, ErrNotFoundData
+	}
+	return e, nil
+}
+
+// Sync merges a remote replica given its heads, fetching only the events
+// missing here: the walk stops at every event that is already known
+func (c *Clock) Sync(heads [][]byte, fetch func(hash []byte) (Event, error)) error {
+	// fetch missing events newest first, then put them oldest first
+	var missing []Event
+	seen := map[string]bool{}
+	queue := append([][]byte{}, heads...)
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if _, ok := c.events[string(hash)]; ok || seen[string(hash)] {
+			continue
+		}
+		seen[string(hash)] = true
+
+		e, err := fetch(hash)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(c.Hash(e), hash) {
+			return fmt.Errorf("%w: fetched event does not match %x", ErrMalformed, hash)
+		}
+		missing = append(missing, e)
+		queue = append(queue, e.Parents...)
+	}
+
+	for len(missing) > 0 {
+		progress := false
+		rest := missing[:0]
+		for _, e := range missing {
+			if _, err := c.Put(e); err != nil {
+				rest = append(rest, e)
+				continue
+			}
+			progress = true
+		}
+		if !progress {
+			return ErrMissingParent
+		}
+		missing = rest
+	}
+	return nil
+}
+
+// Compare returns the causal order of event a relative to event b
+func (c *Clock) Compare(a, b []byte) (Order, error) {
+	for _, hash := range [][]byte{a, b} {
+		if _, ok := c.events[string(hash)]; !ok {
+			return 0, ErrNotFoundData
+		}
+	}
+	switch {
+	case bytes.Equal(a, b):
+		return Equal, nil
+	case c.follows(b, a):
+		return Before, nil
+	case c.follows(a, b):
+		return After, nil
+	default:
+		return Concurrent, nil
+	}
+}
+
+// follows reports whether event a causally follows event b
+func (c *Clock) follows(a, b []byte) bool {
+	seen := map[string]bool{}
+	stack := append([][]byte{}, c.events[string(a)].Parents...)
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if bytes.Equal(hash, b) {
+			return true
+		}
+		if !seen[string(hash)] {
+			seen[string(hash)] = true
+			stack = append(stack, c.events[string(hash)].Parents...)
+		}
+	}
+	return false
+}
+
+// containsHash reports whether hashes contains hash
+func containsHash(hashes [][]byte, hash []byte) bool {
+	for _, h := range hashes {
+		if bytes.Equal(h, hash) {
+			return true
+		}
+	}
+	return false
+}
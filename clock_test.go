@@ -0,0 +1,85 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Clock(t *testing.T) {
+	t.Run("should follow the heads with local events", func(t *testing.T) {
+		c := NewClock()
+		a := c.Add([]byte("a"))
+		b := c.Add([]byte("b"))
+		require.Equal(t, [][]byte{b}, c.Heads())
+
+		e, err := c.Get(b)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{a}, e.Parents)
+
+		order, err := c.Compare(a, b)
+		require.NoError(t, err)
+		require.Equal(t, Before, order)
+	})
+
+	t.Run("should merge concurrent replicas", func(t *testing.T) {
+		alice, bob := NewClock(), NewClock()
+		root := alice.Add([]byte("root"))
+		require.NoError(t, bob.Sync(alice.Heads(), alice.Get))
+
+		x := alice.Add([]byte("x"))
+		y := bob.Add([]byte("y"))
+
+		order, err := alice.Compare(x, root)
+		require.NoError(t, err)
+		require.Equal(t, After, order)
+
+		require.NoError(t, alice.Sync(bob.Heads(), bob.Get))
+		require.NoError(t, bob.Sync(alice.Heads(), alice.Get))
+		require.Equal(t, alice.Heads(), bob.Heads())
+		require.Len(t, alice.Heads(), 2)
+
+		order, err = alice.Compare(x, y)
+		require.NoError(t, err)
+		require.Equal(t, Concurrent, order)
+
+		merged := alice.Add([]byte("merge"))
+		require.Equal(t, [][]byte{merged}, alice.Heads())
+		for _, h := range [][]byte{x, y} {
+			order, err := alice.Compare(merged, h)
+			require.NoError(t, err)
+			require.Equal(t, After, order)
+		}
+	})
+
+	t.Run("should fetch only missing events", func(t *testing.T) {
+		alice, bob := NewClock(), NewClock()
+		alice.Add([]byte("1"))
+		require.NoError(t, bob.Sync(alice.Heads(), alice.Get))
+		alice.Add([]byte("2"))
+		alice.Add([]byte("3"))
+
+		fetched := 0
+		require.NoError(t, bob.Sync(alice.Heads(), func(hash []byte) (Event, error) {
+			fetched++
+			return alice.Get(hash)
+		}))
+		require.Equal(t, 2, fetched)
+		require.Equal(t, alice.Heads(), bob.Heads())
+	})
+
+	t.Run("should return error for events with unknown parents", func(t *testing.T) {
+		c := NewClock()
+		_, err := c.Put(Event{Parents: [][]byte{[]byte("unknown")}})
+		require.ErrorIs(t, err, ErrMissingParent)
+	})
+
+	t.Run("should return error for fetched events not matching their hash", func(t *testing.T) {
+		alice, bob := NewClock(), NewClock()
+		alice.Add([]byte("a"))
+		err := bob.Sync(alice.Heads(), func([]byte) (Event, error) {
+			return Event{Payload: []byte("forged")}, nil
+		})
+		require.ErrorIs(t, err, ErrMalformed)
+	})
+}
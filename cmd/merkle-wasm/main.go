@@ -0,0 +1,13 @@
+//go:build js && wasm
+
+// Command merkle-wasm registers the proof bindings for browsers:
+//
+//	GOOS=js GOARCH=wasm go build -o merkle.wasm ./cmd/merkle-wasm
+package main
+
+import "github.com/chakra-guy/merkle/wasm"
+
+func main() {
+	wasm.Register()
+	select {}
+}
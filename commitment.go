@@ -0,0 +1,87 @@
+package merkle
+
+import (
+	"bytes"
+	"hash"
+)
+
+// VectorCommitment commits to the children of an interior node of an n-ary
+// tree and opens single positions of it, so wide nodes can use commitments
+// such as KZG or IPA whose openings are smaller than all the siblings
+type VectorCommitment interface {
+	// Commit returns the commitment to the children, used as the node hash
+	Commit(children [][]byte) ([]byte, error)
+	// Open returns a proof that children[index] is committed to
+	Open(children [][]byte, index int) ([]byte, error)
+	// Verify reports whether the opening proves child is at index under the commitment
+	Verify(commitment []byte, index int, child, opening []byte) bool
+}
+
+// WithVectorCommitment makes trees built with NewNary commit to the children of
+// every interior node with vc instead of hashing their concatenation
+func WithVectorCommitment(vc VectorCommitment) Option {
+	return func(m *MerkleTree) {
+		m.commitment = vc
+	}
+}
+
+// ConcatCommitment is the default vector commitment: the hash of the
+// concatenated children, opened by the concatenation of the other children
+type ConcatCommitment struct {
+	m *MerkleTree
+}
+
+// NewConcatCommitment creates a concatenation commitment with the given hashing
+// options; its commitments match the node hashes of NewNary without a commitment
+func NewConcatCommitment(opts ...Option) *ConcatCommitment {
+	return &ConcatCommitment{m: newVerifier(opts...)}
+}
+
+// Commit returns the hash of the concatenated children
+func (c *ConcatCommitment) Commit(children [][]byte) ([]byte, error) {
+	return c.m.hashChildren(c.m.newHash(), children), nil
+}
+
+// Open returns the concatenation of all children but children[index]
+func (c *ConcatCommitment) Open(children [][]byte, index int) ([]byte, error) {
+	if index < 0 || index >= len(children) {
+		return nil, ErrOutOfRange
+	}
+	var opening []byte
+	for i, child := range children {
+		if i != index {
+			opening = append(opening, child...)
+		}
+	}
+	return opening, nil
+}
+
+// Verify splits the opening into siblings of the child's size and checks that
+// together with the child they hash to the commitment
+func (c *ConcatCommitment) Verify(commitment []byte, index int, child, opening []byte) bool {
+	size := len(child)
+	if size == 0 || len(opening)%size != 0 || index < 0 || index > len(opening)/size {
+		return false
+	}
+
+	children := make([][]byte, 0, len(opening)/size+1)
+	for i := 0; i < len(opening); i += size {
+		if i/size == index {
+			children = append(children, child)
+		}
+		children = append(children, opening[i:i+size])
+	}
+	if index == len(opening)/size {
+		children = append(children, child)
+	}
+	return bytes.Equal(c.m.hashChildren(c.m.newHash(), children), commitment)
+}
+
+// commitChildren computes the node hash of an n-ary group with the configured
+// vector commitment, or by hashing the concatenated children
+func (m *MerkleTree) commitChildren(h hash.Hash, children [][]byte) ([]byte, error) {
+	if m.commitment == nil {
+		return m.hashChildren(h, children), nil
+	}
+	return m.commitment.Commit(children)
+}
@@ -0,0 +1,76 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_VectorCommitment(t *testing.T) {
+	var data [][]byte
+	for i := 0; i < 20; i++ {
+		data = append(data, []byte(fmt.Sprint(i)))
+	}
+
+	t.Run("should match the default node hashes with the concatenation commitment", func(t *testing.T) {
+		plain, err := NewNary(data, WithArity(4))
+		require.NoError(t, err)
+		committed, err := NewNary(data, WithArity(4), WithVectorCommitment(NewConcatCommitment()))
+		require.NoError(t, err)
+		require.Equal(t, plain.Root(), committed.Root())
+	})
+
+	t.Run("should verify commitment openings", func(t *testing.T) {
+		for _, promote := range []bool{false, true} {
+			opts := []Option{WithArity(8), WithVectorCommitment(NewConcatCommitment())}
+			if promote {
+				opts = append(opts, WithOddNodePromotion())
+			}
+			tree, err := NewNary(data, opts...)
+			require.NoError(t, err)
+
+			for i, leaf := range data {
+				proof, err := tree.GenerateProofAt(i)
+				require.NoError(t, err)
+				require.True(t, tree.VerifyData(leaf, proof))
+				require.True(t, VerifyNaryProof(tree.Root(), tree.m.hashLeaf(leaf), proof, opts...))
+				require.False(t, tree.VerifyData([]byte("x"), proof))
+			}
+		}
+	})
+
+	t.Run("should reject tampered openings", func(t *testing.T) {
+		tree, err := NewNary(data, WithArity(4), WithVectorCommitment(NewConcatCommitment()))
+		require.NoError(t, err)
+		proof, err := tree.GenerateProofAt(5)
+		require.NoError(t, err)
+
+		proof[0].Opening = proof[0].Opening[1:]
+		require.False(t, tree.VerifyData(data[5], proof))
+	})
+
+	t.Run("should only apply to n-ary trees", func(t *testing.T) {
+		_, err := New(data, WithVectorCommitment(NewConcatCommitment()))
+		require.ErrorIs(t, err, ErrArity)
+	})
+}
+
+func Test_ConcatCommitment(t *testing.T) {
+	c := NewConcatCommitment(WithHashFunction(mockHash))
+	children := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+
+	commitment, err := c.Commit(children)
+	require.NoError(t, err)
+	require.Equal(t, "hash(abc)", string(commitment))
+
+	for i, child := range children {
+		opening, err := c.Open(children, i)
+		require.NoError(t, err)
+		require.True(t, c.Verify(commitment, i, child, opening))
+		require.False(t, c.Verify(commitment, (i+1)%3, child, opening))
+	}
+
+	_, err = c.Open(children, 3)
+	require.ErrorIs(t, err, ErrOutOfRange)
+}
@@ -0,0 +1,85 @@
+package merkle
+
+import "hash"
+
+// CompactAppender computes the root of an append-only tree while keeping only
+// the O(log n) right-edge hashes, for devices that cannot hold the full tree.
+// Its roots match those of a MerkleTree built with the same options.
+type CompactAppender struct {
+	m        *MerkleTree
+	h        hash.Hash
+	frontier [][]byte // frontier[i] is the complete subtree of 2^i leaves, if bit i of size is set
+	size     int
+}
+
+// NewCompactAppender creates an empty appender with the given hashing options
+func NewCompactAppender(opts ...Option) *CompactAppender {
+	m := newVerifier(opts...)
+	return &CompactAppender{m: m, h: m.newHash()}
+}
+
+// Append adds a new leaf to the right edge of the tree
+func (c *CompactAppender) Append(data []byte) error {
+	hash, err := c.m.hashData(data)
+	if err != nil {
+		return err
+	}
+	c.AppendHash(hash)
+	return nil
+}
+
+// AppendHash adds a new leaf given its already computed leaf hash
+func (c *CompactAppender) AppendHash(hash []byte) {
+	level := 0
+	for ; c.size>>level&1 == 1; level++ {
+		hash = c.m.hashNodeWith(c.h, c.frontier[level], hash)
+		c.frontier[level] = nil
+	}
+
+	if level == len(c.frontier) {
+		c.frontier = append(c.frontier, nil)
+	}
+	c.frontier[level] = hash
+	c.size++
+}
+
+// Size returns the number of leaves appended so far
+func (c *CompactAppender) Size() int {
+	return c.size
+}
+
+// Root returns the root hash over all leaves appended so far
+func (c *CompactAppender) Root() ([]byte, error) {
+	if c.size == 0 {
+		return nil, ErrEmptyData
+	}
+
+	// carry is the rightmost node of the current level when it does not cover a
+	// complete subtree, so it is not part of the frontier
+	var carry []byte
+	level := 0
+	for ; (c.size+1<<level-1)>>level > 1; level++ {
+		complete := c.size>>level&1 == 1
+		switch {
+		case complete && carry != nil:
+			carry = c.m.hashNodeWith(c.h, c.frontier[level], carry)
+		case complete:
+			carry = c.unpaired(c.frontier[level])
+		case carry != nil:
+			carry = c.unpaired(carry)
+		}
+	}
+
+	if carry != nil {
+		return carry, nil
+	}
+	return c.frontier[level], nil
+}
+
+// unpaired returns the parent of a node without a right sibling
+func (c *CompactAppender) unpaired(hash []byte) []byte {
+	if c.m.promoteOdd {
+		return hash
+	}
+	return c.m.hashNodeWith(c.h, hash, hash)
+}
@@ -0,0 +1,49 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CompactAppender(t *testing.T) {
+	modes := map[string][]Option{
+		"default":  nil,
+		"promoted": {WithOddNodePromotion()},
+		"rfc6962":  {WithRFC6962()},
+	}
+
+	for name, opts := range modes {
+		t.Run("should match the full tree root in "+name+" mode", func(t *testing.T) {
+			c := NewCompactAppender(opts...)
+			var data [][]byte
+			for n := 1; n <= 40; n++ {
+				item := []byte(fmt.Sprint(n))
+				data = append(data, item)
+				c.Append(item)
+
+				tree, err := New(data, opts...)
+				require.NoError(t, err)
+
+				root, err := c.Root()
+				require.NoError(t, err)
+				require.Equal(t, tree.Root(), root, "size %d", n)
+				require.Equal(t, n, c.Size())
+			}
+		})
+	}
+
+	t.Run("should keep a logarithmic frontier", func(t *testing.T) {
+		c := NewCompactAppender()
+		for i := 0; i < 1000; i++ {
+			c.Append([]byte(fmt.Sprint(i)))
+		}
+		require.LessOrEqual(t, len(c.frontier), 10)
+	})
+
+	t.Run("should return error for an empty appender", func(t *testing.T) {
+		_, err := NewCompactAppender().Root()
+		require.ErrorIs(t, err, ErrEmptyData)
+	})
+}
@@ -0,0 +1,162 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// CompressedProof is a compact form of a Proof, where all sides are packed into
+// a single bitmap and sibling hashes the verifier can derive are left out
+type CompressedProof struct {
+	Length int
+	Sides  []byte   // bit i is set when element i is on the right
+	Elided []byte   // bit i is set when the hash of element i was left out
+	Hashes [][]byte // the hashes that were not elided, in order
+}
+
+// CompressProof compresses a proof for the leaf with the given hash, eliding
+// every sibling that duplicates the running hash (an odd node paired with itself)
+func (m *MerkleTree) CompressProof(hash []byte, proof Proof) CompressedProof {
+	cp := CompressedProof{
+		Length: len(proof),
+		Sides:  make([]byte, (len(proof)+7)/8),
+		Elided: make([]byte, (len(proof)+7)/8),
+	}
+
+	for i, pe := range proof {
+		if pe.Side == Right {
+			cp.Sides[i/8] |= 1 << (i % 8)
+		}
+		if bytes.Equal(pe.Hash, hash) {
+			cp.Elided[i/8] |= 1 << (i % 8)
+		} else {
+			cp.Hashes = append(cp.Hashes, pe.Hash)
+		}
+
+		if pe.Side == Left {
+			hash = m.hashNode(pe.Hash, hash)
+		} else {
+			hash = m.hashNode(hash, pe.Hash)
+		}
+	}
+
+	return cp
+}
+
+// DecompressProof restores the full proof for the leaf with the given hash
+func (m *MerkleTree) DecompressProof(hash []byte, cp CompressedProof) (Proof, error) {
+	if cp.Length < 0 || len(cp.Sides) != (cp.Length+7)/8 || len(cp.Elided) != len(cp.Sides) {
+		return nil, ErrMalformed
+	}
+
+	proof := make(Proof, cp.Length)
+	hashes := cp.Hashes
+	for i := range proof {
+		pe := ProofElement{Hash: hash, Side: Left}
+		if cp.Sides[i/8]&(1<<(i%8)) != 0 {
+			pe.Side = Right
+		}
+		if cp.Elided[i/8]&(1<<(i%8)) == 0 {
+			if len(hashes) == 0 {
+				return nil, ErrMalformed
+			}
+			pe.Hash, hashes = hashes[0], hashes[1:]
+		}
+		proof[i] = pe
+
+		if pe.Side == Left {
+			hash = m.hashNode(pe.Hash, hash)
+		} else {
+			hash = m.hashNode(hash, pe.Hash)
+		}
+	}
+	if len(hashes) != 0 {
+		return nil, ErrMalformed
+	}
+
+	return proof, nil
+}
+
+// VerifyCompressedProof verifies a compressed proof for the leaf with the given hash
+func (m *MerkleTree) VerifyCompressedProof(hash []byte, cp CompressedProof) bool {
+	proof, err := m.DecompressProof(hash, cp)
+	if err != nil {
+		return false
+	}
+	return m.VerifyProof(hash, proof)
+}
+
+// VerifyCompressedDataProof verifies a compressed proof for given data against
+// a root, without the tree; opts give the hashing options the tree was built with
+func VerifyCompressedDataProof(root, data []byte, cp CompressedProof, opts ...Option) bool {
+	m := newVerifier(opts...)
+	hash, err := m.hashData(data)
+	if err != nil {
+		return false
+	}
+	proof, err := m.DecompressProof(hash, cp)
+	if err != nil {
+		return false
+	}
+	return m.provesRoot(m.newHash(), hash, proof, root)
+}
+
+// MarshalBinary encodes the proof as the element count, both bitmaps, the hash
+// size and the remaining hashes
+func (cp CompressedProof) MarshalBinary() ([]byte, error) {
+	hashSize := 0
+	if len(cp.Hashes) > 0 {
+		hashSize = len(cp.Hashes[0])
+	}
+
+	b := binary.AppendUvarint(nil, uint64(cp.Length))
+	b = append(b, cp.Sides...)
+	b = append(b, cp.Elided...)
+	b = binary.AppendUvarint(b, uint64(hashSize))
+	for _, h := range cp.Hashes {
+		if len(h) != hashSize {
+			return nil, ErrMalformed
+		}
+		b = append(b, h...)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary
+func (cp *CompressedProof) UnmarshalBinary(b []byte) error {
+	length, n := binary.Uvarint(b)
+	if n <= 0 || length > uint64(len(b))*8 {
+		return ErrMalformed
+	}
+	b = b[n:]
+
+	size := int(length+7) / 8
+	if len(b) < 2*size {
+		return ErrMalformed
+	}
+	sides, elided := b[:size:size], b[size:2*size:2*size]
+	b = b[2*size:]
+
+	hashSize, n := binary.Uvarint(b)
+	if n <= 0 {
+		return ErrMalformed
+	}
+	b = b[n:]
+
+	var hashes [][]byte
+	if hashSize == 0 {
+		if len(b) != 0 {
+			return ErrMalformed
+		}
+	} else {
+		if uint64(len(b))%hashSize != 0 {
+			return ErrMalformed
+		}
+		for ; len(b) > 0; b = b[hashSize:] {
+			hashes = append(hashes, b[:hashSize:hashSize])
+		}
+	}
+
+	*cp = CompressedProof{Length: int(length), Sides: sides, Elided: elided, Hashes: hashes}
+	return nil
+}
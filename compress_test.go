@@ -0,0 +1,83 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CompressProof(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	tree, err := New(data, WithHashFunction(mockHash))
+	require.NoError(t, err)
+
+	t.Run("should elide hashes paired with themselves", func(t *testing.T) {
+		proof, err := tree.GenerateProof([]byte("e"))
+		require.NoError(t, err)
+		require.Len(t, proof, 3)
+
+		cp := tree.CompressProof([]byte("hash(e)"), proof)
+		require.Equal(t, 3, cp.Length)
+		require.Len(t, cp.Hashes, 1)
+		require.Equal(t, []byte{0b011}, cp.Sides)
+		require.Equal(t, []byte{0b011}, cp.Elided)
+	})
+
+	t.Run("should round-trip every proof through the binary encoding", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+
+		for _, item := range data {
+			proof, err := tree.GenerateProof(item)
+			require.NoError(t, err)
+
+			leaf := tree.hashLeaf(item)
+			b, err := tree.CompressProof(leaf, proof).MarshalBinary()
+			require.NoError(t, err)
+
+			var cp CompressedProof
+			require.NoError(t, cp.UnmarshalBinary(b))
+			require.True(t, tree.VerifyCompressedProof(leaf, cp))
+
+			decompressed, err := tree.DecompressProof(leaf, cp)
+			require.NoError(t, err)
+			require.Equal(t, proof, decompressed)
+		}
+	})
+
+	t.Run("should not verify against the wrong leaf", func(t *testing.T) {
+		proof, err := tree.GenerateProof([]byte("a"))
+		require.NoError(t, err)
+		cp := tree.CompressProof([]byte("hash(a)"), proof)
+		require.False(t, tree.VerifyCompressedProof([]byte("hash(b)"), cp))
+	})
+}
+
+func Test_DecompressProof(t *testing.T) {
+	tree, err := New([][]byte{[]byte("a"), []byte("b")}, WithHashFunction(mockHash))
+	require.NoError(t, err)
+
+	t.Run("should return error for missing hashes", func(t *testing.T) {
+		_, err := tree.DecompressProof([]byte("hash(a)"), CompressedProof{Length: 1, Sides: []byte{1}, Elided: []byte{0}})
+		require.ErrorIs(t, err, ErrMalformed)
+	})
+
+	t.Run("should return error for truncated encodings", func(t *testing.T) {
+		var cp CompressedProof
+		require.ErrorIs(t, cp.UnmarshalBinary([]byte{16, 0}), ErrMalformed)
+	})
+}
+
+func Test_VerifyCompressedDataProof(t *testing.T) {
+	tree, err := New([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	require.NoError(t, err)
+
+	t.Run("should verify against the root without the tree", func(t *testing.T) {
+		proof, err := tree.GenerateProofAt(2)
+		require.NoError(t, err)
+		cp := tree.CompressProof(tree.hashLeaf([]byte("c")), proof)
+		require.True(t, VerifyCompressedDataProof(tree.Root(), []byte("c"), cp))
+		require.False(t, VerifyCompressedDataProof(tree.Root(), []byte("a"), cp))
+		require.False(t, VerifyCompressedDataProof(tree.Root(), []byte("c"), cp, WithHashFunction(mockHash)))
+	})
+}
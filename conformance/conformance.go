@@ -0,0 +1,180 @@
+// Package conformance generates deterministic Merkle trees and golden test
+// vectors, so other implementations can verify compatibility against this one.
+package conformance
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/chakra-guy/merkle"
+)
+
+var (
+	ErrUnknownMode = errors.New("unknown conformance mode")
+	ErrMismatch    = errors.New("vector does not match this implementation")
+)
+
+// LeafSize is the size in bytes of every generated leaf
+const LeafSize = 32
+
+// Mode is a named set of tree options that vectors are generated for
+type Mode struct {
+	Name    string
+	Options []merkle.Option
+}
+
+// Modes lists every supported mode
+var Modes = []Mode{
+	{Name: "default"},
+	{Name: "rs-merkle", Options: []merkle.Option{merkle.WithRsMerkle()}},
+	{Name: "rfc6962", Options: []merkle.Option{merkle.WithRFC6962()}},
+	{Name: merkle.SchemeBitcoin.Name, Options: []merkle.Option{merkle.WithScheme(merkle.SchemeBitcoin)}},
+	{Name: merkle.SchemeOZSorted.Name, Options: []merkle.Option{merkle.WithScheme(merkle.SchemeOZSorted)}},
+	{Name: merkle.SchemeCometBFT.Name, Options: []merkle.Option{merkle.WithScheme(merkle.SchemeCometBFT)}},
+}
+
+// Vector is a golden test vector for a single tree
+type Vector struct {
+	Mode   string        `json:"mode"`
+	Seed   int64         `json:"seed"`
+	Leaves []string      `json:"leaves"`
+	Root   string        `json:"root"`
+	Proofs []ProofVector `json:"proofs"`
+}
+
+// ProofVector is the expected proof for the leaf at Index
+type ProofVector struct {
+	Index    int             `json:"index"`
+	Elements []ElementVector `json:"elements"`
+}
+
+// ElementVector is a single hex-encoded proof element
+type ElementVector struct {
+	Hash string `json:"hash"`
+	Side string `json:"side"`
+}
+
+// FindMode looks up a supported mode by name
+func FindMode(name string) (Mode, error) {
+	for _, mode := range Modes {
+		if mode.Name == name {
+			return mode, nil
+		}
+	}
+	return Mode{}, fmt.Errorf("%w: %q", ErrUnknownMode, name)
+}
+
+// Leaves deterministically generates n leaves from the given seed
+func Leaves(seed int64, n int) [][]byte {
+	rng := rand.New(rand.NewSource(seed))
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		leaves[i] = make([]byte, LeafSize)
+		rng.Read(leaves[i])
+	}
+	return leaves
+}
+
+// Generate builds a seeded tree of n leaves in the given mode and returns its vector
+func Generate(mode Mode, seed int64, n int) (Vector, error) {
+	leaves := Leaves(seed, n)
+	tree, err := merkle.New(leaves, mode.Options...)
+	if err != nil {
+		return Vector{}, err
+	}
+
+	v := Vector{
+		Mode: mode.Name,
+		Seed: seed,
+		Root: hex.EncodeToString(tree.Root()),
+	}
+	for i, leaf := range leaves {
+		v.Leaves = append(v.Leaves, hex.EncodeToString(leaf))
+
+		proof, err := tree.GenerateProof(leaf)
+		if err != nil {
+			return Vector{}, err
+		}
+		v.Proofs = append(v.Proofs, ProofVector{Index: i, Elements: encodeProof(proof)})
+	}
+
+	return v, nil
+}
+
+// Suite generates vectors for every supported mode and each of the given sizes
+func Suite(seed int64, sizes []int) ([]Vector, error) {
+	var vectors []Vector
+	for _, mode := range Modes {
+		for _, n := range sizes {
+			v, err := Generate(mode, seed, n)
+			if err != nil {
+				return nil, err
+			}
+			vectors = append(vectors, v)
+		}
+	}
+	return vectors, nil
+}
+
+// Check rebuilds the tree described by a vector and verifies its root and proofs
+func Check(v Vector) error {
+	mode, err := FindMode(v.Mode)
+	if err != nil {
+		return err
+	}
+
+	leaves := make([][]byte, len(v.Leaves))
+	for i, leaf := range v.Leaves {
+		if leaves[i], err = hex.DecodeString(leaf); err != nil {
+			return fmt.Errorf("leaf %d: %w", i, err)
+		}
+	}
+
+	tree, err := merkle.New(leaves, mode.Options...)
+	if err != nil {
+		return err
+	}
+
+	if root := hex.EncodeToString(tree.Root()); root != v.Root {
+		return fmt.Errorf("%w: root %s, want %s", ErrMismatch, root, v.Root)
+	}
+
+	for _, pv := range v.Proofs {
+		if pv.Index < 0 || pv.Index >= len(leaves) {
+			return fmt.Errorf("%w: proof index %d out of range", ErrMismatch, pv.Index)
+		}
+		proof, err := tree.GenerateProof(leaves[pv.Index])
+		if err != nil {
+			return err
+		}
+		if !equalElements(encodeProof(proof), pv.Elements) {
+			return fmt.Errorf("%w: proof for leaf %d", ErrMismatch, pv.Index)
+		}
+	}
+
+	return nil
+}
+
+// encodeProof converts a proof into its hex-encoded vector form
+func encodeProof(proof merkle.Proof) []ElementVector {
+	elements := make([]ElementVector, 0, len(proof))
+	for _, pe := range proof {
+		elements = append(elements, ElementVector{Hash: hex.EncodeToString(pe.Hash), Side: pe.Side.String()})
+	}
+	return elements
+}
+
+// equalElements reports whether two encoded proofs are identical
+func equalElements(a, b []ElementVector) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
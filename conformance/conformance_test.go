@@ -0,0 +1,75 @@
+package conformance
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden vectors")
+
+var (
+	goldenSeed  int64 = 42
+	goldenSizes       = []int{1, 2, 3, 4, 5, 8, 13}
+	goldenPath        = filepath.Join("testdata", "vectors.json")
+)
+
+func Test_Leaves(t *testing.T) {
+	t.Run("should be deterministic for a seed", func(t *testing.T) {
+		require.Equal(t, Leaves(7, 5), Leaves(7, 5))
+		require.NotEqual(t, Leaves(7, 5), Leaves(8, 5))
+	})
+}
+
+func Test_Generate(t *testing.T) {
+	mode, err := FindMode("default")
+	require.NoError(t, err)
+
+	t.Run("should generate a vector that passes its own check", func(t *testing.T) {
+		v, err := Generate(mode, 1, 7)
+		require.NoError(t, err)
+		require.Len(t, v.Leaves, 7)
+		require.Len(t, v.Proofs, 7)
+		require.NoError(t, Check(v))
+	})
+
+	t.Run("should detect a tampered root", func(t *testing.T) {
+		v, err := Generate(mode, 1, 7)
+		require.NoError(t, err)
+		v.Root = v.Leaves[0]
+		require.ErrorIs(t, Check(v), ErrMismatch)
+	})
+
+	t.Run("should return error for unknown mode", func(t *testing.T) {
+		require.ErrorIs(t, Check(Vector{Mode: "nope"}), ErrUnknownMode)
+	})
+}
+
+func Test_Suite(t *testing.T) {
+	vectors, err := Suite(goldenSeed, goldenSizes)
+	require.NoError(t, err)
+	require.Len(t, vectors, len(Modes)*len(goldenSizes))
+
+	if *update {
+		b, err := json.MarshalIndent(vectors, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(goldenPath, append(b, '\n'), 0o644))
+	}
+
+	t.Run("should match the golden vectors", func(t *testing.T) {
+		b, err := os.ReadFile(goldenPath)
+		require.NoError(t, err)
+
+		var golden []Vector
+		require.NoError(t, json.Unmarshal(b, &golden))
+		require.Equal(t, golden, vectors)
+
+		for _, v := range golden {
+			require.NoError(t, Check(v))
+		}
+	})
+}
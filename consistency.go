@@ -0,0 +1,106 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"math/bits"
+)
+
+var (
+	ErrNotPromoting       = errors.New("consistency proofs require a tree that promotes odd nodes")
+	ErrInvalidConsistency = errors.New("consistency proof does not link the roots")
+)
+
+// GenerateConsistencyProof returns the RFC 6962 consistency proof that the
+// tree over its first oldSize leaves is a prefix of the whole tree. Only trees
+// that promote odd nodes, such as WithRFC6962 trees, have prefix subtrees.
+func (m *MerkleTree) GenerateConsistencyProof(oldSize int) ([][]byte, error) {
+	if !m.promoteOdd {
+		return nil, ErrNotPromoting
+	}
+	m.Flush()
+	if oldSize <= 0 || oldSize > len(m.leafs) {
+		return nil, ErrOutOfRange
+	}
+	return m.subproof(oldSize, 0, len(m.leafs), true), nil
+}
+
+// subproof is SUBPROOF(m, D[lo:hi], b) from RFC 6962 section 2.1.2
+func (m *MerkleTree) subproof(size, lo, hi int, complete bool) [][]byte {
+	if size == hi-lo {
+		if complete {
+			return nil
+		}
+		return [][]byte{m.subtreeHash(lo, hi)}
+	}
+	k := splitPoint(hi - lo)
+	if size <= k {
+		return append(m.subproof(size, lo, lo+k, complete), m.subtreeHash(lo+k, hi))
+	}
+	return append(m.subproof(size-k, lo+k, hi, false), m.subtreeHash(lo, lo+k))
+}
+
+// subtreeHash is the hash of the subtree over leaves lo to hi, read from the
+// levels when the range is a perfect subtree
+func (m *MerkleTree) subtreeHash(lo, hi int) []byte {
+	if n := hi - lo; n&(n-1) == 0 {
+		k := bits.TrailingZeros(uint(n))
+		return m.levels[k][lo>>k].hash
+	}
+	k := splitPoint(hi - lo)
+	return m.hashNode(m.subtreeHash(lo, lo+k), m.subtreeHash(lo+k, hi))
+}
+
+// splitPoint returns the largest power of two smaller than n
+func splitPoint(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// VerifyConsistency verifies a consistency proof between the roots of the
+// trees over the first oldSize and newSize leaves, as in RFC 9162 section 2.1.4.2
+func VerifyConsistency(oldSize, newSize int, oldRoot, newRoot []byte, proof [][]byte, opts ...Option) error {
+	m := newVerifier(opts...)
+	switch {
+	case !m.promoteOdd:
+		return ErrNotPromoting
+	case oldSize <= 0 || oldSize > newSize:
+		return ErrOutOfRange
+	case oldSize == newSize:
+		if len(proof) != 0 || !bytes.Equal(oldRoot, newRoot) {
+			return ErrInvalidConsistency
+		}
+		return nil
+	}
+
+	if oldSize&(oldSize-1) == 0 {
+		proof = append([][]byte{oldRoot}, proof...)
+	}
+	if len(proof) == 0 {
+		return ErrMalformed
+	}
+
+	fn, sn := oldSize-1, newSize-1
+	for fn&1 == 1 {
+		fn, sn = fn>>1, sn>>1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return ErrMalformed
+		}
+		if fn&1 == 1 || fn == sn {
+			fr, sr = m.hashNode(c, fr), m.hashNode(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn, sn = fn>>1, sn>>1
+			}
+		} else {
+			sr = m.hashNode(sr, c)
+		}
+		fn, sn = fn>>1, sn>>1
+	}
+
+	if sn != 0 || !bytes.Equal(fr, oldRoot) || !bytes.Equal(sr, newRoot) {
+		return ErrInvalidConsistency
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GenerateConsistencyProof(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e"), []byte("f"), []byte("g")}
+
+	t.Run("should generate the RFC 6962 example proof", func(t *testing.T) {
+		tree, err := New(data, WithHashFunction(mockHash), WithOddNodePromotion())
+		require.NoError(t, err)
+
+		proof, err := tree.GenerateConsistencyProof(3)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"hash(c)",
+			"hash(d)",
+			"hash(hash(a)hash(b))",
+			"hash(hash(hash(e)hash(f))hash(g))",
+		}, hashStrings(proof))
+
+		proof, err = tree.GenerateConsistencyProof(4)
+		require.NoError(t, err)
+		require.Equal(t, []string{"hash(hash(hash(e)hash(f))hash(g))"}, hashStrings(proof))
+
+		proof, err = tree.GenerateConsistencyProof(7)
+		require.NoError(t, err)
+		require.Empty(t, proof)
+	})
+
+	t.Run("should return error for trees that duplicate odd nodes", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		_, err = tree.GenerateConsistencyProof(3)
+		require.ErrorIs(t, err, ErrNotPromoting)
+	})
+
+	t.Run("should return error for an out of range size", func(t *testing.T) {
+		tree, err := New(data, WithRFC6962())
+		require.NoError(t, err)
+		_, err = tree.GenerateConsistencyProof(0)
+		require.ErrorIs(t, err, ErrOutOfRange)
+		_, err = tree.GenerateConsistencyProof(8)
+		require.ErrorIs(t, err, ErrOutOfRange)
+	})
+}
+
+func Test_VerifyConsistency(t *testing.T) {
+	var data [][]byte
+	for i := 0; i < 33; i++ {
+		data = append(data, []byte(fmt.Sprint(i)))
+	}
+	roots := make([][]byte, len(data)+1)
+	for n := 1; n <= len(data); n++ {
+		tree, err := New(data[:n], WithRFC6962())
+		require.NoError(t, err)
+		roots[n] = tree.Root()
+	}
+
+	t.Run("should verify proofs between every pair of sizes", func(t *testing.T) {
+		for n := 1; n <= len(data); n++ {
+			tree, err := New(data[:n], WithRFC6962())
+			require.NoError(t, err)
+			for m := 1; m <= n; m++ {
+				proof, err := tree.GenerateConsistencyProof(m)
+				require.NoError(t, err)
+				require.NoError(t, VerifyConsistency(m, n, roots[m], roots[n], proof, WithRFC6962()), "%d to %d", m, n)
+			}
+		}
+	})
+
+	t.Run("should reject proofs for other roots or sizes", func(t *testing.T) {
+		tree, err := New(data[:13], WithRFC6962())
+		require.NoError(t, err)
+		proof, err := tree.GenerateConsistencyProof(5)
+		require.NoError(t, err)
+
+		require.ErrorIs(t, VerifyConsistency(5, 13, roots[6], roots[13], proof, WithRFC6962()), ErrInvalidConsistency)
+		require.ErrorIs(t, VerifyConsistency(5, 13, roots[5], roots[12], proof, WithRFC6962()), ErrInvalidConsistency)
+		require.Error(t, VerifyConsistency(6, 13, roots[6], roots[13], proof, WithRFC6962()))
+		require.Error(t, VerifyConsistency(5, 13, roots[5], roots[13], proof[1:], WithRFC6962()))
+		require.ErrorIs(t, VerifyConsistency(5, 5, roots[5], roots[5], proof, WithRFC6962()), ErrInvalidConsistency)
+		require.ErrorIs(t, VerifyConsistency(6, 5, roots[6], roots[5], proof, WithRFC6962()), ErrOutOfRange)
+		require.ErrorIs(t, VerifyConsistency(5, 13, roots[5], roots[13], proof), ErrNotPromoting)
+	})
+}
+
+// hashStrings converts mock hashes to their readable form
+func hashStrings(hashes [][]byte) []string {
+	s := make([]string, len(hashes))
+	for i, h := range hashes {
+		s[i] = string(h)
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package merkle
+
+import "crypto/subtle"
+
+// WithConstantTimeProofs makes proof generation scan every leaf and every node
+// of each level, selecting the path with constant-time operations, so the
+// time and memory accesses of generating a proof do not depend on which leaf
+// is proven. It is meant for trees over secret data served to several
+// tenants, and costs a full pass over the tree per proof. The lengths of the
+// looked-up data and, in trees promoting odd nodes, of the proof itself still
+// depend on the leaf, as the proof reveals them anyway.
+func WithConstantTimeProofs() Option {
+	return func(m *MerkleTree) {
+		m.constantTime = true
+	}
+}
+
+// scanLeafIndex returns the index of the first leaf for which match returns
+// 1, or -1, after calling match on every leaf
+func (m *MerkleTree) scanLeafIndex(match func(leaf *Node) int) int {
+	index, found := 0, 0
+	for i, leaf := range m.leafs {
+		hit := match(leaf) & (found ^ 1)
+		index = subtle.ConstantTimeSelect(hit, i, index)
+		found |= hit
+	}
+	return subtle.ConstantTimeSelect(found, index, -1)
+}
+
+// scanProof collects the proof of the leaf at index, reading every node of
+// each level and copying the sibling hash out with constant-time selects
+func (m *MerkleTree) scanProof(index int) Proof {
+	size := len(m.root.hash)
+	var proof Proof
+	for _, level := range m.levels[:len(m.levels)-1] {
+		sibling := index ^ 1
+		paired := subtle.ConstantTimeLessOrEq(sibling, len(level)-1)
+		// an unpaired node is its own sibling when odd nodes are duplicated
+		sibling = subtle.ConstantTimeSelect(paired, sibling, index)
+
+		hash := make([]byte, size)
+		for j, node := range level {
+			if len(node.hash) == size {
+				subtle.ConstantTimeCopy(subtle.ConstantTimeEq(int32(j), int32(sibling)), hash, node.hash)
+			}
+		}
+		pe := ProofElement{Hash: hash, Side: Side(index&1 ^ 1)}
+
+		if paired == 1 || !m.promoteOdd {
+			proof = append(proof, pe)
+		}
+		index >>= 1
+	}
+	return proof
+}
+
+// nodeIndex returns the index of a leaf node, after comparing every leaf
+func (m *MerkleTree) nodeIndex(node *Node) int {
+	return m.scanLeafIndex(func(leaf *Node) int {
+		return boolToInt(leaf == node)
+	})
+}
+
+// boolToInt returns 1 for true and 0 for false
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
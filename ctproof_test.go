@@ -0,0 +1,76 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithConstantTimeProofs(t *testing.T) {
+	var data [][]byte
+	for i := 0; i < 13; i++ {
+		data = append(data, []byte(fmt.Sprint("secret", i)))
+	}
+	data = append(data, data[4])
+
+	for name, opts := range map[string][]Option{
+		"duplicated": nil,
+		"promoted":   {WithOddNodePromotion()},
+		"dropped":    {WithoutStoringData()},
+	} {
+		t.Run("should generate the same proofs as the default path for "+name+" trees", func(t *testing.T) {
+			for n := 1; n <= len(data); n++ {
+				plain, err := New(data[:n], opts...)
+				require.NoError(t, err)
+				ct, err := New(data[:n], append(opts, WithConstantTimeProofs())...)
+				require.NoError(t, err)
+				for j := range data[:n] {
+					expected, err := plain.GenerateProofAt(j)
+					require.NoError(t, err)
+					proof, err := ct.GenerateProofAt(j)
+					require.NoError(t, err)
+					require.Equal(t, expected, proof, "leaf %d of %d", j, n)
+				}
+			}
+
+			plain, err := New(data, opts...)
+			require.NoError(t, err)
+			ct, err := New(data, append(opts, WithConstantTimeProofs())...)
+			require.NoError(t, err)
+
+			for _, leaf := range data {
+				expected, err := plain.GenerateProof(leaf)
+				require.NoError(t, err)
+				proof, err := ct.GenerateProof(leaf)
+				require.NoError(t, err)
+				require.Equal(t, expected, proof)
+				require.True(t, ct.VerifyData(leaf, proof))
+			}
+
+			hash := ct.Leaves()[4].Hash
+			expected, err := plain.GenerateProofByHash(hash)
+			require.NoError(t, err)
+			proof, err := ct.GenerateProofByHash(hash)
+			require.NoError(t, err)
+			require.Equal(t, expected, proof)
+		})
+	}
+
+	t.Run("should return error for unknown data", func(t *testing.T) {
+		tree, err := New(data, WithConstantTimeProofs())
+		require.NoError(t, err)
+		_, err = tree.GenerateProof([]byte("unknown"))
+		require.ErrorIs(t, err, ErrNotFoundData)
+		_, err = tree.GenerateProofByHash([]byte("unknown"))
+		require.ErrorIs(t, err, ErrNotFoundData)
+	})
+
+	t.Run("should keep the option when splitting", func(t *testing.T) {
+		tree, err := New(data, WithConstantTimeProofs())
+		require.NoError(t, err)
+		left, _, err := tree.Split(4)
+		require.NoError(t, err)
+		require.True(t, left.constantTime)
+	})
+}
@@ -0,0 +1,178 @@
+// Package differential cross-checks roots and proofs against reference
+// implementations for randomized inputs. It is a module of its own, so the
+// reference implementations are not dependencies of the merkle module:
+//
+//	cd differential && go test ./...
+package differential
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"flag"
+	"math/rand"
+	"testing"
+
+	"github.com/cbergoon/merkletree"
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	differentialSeed       = flag.Int64("differential.seed", 1, "seed of the randomized inputs")
+	differentialIterations = flag.Int("differential.iterations", 200, "number of randomized trees per reference")
+)
+
+// randomLeaves returns between 2 and 257 distinct random leaves
+func randomLeaves(r *rand.Rand) [][]byte {
+	leaves := make([][]byte, 2+r.Intn(256))
+	for i := range leaves {
+		leaves[i] = make([]byte, 1+r.Intn(64))
+		r.Read(leaves[i])
+		leaves[i] = append(leaves[i], byte(i), byte(i>>8))
+	}
+	return leaves
+}
+
+// content is a cbergoon/merkletree leaf hashed with plain SHA-256
+type content []byte
+
+func (c content) CalculateHash() ([]byte, error) {
+	h := sha256.Sum256(c)
+	return h[:], nil
+}
+
+func (c content) Equals(other merkletree.Content) (bool, error) {
+	return bytes.Equal(c, other.(content)), nil
+}
+
+// cbergoon/merkletree pairs a single leaf with itself, so the comparison
+// starts at two leaves
+func Test_DifferentialCbergoon(t *testing.T) {
+	r := rand.New(rand.NewSource(*differentialSeed))
+	for i := 0; i < *differentialIterations; i++ {
+		leaves := randomLeaves(r)
+		cs := make([]merkletree.Content, len(leaves))
+		for j, leaf := range leaves {
+			cs[j] = content(leaf)
+		}
+		ref, err := merkletree.NewTree(cs)
+		require.NoError(t, err)
+
+		tree, err := merkle.New(leaves)
+		require.NoError(t, err)
+		require.Equal(t, ref.MerkleRoot(), tree.Root(), "%d leaves", len(leaves))
+
+		j := r.Intn(len(leaves))
+		path, indexes, err := ref.GetMerklePath(cs[j])
+		require.NoError(t, err)
+		proof, err := tree.GenerateProofAt(j)
+		require.NoError(t, err)
+		require.Len(t, proof, len(path))
+		for k, pe := range proof {
+			require.Equal(t, path[k], pe.Hash)
+			require.Equal(t, indexes[k] == 1, pe.Side == merkle.Right)
+		}
+	}
+}
+
+// rfc6962Hash is MTH from RFC 6962 section 2.1, transcribed directly
+func rfc6962Hash(d [][]byte) []byte {
+	if len(d) == 1 {
+		h := sha256.Sum256(append([]byte{0x00}, d[0]...))
+		return h[:]
+	}
+	k := 1
+	for k*2 < len(d) {
+		k *= 2
+	}
+	h := sha256.Sum256(append(append([]byte{0x01}, rfc6962Hash(d[:k])...), rfc6962Hash(d[k:])...))
+	return h[:]
+}
+
+// rfc6962Path is PATH(m, D) from RFC 6962 section 2.1.1
+func rfc6962Path(m int, d [][]byte) [][]byte {
+	if len(d) == 1 {
+		return nil
+	}
+	k := 1
+	for k*2 < len(d) {
+		k *= 2
+	}
+	if m < k {
+		return append(rfc6962Path(m, d[:k]), rfc6962Hash(d[k:]))
+	}
+	return append(rfc6962Path(m-k, d[k:]), rfc6962Hash(d[:k]))
+}
+
+func Test_DifferentialRFC6962(t *testing.T) {
+	r := rand.New(rand.NewSource(*differentialSeed))
+	for i := 0; i < *differentialIterations; i++ {
+		leaves := randomLeaves(r)[r.Intn(2):]
+		root := rfc6962Hash(leaves)
+
+		for _, opt := range []merkle.Option{merkle.WithRFC6962(), merkle.WithScheme(merkle.SchemeRFC6962)} {
+			tree, err := merkle.New(leaves, opt)
+			require.NoError(t, err)
+			require.Equal(t, root, tree.Root(), "%d leaves", len(leaves))
+
+			j := r.Intn(len(leaves))
+			proof, err := tree.GenerateProofAt(j)
+			require.NoError(t, err)
+			path := rfc6962Path(j, leaves)
+			require.Len(t, proof, len(path))
+			for k, pe := range proof {
+				require.Equal(t, path[k], pe.Hash)
+			}
+		}
+
+		appender := merkle.NewCompactAppender(merkle.WithRFC6962())
+		for _, leaf := range leaves {
+			require.NoError(t, appender.Append(leaf))
+		}
+		got, err := appender.Root()
+		require.NoError(t, err)
+		require.Equal(t, root, got)
+	}
+}
+
+// bitcoinRoot is Bitcoin Core's ComputeMerkleRoot over txids in internal byte order
+func bitcoinRoot(txids [][]byte) []byte {
+	hashes := append([][]byte{}, txids...)
+	for len(hashes) > 1 {
+		if len(hashes)%2 == 1 {
+			hashes = append(hashes, hashes[len(hashes)-1])
+		}
+		next := make([][]byte, len(hashes)/2)
+		for i := range next {
+			first := sha256.Sum256(append(append([]byte{}, hashes[2*i]...), hashes[2*i+1]...))
+			second := sha256.Sum256(first[:])
+			next[i] = second[:]
+		}
+		hashes = next
+	}
+	return hashes[0]
+}
+
+func Test_DifferentialBitcoin(t *testing.T) {
+	r := rand.New(rand.NewSource(*differentialSeed))
+	for i := 0; i < *differentialIterations; i++ {
+		txids := make([][]byte, 1+r.Intn(300))
+		for j := range txids {
+			txids[j] = make([]byte, 32)
+			r.Read(txids[j])
+		}
+		root := bitcoinRoot(txids)
+
+		tree, err := merkle.New(txids, merkle.WithScheme(merkle.SchemeBitcoin))
+		require.NoError(t, err)
+		require.Equal(t, root, tree.Root(), "%d txids", len(txids))
+
+		matches := make([]bool, len(txids))
+		matches[r.Intn(len(txids))] = true
+		p, err := merkle.NewPartialMerkleTree(txids, matches)
+		require.NoError(t, err)
+		got, _, _, err := p.ExtractMatches()
+		require.NoError(t, err)
+		require.Equal(t, root, got)
+	}
+}
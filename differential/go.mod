@@ -0,0 +1,19 @@
+module github.com/chakra-guy/merkle/differential
+
+go 1.21.6
+
+require (
+	github.com/cbergoon/merkletree v0.2.0
+	github.com/chakra-guy/merkle v0.0.0
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.31.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/chakra-guy/merkle => ../
@@ -0,0 +1,82 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidFileName = errors.New("file names with newlines are not supported")
+
+// DirhashLeaf encodes a file as the line that the Hash1 algorithm of
+// golang.org/x/mod/sumdb/dirhash hashes for it: the hex SHA-256 of its
+// contents, two spaces, its slash-separated name and a newline
+func DirhashLeaf(name string, content []byte) ([]byte, error) {
+	if strings.Contains(name, "\n") {
+		return nil, ErrInvalidFileName
+	}
+	return fmt.Appendf(nil, "%x  %s\n", sha256.Sum256(content), name), nil
+}
+
+// NewFromDir creates a Merkle tree with a DirhashLeaf for every file below
+// dir, named and ordered as dirhash.HashDir does with the given prefix (such
+// as "module@version"), and returns the names in leaf order
+func NewFromDir(dir, prefix string, opts ...Option) (*MerkleTree, []string, error) {
+	dir = filepath.Clean(dir)
+	files := map[string]string{}
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(filepath.Join(prefix, rel))] = file
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	data := make([][]byte, len(names))
+	for i, name := range names {
+		content, err := os.ReadFile(files[name])
+		if err != nil {
+			return nil, nil, err
+		}
+		if data[i], err = DirhashLeaf(name, content); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	tree, err := New(data, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tree, names, nil
+}
+
+// DirhashSummary returns the "h1:" dirhash of a tree built from DirhashLeaf
+// leaves, as recorded in go.sum for the same files
+func (m *MerkleTree) DirhashSummary() (string, error) {
+	h := sha256.New()
+	for _, leaf := range m.leafs {
+		if leaf.data == nil {
+			return "", ErrDataNotStored
+		}
+		h.Write(leaf.data)
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,55 @@
+package merkle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewFromDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/m\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "m.go"), []byte("package m\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("hi\n"), 0o644))
+
+	tree, names, err := NewFromDir(dir, "example.com/m@v1.0.0")
+	require.NoError(t, err)
+
+	t.Run("should name and order files as dirhash does", func(t *testing.T) {
+		require.Equal(t, []string{
+			"example.com/m@v1.0.0/README",
+			"example.com/m@v1.0.0/go.mod",
+			"example.com/m@v1.0.0/sub/m.go",
+		}, names)
+	})
+
+	t.Run("should match the dirhash h1 summary", func(t *testing.T) {
+		// computed with dirhash.HashDir(dir, "example.com/m@v1.0.0", dirhash.Hash1)
+		summary, err := tree.DirhashSummary()
+		require.NoError(t, err)
+		require.Equal(t, "h1:O5+XYe1jXoeoB/zdHfQLFd7P7XkvgCJLvdvarzK6qHA=", summary)
+	})
+
+	t.Run("should prove a single file", func(t *testing.T) {
+		leaf, err := DirhashLeaf("example.com/m@v1.0.0/go.mod", []byte("module example.com/m\n"))
+		require.NoError(t, err)
+		proof, err := tree.GenerateProofAt(1)
+		require.NoError(t, err)
+		require.True(t, tree.VerifyData(leaf, proof))
+	})
+
+	t.Run("should return error for file names with newlines", func(t *testing.T) {
+		_, err := DirhashLeaf("a\nb", nil)
+		require.ErrorIs(t, err, ErrInvalidFileName)
+	})
+
+	t.Run("should return error for summaries without stored data", func(t *testing.T) {
+		tree, _, err := NewFromDir(dir, "", WithoutStoringData())
+		require.NoError(t, err)
+		_, err = tree.DirhashSummary()
+		require.ErrorIs(t, err, ErrDataNotStored)
+	})
+}
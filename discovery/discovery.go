@@ -0,0 +1,127 @@
+// Package discovery announces tree roots to a pluggable discovery layer, such
+// as a libp2p DHT, and finds the peers serving proofs for a root.
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/chakra-guy/merkle"
+)
+
+var (
+	ErrNotAnnounced = errors.New("root has not been announced")
+	ErrBadMetadata  = errors.New("announced metadata does not match the root")
+)
+
+const keyPrefix = "merkle-root/v1\x00"
+
+// Peer is a peer serving proofs for an announced root
+type Peer struct {
+	ID    string
+	Addrs []string
+}
+
+// Router is the discovery layer. Keys are SHA-256 digests spread uniformly
+// over the key space, so they map directly onto a DHT such as libp2p's
+// Kademlia, wrapped as a raw CID:
+//
+//	mh, _ := multihash.Encode(key, multihash.SHA2_256)
+//	c := cid.NewCidV1(cid.Raw, mh)
+//	dht.Provide(ctx, c, true)
+type Router interface {
+	// Provide announces this peer as a provider of the key
+	Provide(ctx context.Context, key []byte) error
+	// FindProviders returns up to limit providers of the key
+	FindProviders(ctx context.Context, key []byte, limit int) ([]Peer, error)
+	// PutValue stores a value under the key
+	PutValue(ctx context.Context, key, value []byte) error
+	// GetValue returns the value stored under the key
+	GetValue(ctx context.Context, key []byte) ([]byte, error)
+}
+
+// Metadata describes an announced tree
+type Metadata struct {
+	Root   []byte `json:"root"`
+	Size   int    `json:"size"`
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// Key returns the discovery key of a root
+func Key(root []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(keyPrefix))
+	h.Write(root)
+	return h.Sum(nil)
+}
+
+// metadataKey returns the key the metadata of a root is stored under
+func metadataKey(root []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(keyPrefix + "metadata\x00"))
+	h.Write(root)
+	return h.Sum(nil)
+}
+
+// Announcer announces roots and finds their providers through a router
+type Announcer struct {
+	r Router
+}
+
+// NewAnnouncer creates an announcer over the router
+func NewAnnouncer(r Router) *Announcer {
+	return &Announcer{r: r}
+}
+
+// Announce stores the metadata of the tree and announces this peer as a
+// provider of proofs for its root; scheme names the tree's hashing scheme
+func (a *Announcer) Announce(ctx context.Context, tree *merkle.MerkleTree, scheme string) (Metadata, error) {
+	meta := Metadata{Root: tree.Root(), Size: len(tree.Leaves()), Scheme: scheme}
+	value, err := json.Marshal(meta)
+	if err != nil {
+		return Metadata{}, err
+	}
+	if err := a.r.PutValue(ctx, metadataKey(meta.Root), value); err != nil {
+		return Metadata{}, err
+	}
+	if err := a.r.Provide(ctx, Key(meta.Root)); err != nil {
+		return Metadata{}, err
+	}
+	return meta, nil
+}
+
+// Lookup returns the announced metadata of a root
+func (a *Announcer) Lookup(ctx context.Context, root []byte) (Metadata, error) {
+	value, err := a.r.GetValue(ctx, metadataKey(root))
+	if err != nil {
+		return Metadata{}, err
+	}
+	if value == nil {
+		return Metadata{}, ErrNotAnnounced
+	}
+
+	var meta Metadata
+	if err := json.Unmarshal(value, &meta); err != nil {
+		return Metadata{}, fmt.Errorf("%w: %v", ErrBadMetadata, err)
+	}
+	if !bytes.Equal(meta.Root, root) {
+		return Metadata{}, ErrBadMetadata
+	}
+	return meta, nil
+}
+
+// FindPeers returns up to limit peers serving proofs for the root
+func (a *Announcer) FindPeers(ctx context.Context, root []byte, limit int) ([]Peer, error) {
+	peers, err := a.r.FindProviders(ctx, Key(root), limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(peers) == 0 {
+		return nil, ErrNotAnnounced
+	}
+	return peers, nil
+}
@@ -0,0 +1,95 @@
+package discovery
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRouter is an in-process router shared by several peers
+type memoryRouter struct {
+	providers map[string][]Peer
+	values    map[string][]byte
+}
+
+func newMemoryRouter() *memoryRouter {
+	return &memoryRouter{providers: map[string][]Peer{}, values: map[string][]byte{}}
+}
+
+// peerRouter is the view of the shared router from one peer
+type peerRouter struct {
+	*memoryRouter
+	self Peer
+}
+
+func (r peerRouter) Provide(ctx context.Context, key []byte) error {
+	r.providers[string(key)] = append(r.providers[string(key)], r.self)
+	return nil
+}
+
+func (r peerRouter) FindProviders(ctx context.Context, key []byte, limit int) ([]Peer, error) {
+	peers := r.providers[string(key)]
+	if len(peers) > limit {
+		peers = peers[:limit]
+	}
+	return peers, nil
+}
+
+func (r peerRouter) PutValue(ctx context.Context, key, value []byte) error {
+	r.values[string(key)] = value
+	return nil
+}
+
+func (r peerRouter) GetValue(ctx context.Context, key []byte) ([]byte, error) {
+	return r.values[string(key)], nil
+}
+
+func Test_Key(t *testing.T) {
+	t.Run("should map roots to distinct digests", func(t *testing.T) {
+		a, b := Key([]byte("a")), Key([]byte("b"))
+		require.Len(t, a, sha256.Size)
+		require.NotEqual(t, a, b)
+		require.Equal(t, a, Key([]byte("a")))
+		require.NotEqual(t, a, metadataKey([]byte("a")))
+	})
+}
+
+func Test_Announcer(t *testing.T) {
+	ctx := context.Background()
+	tree, err := merkle.New([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	require.NoError(t, err)
+
+	shared := newMemoryRouter()
+	alice := NewAnnouncer(peerRouter{shared, Peer{ID: "alice", Addrs: []string{"/ip4/10.0.0.1/tcp/4001"}}})
+	bob := NewAnnouncer(peerRouter{shared, Peer{ID: "bob"}})
+
+	t.Run("should announce a root and find its providers", func(t *testing.T) {
+		meta, err := alice.Announce(ctx, tree, "sha256")
+		require.NoError(t, err)
+		require.Equal(t, Metadata{Root: tree.Root(), Size: 3, Scheme: "sha256"}, meta)
+
+		got, err := bob.Lookup(ctx, tree.Root())
+		require.NoError(t, err)
+		require.Equal(t, meta, got)
+
+		peers, err := bob.FindPeers(ctx, tree.Root(), 10)
+		require.NoError(t, err)
+		require.Equal(t, []Peer{{ID: "alice", Addrs: []string{"/ip4/10.0.0.1/tcp/4001"}}}, peers)
+	})
+
+	t.Run("should return error for an unannounced root", func(t *testing.T) {
+		_, err := bob.Lookup(ctx, []byte("unknown"))
+		require.ErrorIs(t, err, ErrNotAnnounced)
+		_, err = bob.FindPeers(ctx, []byte("unknown"), 10)
+		require.ErrorIs(t, err, ErrNotAnnounced)
+	})
+
+	t.Run("should reject metadata announced for another root", func(t *testing.T) {
+		shared.values[string(metadataKey([]byte("forged")))] = shared.values[string(metadataKey(tree.Root()))]
+		_, err := bob.Lookup(ctx, []byte("forged"))
+		require.ErrorIs(t, err, ErrBadMetadata)
+	})
+}
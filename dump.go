@@ -0,0 +1,115 @@
+package merkle
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidDump = errors.New("invalid tree dump")
+
+const dumpHeader = "merkle dump v1"
+
+// TreeDump is a snapshot of every node hash of a tree, level by level from the
+// leaves up, with the tree's HashAlgorithm if it is recognized. Its text form
+// is stable, so it works as a golden file:
+//
+//	merkle dump v1
+//	hash: sha256
+//	level 0: 3
+//	<hex hash of leaf 0>
+//	<hex hash of leaf 1>
+//	<hex hash of leaf 2>
+//	level 1: 2
+//	...
+//	level 2: 1
+//	<hex root>
+type TreeDump struct {
+	HashAlgorithm string
+	Levels        [][][]byte
+}
+
+// Dump returns a snapshot of the tree's node hashes
+func (m *MerkleTree) Dump() *TreeDump {
+	d := &TreeDump{HashAlgorithm: m.HashAlgorithm(), Levels: make([][][]byte, len(m.levels))}
+	for k, level := range m.levels {
+		d.Levels[k] = make([][]byte, len(level))
+		for j, node := range level {
+			d.Levels[k][j] = node.hash
+		}
+	}
+	return d
+}
+
+// Root returns the root hash of the dumped tree
+func (d *TreeDump) Root() []byte {
+	return d.Levels[len(d.Levels)-1][0]
+}
+
+// MarshalText encodes the dump in its canonical text form
+func (d *TreeDump) MarshalText() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(dumpHeader + "\n")
+	if d.HashAlgorithm != "" {
+		fmt.Fprintf(&b, "hash: %s\n", d.HashAlgorithm)
+	}
+	for k, level := range d.Levels {
+		fmt.Fprintf(&b, "level %d: %d\n", k, len(level))
+		for _, hash := range level {
+			b.WriteString(hex.EncodeToString(hash) + "\n")
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalText parses a dump in its canonical text form, checking that every
+// level halves the one below it and that the last level holds a single root
+func (d *TreeDump) UnmarshalText(text []byte) error {
+	s := bufio.NewScanner(bytes.NewReader(text))
+	s.Buffer(nil, 1<<20)
+	if !s.Scan() || s.Text() != dumpHeader {
+		return fmt.Errorf("%w: missing header", ErrInvalidDump)
+	}
+
+	var alg string
+	var levels [][][]byte
+	for s.Scan() {
+		if name, ok := strings.CutPrefix(s.Text(), "hash: "); ok && alg == "" && levels == nil {
+			alg = name
+			continue
+		}
+		var k, count int
+		if _, err := fmt.Sscanf(s.Text(), "level %d: %d", &k, &count); err != nil || k != len(levels) || count < 1 {
+			return fmt.Errorf("%w: bad level line %q", ErrInvalidDump, s.Text())
+		}
+		if k > 0 && count != (len(levels[k-1])+1)/2 {
+			return fmt.Errorf("%w: level %d has %d nodes", ErrInvalidDump, k, count)
+		}
+
+		// the count is untrusted, so the level grows as its lines are read
+		var level [][]byte
+		for j := 0; j < count; j++ {
+			if !s.Scan() {
+				return fmt.Errorf("%w: level %d is truncated", ErrInvalidDump, k)
+			}
+			hash, err := hex.DecodeString(strings.TrimSpace(s.Text()))
+			if err != nil {
+				return fmt.Errorf("%w: level %d node %d: %v", ErrInvalidDump, k, j, err)
+			}
+			level = append(level, hash)
+		}
+		levels = append(levels, level)
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if len(levels) == 0 || len(levels[len(levels)-1]) != 1 {
+		return fmt.Errorf("%w: no root level", ErrInvalidDump)
+	}
+
+	d.HashAlgorithm, d.Levels = alg, levels
+	return nil
+}
@@ -0,0 +1,58 @@
+package merkle
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var updateDump = flag.Bool("update", false, "rewrite the golden tree dump")
+
+const dumpGoldenPath = "testdata/tree.dump"
+
+func Test_Dump(t *testing.T) {
+	var data [][]byte
+	for i := 0; i < 5; i++ {
+		data = append(data, []byte(fmt.Sprint(i)))
+	}
+	tree, err := New(data)
+	require.NoError(t, err)
+
+	text, err := tree.Dump().MarshalText()
+	require.NoError(t, err)
+	if *updateDump {
+		require.NoError(t, os.WriteFile(dumpGoldenPath, text, 0o644))
+	}
+
+	t.Run("should match the golden dump", func(t *testing.T) {
+		golden, err := os.ReadFile(dumpGoldenPath)
+		require.NoError(t, err)
+		require.Equal(t, string(golden), string(text))
+	})
+
+	t.Run("should parse its own output", func(t *testing.T) {
+		var d TreeDump
+		require.NoError(t, d.UnmarshalText(text))
+		require.Equal(t, tree.Dump(), &d)
+		require.Equal(t, tree.Root(), d.Root())
+	})
+
+	t.Run("should reject malformed dumps", func(t *testing.T) {
+		for _, text := range []string{
+			"",
+			"merkle dump v2\n",
+			"merkle dump v1\nlevel 1: 1\n00\n",
+			"merkle dump v1\nlevel 0: 2\n00\n",
+			"merkle dump v1\nlevel 0: 2\n00\n01\n",
+			"merkle dump v1\nlevel 0: 2\n00\n01\nlevel 1: 2\n00\n01\n",
+			"merkle dump v1\nlevel 0: 1\nzz\n",
+			"merkle dump v1\nlevel 0: 9000000000000000000\n00\n",
+		} {
+			var d TreeDump
+			require.ErrorIs(t, d.UnmarshalText([]byte(text)), ErrInvalidDump, text)
+		}
+	})
+}
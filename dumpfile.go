@@ -0,0 +1,141 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ProveFromFile generates the proof of the leaf at index from a tree dump
+// saved with TreeDump.MarshalText, without loading the whole tree: hash lines
+// have a fixed width, so it reads the header and then only the O(log n) node
+// records on the path, which suits cold-start proof servers
+func ProveFromFile(path string, index int) (Proof, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return proveFromDump(f, index)
+}
+
+// dumpLayout locates the node records of a dump
+type dumpLayout struct {
+	r      io.ReaderAt
+	width  int64   // length of a hash line, with its newline
+	starts []int64 // offset of the first hash line of each level
+	counts []int
+}
+
+// proveFromDump generates a proof from a dump read at the offsets of its records
+func proveFromDump(r io.ReaderAt, index int) (Proof, error) {
+	d, err := readDumpLayout(r)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= d.counts[0] {
+		return nil, ErrOutOfRange
+	}
+
+	var proof Proof
+	for k := 0; k < len(d.counts)-1; k++ {
+		sibling, side := index^1, Side(index&1^1)
+		if sibling >= d.counts[k] {
+			// an unpaired node is either promoted as its own parent or
+			// paired with itself
+			node, err := d.hash(k, index)
+			if err != nil {
+				return nil, err
+			}
+			parent, err := d.hash(k+1, index>>1)
+			if err != nil {
+				return nil, err
+			}
+			if !bytes.Equal(node, parent) {
+				proof = append(proof, ProofElement{Hash: node, Side: side})
+			}
+		} else {
+			hash, err := d.hash(k, sibling)
+			if err != nil {
+				return nil, err
+			}
+			proof = append(proof, ProofElement{Hash: hash, Side: side})
+		}
+		index >>= 1
+	}
+	return proof, nil
+}
+
+// readDumpLayout reads the header, the leaf level line and the first hash line
+// of a dump and derives the offset of every level from them
+func readDumpLayout(r io.ReaderAt) (*dumpLayout, error) {
+	head := make([]byte, 512)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	lines := strings.SplitAfterN(string(head[:n]), "\n", 5)
+	if len(lines) < 3 || lines[0] != dumpHeader+"\n" {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidDump)
+	}
+	offset := int64(len(lines[0]))
+	if strings.HasPrefix(lines[1], "hash: ") {
+		offset += int64(len(lines[1]))
+		lines = lines[1:]
+	}
+
+	var leaves int
+	if _, err := fmt.Sscanf(lines[1], "level 0: %d\n", &leaves); err != nil || leaves < 1 {
+		return nil, fmt.Errorf("%w: bad level line %q", ErrInvalidDump, strings.TrimSpace(lines[1]))
+	}
+	offset += int64(len(lines[1]))
+	width := -1
+	if len(lines) > 2 {
+		width = strings.IndexByte(lines[2], '\n') + 1
+	}
+	if width < 3 || width%2 == 0 {
+		return nil, fmt.Errorf("%w: bad hash line", ErrInvalidDump)
+	}
+
+	d := &dumpLayout{r: r, width: int64(width)}
+	for count := leaves; ; count = (count + 1) / 2 {
+		if k := len(d.counts); k > 0 {
+			offset += int64(d.counts[k-1])*d.width + int64(len(levelLine(k, count)))
+		}
+		d.starts, d.counts = append(d.starts, offset), append(d.counts, count)
+		if count == 1 {
+			return d, nil
+		}
+	}
+}
+
+// levelLine is the line introducing level k of a dump
+func levelLine(k, count int) string {
+	return fmt.Sprintf("level %d: %d\n", k, count)
+}
+
+// hash reads the hash of the node at level k and index j, checking the level
+// line before it
+func (d *dumpLayout) hash(k, j int) ([]byte, error) {
+	line := levelLine(k, d.counts[k])
+	check := make([]byte, len(line))
+	if _, err := d.r.ReadAt(check, d.starts[k]-int64(len(line))); err != nil || string(check) != line {
+		return nil, fmt.Errorf("%w: level %d is not where expected", ErrInvalidDump, k)
+	}
+
+	record := make([]byte, d.width)
+	if _, err := d.r.ReadAt(record, d.starts[k]+int64(j)*d.width); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if record[len(record)-1] != '\n' {
+		return nil, fmt.Errorf("%w: level %d node %d has the wrong width", ErrInvalidDump, k, j)
+	}
+	hash, err := hex.DecodeString(string(record[:len(record)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: level %d node %d: %v", ErrInvalidDump, k, j, err)
+	}
+	return hash, nil
+}
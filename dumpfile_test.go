@@ -0,0 +1,100 @@
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingReaderAt counts the reads made through it
+type countingReaderAt struct {
+	r     io.ReaderAt
+	reads int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	c.reads++
+	return c.r.ReadAt(p, off)
+}
+
+func Test_ProveFromFile(t *testing.T) {
+	t.Run("should match the proofs of the loaded tree", func(t *testing.T) {
+		for _, opts := range [][]Option{nil, {WithOddNodePromotion()}} {
+			for n := 1; n <= 9; n++ {
+				var data [][]byte
+				for i := 0; i < n; i++ {
+					data = append(data, []byte(fmt.Sprint(i)))
+				}
+				tree, err := New(data, opts...)
+				require.NoError(t, err)
+
+				text, err := tree.Dump().MarshalText()
+				require.NoError(t, err)
+				path := filepath.Join(t.TempDir(), "tree.dump")
+				require.NoError(t, os.WriteFile(path, text, 0o644))
+
+				for i := 0; i < n; i++ {
+					want, err := tree.GenerateProofAt(i)
+					require.NoError(t, err)
+					got, err := ProveFromFile(path, i)
+					require.NoError(t, err)
+					require.Equal(t, want, got, "n=%d i=%d", n, i)
+				}
+			}
+		}
+	})
+
+	t.Run("should read only the records on the path", func(t *testing.T) {
+		var data [][]byte
+		for i := 0; i < 1000; i++ {
+			data = append(data, []byte(fmt.Sprint(i)))
+		}
+		tree, err := New(data)
+		require.NoError(t, err)
+		text, err := tree.Dump().MarshalText()
+		require.NoError(t, err)
+
+		r := &countingReaderAt{r: bytes.NewReader(text)}
+		proof, err := proveFromDump(r, 777)
+		require.NoError(t, err)
+		require.True(t, tree.VerifyData([]byte("777"), proof))
+		require.LessOrEqual(t, r.reads, 1+4*len(proof))
+	})
+
+	t.Run("should reject out of range indices", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a"), []byte("b")})
+		require.NoError(t, err)
+		text, err := tree.Dump().MarshalText()
+		require.NoError(t, err)
+		for _, index := range []int{-1, 2} {
+			_, err := proveFromDump(bytes.NewReader(text), index)
+			require.ErrorIs(t, err, ErrOutOfRange)
+		}
+	})
+
+	t.Run("should reject malformed dumps", func(t *testing.T) {
+		for _, text := range []string{
+			"",
+			"not a dump\n",
+			"merkle dump v1\nlevel 0: 2\nzz\n",
+			"merkle dump v1\nlevel 0: 3\n" + mockHex("a") + "\n" + mockHex("b") + "\n" + mockHex("c") + "\nlevel 9: 2\n" + mockHex("ab") + "\n" + mockHex("cc") + "\n",
+		} {
+			_, err := proveFromDump(bytes.NewReader([]byte(text)), 0)
+			require.ErrorIs(t, err, ErrInvalidDump, text)
+		}
+	})
+
+	t.Run("should fail for missing files", func(t *testing.T) {
+		_, err := ProveFromFile(filepath.Join(t.TempDir(), "missing.dump"), 0)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func mockHex(s string) string {
+	return fmt.Sprintf("%x", []byte(s))
+}
@@ -0,0 +1,152 @@
+package merkle
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var ErrInvalidTypedValue = errors.New("invalid EIP-712 typed value")
+
+const (
+	typedWordSize = 32
+	addressSize   = 20
+)
+
+// WithKeccak256 sets Keccak-256 as the hash function, as used by Solidity verifiers
+func WithKeccak256() Option {
+	return func(m *MerkleTree) {
+		m.hashFn = sha3.NewLegacyKeccak256
+	}
+}
+
+// TypedDomain is an EIP-712 domain; unset fields are left out of the domain type
+type TypedDomain struct {
+	Name              string
+	Version           string
+	ChainID           *big.Int
+	VerifyingContract []byte
+	Salt              []byte
+}
+
+// Separator computes the EIP-712 domain separator
+func (d TypedDomain) Separator() ([]byte, error) {
+	var types []string
+	var fields [][]byte
+
+	if d.Name != "" {
+		types = append(types, "string name")
+		fields = append(fields, TypedString(d.Name))
+	}
+	if d.Version != "" {
+		types = append(types, "string version")
+		fields = append(fields, TypedString(d.Version))
+	}
+	if d.ChainID != nil {
+		word, err := TypedUint(d.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, "uint256 chainId")
+		fields = append(fields, word)
+	}
+	if d.VerifyingContract != nil {
+		word, err := TypedAddress(d.VerifyingContract)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, "address verifyingContract")
+		fields = append(fields, word)
+	}
+	if d.Salt != nil {
+		word, err := TypedBytes32(d.Salt)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, "bytes32 salt")
+		fields = append(fields, word)
+	}
+
+	return HashStruct("EIP712Domain("+strings.Join(types, ",")+")", fields...)
+}
+
+// TypeHash computes the hash of an EIP-712 encoded type, such as
+// "Mail(Person from,Person to,string contents)Person(string name,address wallet)"
+func TypeHash(encodedType string) []byte {
+	return sum(sha3.NewLegacyKeccak256, []byte(encodedType))
+}
+
+// HashStruct computes the EIP-712 hashStruct of a value from its encoded type
+// and its fields, each already encoded as a 32-byte word
+func HashStruct(encodedType string, fields ...[]byte) ([]byte, error) {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(TypeHash(encodedType))
+	for _, field := range fields {
+		if len(field) != typedWordSize {
+			return nil, ErrInvalidTypedValue
+		}
+		h.Write(field)
+	}
+	return h.Sum(nil), nil
+}
+
+// TypedLeaf computes the EIP-712 digest of a struct under a domain, for use as
+// leaf data that wallets can sign and Solidity verifiers can recompute
+func TypedLeaf(domain TypedDomain, encodedType string, fields ...[]byte) ([]byte, error) {
+	separator, err := domain.Separator()
+	if err != nil {
+		return nil, err
+	}
+	structHash, err := HashStruct(encodedType, fields...)
+	if err != nil {
+		return nil, err
+	}
+	return sum(sha3.NewLegacyKeccak256, []byte{0x19, 0x01}, separator, structHash), nil
+}
+
+// TypedUint encodes an unsigned integer as a uint256 word, as for amounts
+func TypedUint(v *big.Int) ([]byte, error) {
+	if v == nil || v.Sign() < 0 || v.BitLen() > typedWordSize*8 {
+		return nil, ErrInvalidTypedValue
+	}
+	return v.FillBytes(make([]byte, typedWordSize)), nil
+}
+
+// TypedAddress encodes a 20-byte address as a left-padded word
+func TypedAddress(addr []byte) ([]byte, error) {
+	if len(addr) != addressSize {
+		return nil, ErrInvalidTypedValue
+	}
+	word := make([]byte, typedWordSize)
+	copy(word[typedWordSize-addressSize:], addr)
+	return word, nil
+}
+
+// TypedBool encodes a boolean as a word
+func TypedBool(v bool) []byte {
+	word := make([]byte, typedWordSize)
+	if v {
+		word[typedWordSize-1] = 1
+	}
+	return word
+}
+
+// TypedBytes32 encodes a bytes32 value
+func TypedBytes32(b []byte) ([]byte, error) {
+	if len(b) != typedWordSize {
+		return nil, ErrInvalidTypedValue
+	}
+	return append([]byte{}, b...), nil
+}
+
+// TypedString encodes a dynamic string as the hash of its contents
+func TypedString(s string) []byte {
+	return sum(sha3.NewLegacyKeccak256, []byte(s))
+}
+
+// TypedBytes encodes a dynamic byte array as the hash of its contents
+func TypedBytes(b []byte) []byte {
+	return sum(sha3.NewLegacyKeccak256, b)
+}
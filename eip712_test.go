@@ -0,0 +1,84 @@
+package merkle
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TypedLeaf(t *testing.T) {
+	// the Mail example from the EIP-712 specification
+	domain := TypedDomain{
+		Name:              "Ether Mail",
+		Version:           "1",
+		ChainID:           big.NewInt(1),
+		VerifyingContract: mustHex(t, "cccccccccccccccccccccccccccccccccccccccc"),
+	}
+	person := func(name, wallet string) []byte {
+		addr, err := TypedAddress(mustHex(t, wallet))
+		require.NoError(t, err)
+		h, err := HashStruct("Person(string name,address wallet)", TypedString(name), addr)
+		require.NoError(t, err)
+		return h
+	}
+	mailType := "Mail(Person from,Person to,string contents)Person(string name,address wallet)"
+	from := person("Cow", "cd2a3d9f938e13cd947ec05abc7fe734df8dd826")
+	to := person("Bob", "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+
+	t.Run("should compute the domain separator", func(t *testing.T) {
+		separator, err := domain.Separator()
+		require.NoError(t, err)
+		require.Equal(t, "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f", hex.EncodeToString(separator))
+	})
+
+	t.Run("should compute the struct hash", func(t *testing.T) {
+		h, err := HashStruct(mailType, from, to, TypedString("Hello, Bob!"))
+		require.NoError(t, err)
+		require.Equal(t, "c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e", hex.EncodeToString(h))
+	})
+
+	t.Run("should compute the typed data digest", func(t *testing.T) {
+		leaf, err := TypedLeaf(domain, mailType, from, to, TypedString("Hello, Bob!"))
+		require.NoError(t, err)
+		require.Equal(t, "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", hex.EncodeToString(leaf))
+	})
+
+	t.Run("should return error for fields that are not words", func(t *testing.T) {
+		_, err := HashStruct(mailType, []byte("short"))
+		require.ErrorIs(t, err, ErrInvalidTypedValue)
+	})
+}
+
+func Test_TypedUint(t *testing.T) {
+	t.Run("should left-pad to a word", func(t *testing.T) {
+		word, err := TypedUint(big.NewInt(258))
+		require.NoError(t, err)
+		require.Len(t, word, 32)
+		require.Equal(t, []byte{1, 2}, word[30:])
+	})
+
+	t.Run("should return error for values that do not fit", func(t *testing.T) {
+		_, err := TypedUint(big.NewInt(-1))
+		require.ErrorIs(t, err, ErrInvalidTypedValue)
+		_, err = TypedUint(new(big.Int).Lsh(big.NewInt(1), 256))
+		require.ErrorIs(t, err, ErrInvalidTypedValue)
+		_, err = TypedUint(nil)
+		require.ErrorIs(t, err, ErrInvalidTypedValue)
+	})
+}
+
+func Test_WithKeccak256(t *testing.T) {
+	t.Run("should hash with keccak-256", func(t *testing.T) {
+		tree, err := New([][]byte{{}}, WithKeccak256())
+		require.NoError(t, err)
+		require.Equal(t, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470", hex.EncodeToString(tree.Root()))
+	})
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
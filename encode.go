@@ -0,0 +1,77 @@
+package merkle
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/big"
+)
+
+var ErrInvalidEncoding = errors.New("value cannot be encoded at a fixed width")
+
+// UUIDSize is the width in bytes of an encoded UUID
+const UUIDSize = 16
+
+// EncodeUint64 encodes an unsigned integer as 8 big-endian bytes
+func EncodeUint64(v uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, v)
+}
+
+// EncodeInt64 encodes a signed integer as 8 big-endian bytes in two's complement
+func EncodeInt64(v int64) []byte {
+	return EncodeUint64(uint64(v))
+}
+
+// EncodeAmount encodes a non-negative amount as a left-padded 32-byte
+// big-endian word, the same word as TypedUint
+func EncodeAmount(v *big.Int) ([]byte, error) {
+	b, err := TypedUint(v)
+	if err != nil {
+		return nil, ErrInvalidEncoding
+	}
+	return b, nil
+}
+
+// EncodeAddress encodes a 20-byte address given as a hex string with an
+// optional 0x prefix
+func EncodeAddress(addr string) ([]byte, error) {
+	if len(addr) >= 2 && addr[0] == '0' && (addr[1] == 'x' || addr[1] == 'X') {
+		addr = addr[2:]
+	}
+	b, err := hex.DecodeString(addr)
+	if err != nil || len(b) != addressSize {
+		return nil, ErrInvalidEncoding
+	}
+	return b, nil
+}
+
+// EncodeUUID encodes a UUID in its canonical textual form, with or without
+// hyphens and in either case, as its 16 raw bytes
+func EncodeUUID(uuid string) ([]byte, error) {
+	s := uuid
+	if len(s) == 36 {
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return nil, ErrInvalidEncoding
+		}
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != UUIDSize {
+		return nil, ErrInvalidEncoding
+	}
+	return b, nil
+}
+
+// EncodeRecord concatenates fixed-width fields into leaf data; because every
+// field has a fixed width, the result is unambiguous for a given schema
+func EncodeRecord(fields ...[]byte) []byte {
+	size := 0
+	for _, f := range fields {
+		size += len(f)
+	}
+	record := make([]byte, 0, size)
+	for _, f := range fields {
+		record = append(record, f...)
+	}
+	return record
+}
@@ -0,0 +1,80 @@
+package merkle
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EncodeUint64(t *testing.T) {
+	t.Run("should encode big-endian at a fixed width", func(t *testing.T) {
+		require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 1, 2}, EncodeUint64(258))
+		require.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, EncodeInt64(-1))
+	})
+}
+
+func Test_EncodeAmount(t *testing.T) {
+	t.Run("should left-pad to a word", func(t *testing.T) {
+		b, err := EncodeAmount(big.NewInt(258))
+		require.NoError(t, err)
+		require.Len(t, b, 32)
+		require.Equal(t, []byte{1, 2}, b[30:])
+	})
+
+	t.Run("should return error for amounts that do not fit", func(t *testing.T) {
+		_, err := EncodeAmount(big.NewInt(-1))
+		require.ErrorIs(t, err, ErrInvalidEncoding)
+		_, err = EncodeAmount(new(big.Int).Lsh(big.NewInt(1), 256))
+		require.ErrorIs(t, err, ErrInvalidEncoding)
+		_, err = EncodeAmount(nil)
+		require.ErrorIs(t, err, ErrInvalidEncoding)
+	})
+}
+
+func Test_EncodeAddress(t *testing.T) {
+	t.Run("should decode prefixed and bare hex alike", func(t *testing.T) {
+		a, err := EncodeAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+		require.NoError(t, err)
+		b, err := EncodeAddress("cd2a3d9f938e13cd947ec05abc7fe734df8dd826")
+		require.NoError(t, err)
+		require.Len(t, a, 20)
+		require.Equal(t, a, b)
+	})
+
+	t.Run("should return error for wrong lengths", func(t *testing.T) {
+		_, err := EncodeAddress("0xcd2a")
+		require.ErrorIs(t, err, ErrInvalidEncoding)
+	})
+
+	t.Run("should strip a single prefix", func(t *testing.T) {
+		_, err := EncodeAddress("0x0XCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+		require.ErrorIs(t, err, ErrInvalidEncoding)
+		_, err = EncodeAddress("0XCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+		require.NoError(t, err)
+	})
+}
+
+func Test_EncodeUUID(t *testing.T) {
+	t.Run("should encode canonical and compact forms alike", func(t *testing.T) {
+		a, err := EncodeUUID("123E4567-e89b-12d3-a456-426614174000")
+		require.NoError(t, err)
+		b, err := EncodeUUID("123e4567e89b12d3a456426614174000")
+		require.NoError(t, err)
+		require.Len(t, a, UUIDSize)
+		require.Equal(t, a, b)
+	})
+
+	t.Run("should return error for malformed UUIDs", func(t *testing.T) {
+		_, err := EncodeUUID("123e4567+e89b-12d3-a456-426614174000")
+		require.ErrorIs(t, err, ErrInvalidEncoding)
+		_, err = EncodeUUID("123e4567")
+		require.ErrorIs(t, err, ErrInvalidEncoding)
+	})
+}
+
+func Test_EncodeRecord(t *testing.T) {
+	t.Run("should concatenate fields", func(t *testing.T) {
+		require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 1, 0xab}, EncodeRecord(EncodeUint64(1), []byte{0xab}))
+	})
+}
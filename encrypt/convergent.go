@@ -0,0 +1,90 @@
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/chakra-guy/merkle"
+)
+
+var ErrKeyMismatch = errors.New("decrypted leaf does not match its convergent key")
+
+// Convergent encrypts leaves under keys derived from their own content, so
+// equal leaves encrypt to equal ciphertexts and still deduplicate, while the
+// tree only commits to ciphertexts. The secret scopes deduplication: without
+// one, anyone who can guess a leaf can confirm it is in the tree.
+type Convergent struct {
+	secret []byte
+}
+
+// NewConvergent creates a convergent encrypter keyed with a secret shared by
+// the writers that should deduplicate against each other; it may be nil
+func NewConvergent(secret []byte) *Convergent {
+	return &Convergent{secret: secret}
+}
+
+// Key derives the content key of a leaf
+func (c *Convergent) Key(data []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// Encrypt encrypts a leaf with AES-256-GCM under its content key, returning
+// the ciphertext and the key needed to decrypt it. Each key encrypts a single
+// plaintext, so a fixed nonce is safe.
+func (c *Convergent) Encrypt(data []byte) (ciphertext, key []byte, err error) {
+	key = c.Key(data)
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead.Seal(nil, make([]byte, aead.NonceSize()), data, nil), key, nil
+}
+
+// Decrypt decrypts a leaf encrypted by Encrypt and checks that it matches the key
+func (c *Convergent) Decrypt(key, ciphertext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(c.Key(data), key) {
+		return nil, ErrKeyMismatch
+	}
+	return data, nil
+}
+
+// NewTree encrypts every leaf and builds a tree over the ciphertexts, returning
+// the content key of each leaf in order
+func (c *Convergent) NewTree(leaves [][]byte, opts ...merkle.Option) (*merkle.MerkleTree, [][]byte, error) {
+	ciphertexts := make([][]byte, len(leaves))
+	keys := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		var err error
+		if ciphertexts[i], keys[i], err = c.Encrypt(leaf); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	tree, err := merkle.New(ciphertexts, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tree, keys, nil
+}
+
+// newAEAD creates an AES-256-GCM cipher
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
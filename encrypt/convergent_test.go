@@ -0,0 +1,64 @@
+package encrypt
+
+import (
+	"testing"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Convergent(t *testing.T) {
+	c := NewConvergent([]byte("backup-domain"))
+	leaves := [][]byte{[]byte("chunk 1"), []byte("chunk 2"), []byte("chunk 1")}
+
+	t.Run("should encrypt equal leaves to equal ciphertexts", func(t *testing.T) {
+		a, keyA, err := c.Encrypt(leaves[0])
+		require.NoError(t, err)
+		b, keyB, err := c.Encrypt(leaves[2])
+		require.NoError(t, err)
+		require.Equal(t, a, b)
+		require.Equal(t, keyA, keyB)
+		require.NotContains(t, string(a), "chunk")
+
+		other, _, err := NewConvergent([]byte("other-domain")).Encrypt(leaves[0])
+		require.NoError(t, err)
+		require.NotEqual(t, a, other)
+	})
+
+	t.Run("should build a tree over ciphertexts that decrypt with the keys", func(t *testing.T) {
+		tree, keys, err := c.NewTree(leaves)
+		require.NoError(t, err)
+
+		stored := tree.Leaves()
+		require.Equal(t, stored[0].Hash, stored[2].Hash)
+		for i, leaf := range stored {
+			data, err := c.Decrypt(keys[i], leaf.Data)
+			require.NoError(t, err)
+			require.Equal(t, leaves[i], data)
+
+			proof, err := tree.GenerateProofAt(i)
+			require.NoError(t, err)
+			require.True(t, tree.VerifyData(leaf.Data, proof))
+		}
+	})
+
+	t.Run("should reject the wrong key", func(t *testing.T) {
+		ciphertext, _, err := c.Encrypt(leaves[0])
+		require.NoError(t, err)
+		_, err = c.Decrypt(c.Key(leaves[1]), ciphertext)
+		require.Error(t, err)
+	})
+
+	t.Run("should reject a key from another domain", func(t *testing.T) {
+		other := NewConvergent(nil)
+		ciphertext, key, err := other.Encrypt(leaves[0])
+		require.NoError(t, err)
+		_, err = c.Decrypt(key, ciphertext)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+	})
+
+	t.Run("should pass tree options through", func(t *testing.T) {
+		_, _, err := c.NewTree(leaves, merkle.WithScheme(merkle.SchemeOZSorted), merkle.WithFIPSOnly())
+		require.ErrorIs(t, err, merkle.ErrNotFIPS)
+	})
+}
@@ -0,0 +1,72 @@
+// Package encrypt encrypts exported proof bundles to age recipients, so
+// per-user proofs can be distributed without being publicly enumerable, and
+// encrypts leaves convergently, so trees over confidential data still dedup.
+package encrypt
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/chakra-guy/merkle"
+)
+
+// Bundle encrypts the JSON encoding of a proof bundle to the recipients, as
+// ASCII armor if armored is set
+func Bundle(b merkle.ProofBundle, armored bool, recipients ...age.Recipient) ([]byte, error) {
+	plaintext, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	var out io.WriteCloser = nopCloser{&buf}
+	if armored {
+		out = armor.NewWriter(&buf)
+	}
+	w, err := age.Encrypt(out, recipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// OpenBundle decrypts a proof bundle encrypted by Bundle, armored or not, with
+// one of the identities
+func OpenBundle(ciphertext []byte, identities ...age.Identity) (merkle.ProofBundle, error) {
+	var in io.Reader = bytes.NewReader(ciphertext)
+	if bytes.HasPrefix(ciphertext, []byte(armor.Header)) {
+		in = armor.NewReader(in)
+	}
+	r, err := age.Decrypt(in, identities...)
+	if err != nil {
+		return merkle.ProofBundle{}, err
+	}
+
+	var b merkle.ProofBundle
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return merkle.ProofBundle{}, err
+	}
+	return b, nil
+}
+
+// nopCloser is a WriteCloser whose Close does nothing
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
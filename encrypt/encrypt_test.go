@@ -0,0 +1,41 @@
+package encrypt
+
+import (
+	"bytes"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Bundle(t *testing.T) {
+	tree, err := merkle.New([][]byte{[]byte("alice"), []byte("bob"), []byte("carol")})
+	require.NoError(t, err)
+	bundle, err := tree.GenerateBundle("airdrop", []byte("bob"))
+	require.NoError(t, err)
+
+	bob, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	eve, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	for _, armored := range []bool{false, true} {
+		ciphertext, err := Bundle(bundle, armored, bob.Recipient())
+		require.NoError(t, err)
+		require.Equal(t, armored, bytes.HasPrefix(ciphertext, []byte(armor.Header)))
+
+		t.Run("should decrypt for the recipient", func(t *testing.T) {
+			decrypted, err := OpenBundle(ciphertext, bob)
+			require.NoError(t, err)
+			require.Equal(t, bundle.Leaf, decrypted.Leaf)
+			require.NoError(t, merkle.VerifyAcross(map[string][]byte{"airdrop": tree.Root()}, []merkle.ProofBundle{decrypted}))
+		})
+
+		t.Run("should not decrypt for other identities", func(t *testing.T) {
+			_, err := OpenBundle(ciphertext, eve)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/subtle"
+)
+
+// RootEqual reports whether both trees have the same root hash, in constant time
+func (m *MerkleTree) RootEqual(other *MerkleTree) bool {
+	return subtle.ConstantTimeCompare(m.Root(), other.Root()) == 1
+}
+
+// Equal reports whether both trees hold the same leaves in the same order
+// under the same tree options. The hash function cannot be compared directly,
+// but differing ones show up as differing leaf hashes.
+func (m *MerkleTree) Equal(other *MerkleTree) bool {
+	if m.promoteOdd != other.promoteOdd ||
+		m.dropData != other.dropData ||
+		m.sortLeaves != other.sortLeaves ||
+		m.blinding != other.blinding ||
+		m.swapOrder != other.swapOrder ||
+		m.keyID != other.keyID ||
+		m.schemeName() != other.schemeName() ||
+		!bytes.Equal(m.leafPrefix, other.leafPrefix) ||
+		!bytes.Equal(m.nodePrefix, other.nodePrefix) ||
+		!bytes.Equal(m.personalization, other.personalization) {
+		return false
+	}
+
+	if len(m.leafs) != len(other.leafs) {
+		return false
+	}
+	for i, leaf := range m.leafs {
+		if !bytes.Equal(leaf.hash, other.leafs[i].hash) || !bytes.Equal(leaf.data, other.leafs[i].data) {
+			return false
+		}
+	}
+	return m.RootEqual(other)
+}
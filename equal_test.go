@@ -0,0 +1,47 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RootEqual(t *testing.T) {
+	a, err := New([][]byte{[]byte("a"), []byte("b")})
+	require.NoError(t, err)
+	b, err := New([][]byte{[]byte("a"), []byte("b")})
+	require.NoError(t, err)
+	c, err := New([][]byte{[]byte("b"), []byte("a")})
+	require.NoError(t, err)
+
+	t.Run("should compare root hashes", func(t *testing.T) {
+		require.True(t, a.RootEqual(b))
+		require.False(t, a.RootEqual(c))
+	})
+}
+
+func Test_Equal(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	tree, err := New(data)
+	require.NoError(t, err)
+
+	t.Run("should be equal for the same leaves and options", func(t *testing.T) {
+		other, err := New(data)
+		require.NoError(t, err)
+		require.True(t, tree.Equal(other))
+	})
+
+	t.Run("should not be equal for different leaves", func(t *testing.T) {
+		other, err := New(data[:2])
+		require.NoError(t, err)
+		require.False(t, tree.Equal(other))
+	})
+
+	t.Run("should not be equal for different options", func(t *testing.T) {
+		for _, opt := range []Option{WithOddNodePromotion(), WithoutStoringData(), WithHashFunction(mockHash), WithPersonalization("app")} {
+			other, err := New(data, opt)
+			require.NoError(t, err)
+			require.False(t, tree.Equal(other))
+		}
+	})
+}
@@ -0,0 +1,246 @@
+package merkle
+
+import (
+	"errors"
+)
+
+var (
+	ErrShareSize    = errors.New("shares must be non-empty and of equal size")
+	ErrTooFewShares = errors.New("too few shares to recover the data")
+)
+
+// MaxShares is the most shares a Reed–Solomon code over GF(2^8) can extend to
+const MaxShares = 256
+
+// ExtendShares extends k equally sized data shares to 2k with a systematic
+// Reed–Solomon code over GF(2^8): the first k shares are the data, and any k
+// of the 2k shares recover it
+func ExtendShares(shares [][]byte) ([][]byte, error) {
+	k := len(shares)
+	if err := checkShares(shares, MaxShares/2); err != nil {
+		return nil, err
+	}
+
+	xs := make([]byte, k)
+	for i := range xs {
+		xs[i] = byte(i)
+	}
+	extended := append(make([][]byte, 0, 2*k), shares...)
+	for x := k; x < 2*k; x++ {
+		extended = append(extended, gfInterpolate(xs, shares, byte(x)))
+	}
+	return extended, nil
+}
+
+// RecoverShares recovers the k data shares from the 2k extended shares, any
+// k of which must be present; missing shares are nil
+func RecoverShares(extended [][]byte) ([][]byte, error) {
+	k := len(extended) / 2
+	var xs []byte
+	var ys [][]byte
+	for i, share := range extended {
+		if share != nil && len(xs) < k {
+			xs = append(xs, byte(i))
+			ys = append(ys, share)
+		}
+	}
+	if len(extended)%2 != 0 || k == 0 || len(xs) < k {
+		return nil, ErrTooFewShares
+	}
+	if err := checkShares(ys, MaxShares/2); err != nil {
+		return nil, err
+	}
+
+	shares := make([][]byte, k)
+	for x := range shares {
+		shares[x] = gfInterpolate(xs, ys, byte(x))
+	}
+	return shares, nil
+}
+
+// ExtendedSquare is a k×k square of data shares extended to 2k×2k by
+// Reed–Solomon coding every row and column, with a Merkle root per row and
+// column, as data availability layers construct it
+type ExtendedSquare struct {
+	Width    int
+	Shares   [][]byte // row-major
+	RowRoots [][]byte
+	ColRoots [][]byte
+
+	m *MerkleTree
+}
+
+// ExtendSquare extends k*k data shares, given row by row, into a 2k×2k square
+func ExtendSquare(shares [][]byte, opts ...Option) (*ExtendedSquare, error) {
+	k := 0
+	for k*k < len(shares) {
+		k++
+	}
+	if k*k != len(shares) {
+		return nil, ErrShareSize
+	}
+	if err := checkShares(shares, MaxShares*MaxShares/4); err != nil {
+		return nil, err
+	}
+
+	s := &ExtendedSquare{Width: 2 * k, Shares: make([][]byte, 4*k*k), m: newVerifier(opts...)}
+	for row := 0; row < k; row++ {
+		extended, err := ExtendShares(shares[row*k : (row+1)*k])
+		if err != nil {
+			return nil, err
+		}
+		copy(s.Shares[row*s.Width:], extended)
+	}
+	for col := 0; col < s.Width; col++ {
+		extended, err := ExtendShares(s.column(col)[:k])
+		if err != nil {
+			return nil, err
+		}
+		for row, share := range extended {
+			s.Shares[row*s.Width+col] = share
+		}
+	}
+
+	for i := 0; i < s.Width; i++ {
+		row, err := s.tree(s.row(i))
+		if err != nil {
+			return nil, err
+		}
+		col, err := s.tree(s.column(i))
+		if err != nil {
+			return nil, err
+		}
+		s.RowRoots, s.ColRoots = append(s.RowRoots, row.Root()), append(s.ColRoots, col.Root())
+	}
+	return s, nil
+}
+
+// DataRoot returns the root of the tree over the row roots followed by the
+// column roots, which commits to the whole square
+func (s *ExtendedSquare) DataRoot() ([]byte, error) {
+	t, err := s.tree(append(append([][]byte{}, s.RowRoots...), s.ColRoots...))
+	if err != nil {
+		return nil, err
+	}
+	return t.Root(), nil
+}
+
+// ProveShare returns the share at the given position with its proof against
+// the root of its row
+func (s *ExtendedSquare) ProveShare(row, col int) ([]byte, Proof, error) {
+	if row < 0 || row >= s.Width || col < 0 || col >= s.Width {
+		return nil, nil, ErrOutOfRange
+	}
+	t, err := s.tree(s.row(row))
+	if err != nil {
+		return nil, nil, err
+	}
+	proof, err := t.GenerateProofAt(col)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.Shares[row*s.Width+col], proof, nil
+}
+
+// VerifyShare verifies a sampled share against the root of its row
+func VerifyShare(rowRoot, share []byte, proof Proof, opts ...Option) bool {
+	m := newVerifier(opts...)
+	hash, err := m.hashData(share)
+	return err == nil && m.provesRoot(m.newHash(), hash, proof, rowRoot)
+}
+
+// row returns the shares of a row
+func (s *ExtendedSquare) row(row int) [][]byte {
+	return s.Shares[row*s.Width : (row+1)*s.Width]
+}
+
+// column returns the shares of a column
+func (s *ExtendedSquare) column(col int) [][]byte {
+	shares := make([][]byte, s.Width)
+	for row := range shares {
+		shares[row] = s.Shares[row*s.Width+col]
+	}
+	return shares
+}
+
+// tree builds a tree over shares with the square's options
+func (s *ExtendedSquare) tree(shares [][]byte) (*MerkleTree, error) {
+	t := s.m.cloneOptions()
+	t.leafs = make([]*Node, len(shares))
+	for i, share := range shares {
+		leaf, err := t.newLeaf(share)
+		if err != nil {
+			return nil, err
+		}
+		t.leafs[i] = leaf
+	}
+	t.rebuild()
+	return t, nil
+}
+
+// checkShares checks that there are at most limit shares of equal, non-zero size
+func checkShares(shares [][]byte, limit int) error {
+	if len(shares) == 0 || len(shares) > limit {
+		return ErrShareSize
+	}
+	for _, share := range shares {
+		if len(share) == 0 || len(share) != len(shares[0]) {
+			return ErrShareSize
+		}
+	}
+	return nil
+}
+
+// gfExp and gfLog are the exponent and logarithm tables of GF(2^8) with the
+// generator 2 and the reducing polynomial x^8 + x^4 + x^3 + x^2 + 1
+var gfExp, gfLog = func() (exp [510]byte, log [256]byte) {
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i], exp[i+255] = byte(x), byte(x)
+		log[x] = byte(i)
+		if x <<= 1; x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	return exp, log
+}()
+
+// gfMul multiplies in GF(2^8)
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// gfDiv divides in GF(2^8); b must not be zero
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// gfInterpolate evaluates, byte by byte, the polynomial through the points
+// (xs[i], ys[i]) at x using Lagrange interpolation
+func gfInterpolate(xs []byte, ys [][]byte, x byte) []byte {
+	out := make([]byte, len(ys[0]))
+	for i, xi := range xs {
+		if xi == x {
+			copy(out, ys[i])
+			return out
+		}
+
+		// the Lagrange basis polynomial of point i at x; subtraction is xor
+		basis := byte(1)
+		for j, xj := range xs {
+			if j != i {
+				basis = gfMul(basis, gfDiv(x^xj, xi^xj))
+			}
+		}
+		for b, y := range ys[i] {
+			out[b] ^= gfMul(basis, y)
+		}
+	}
+	return out
+}
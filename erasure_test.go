@@ -0,0 +1,88 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ExtendShares(t *testing.T) {
+	shares := [][]byte{[]byte("abcd"), []byte("efgh"), []byte("ijkl")}
+	extended, err := ExtendShares(shares)
+	require.NoError(t, err)
+
+	t.Run("should keep the data shares first", func(t *testing.T) {
+		require.Len(t, extended, 6)
+		require.Equal(t, shares, extended[:3])
+	})
+
+	t.Run("should recover from any half of the shares", func(t *testing.T) {
+		for _, missing := range [][]int{{0, 1, 2}, {3, 4, 5}, {0, 2, 4}, {1, 5}} {
+			partial := append([][]byte{}, extended...)
+			for _, i := range missing {
+				partial[i] = nil
+			}
+			recovered, err := RecoverShares(partial)
+			require.NoError(t, err)
+			require.Equal(t, shares, recovered)
+		}
+	})
+
+	t.Run("should return error for too few shares", func(t *testing.T) {
+		partial := append([][]byte{}, extended...)
+		partial[0], partial[1], partial[2], partial[3] = nil, nil, nil, nil
+		_, err := RecoverShares(partial)
+		require.ErrorIs(t, err, ErrTooFewShares)
+	})
+
+	t.Run("should return error for unequal shares", func(t *testing.T) {
+		_, err := ExtendShares([][]byte{[]byte("ab"), []byte("c")})
+		require.ErrorIs(t, err, ErrShareSize)
+	})
+}
+
+func Test_ExtendSquare(t *testing.T) {
+	shares := [][]byte{[]byte("aa"), []byte("bb"), []byte("cc"), []byte("dd")}
+	square, err := ExtendSquare(shares)
+	require.NoError(t, err)
+
+	t.Run("should extend every row and column", func(t *testing.T) {
+		require.Equal(t, 4, square.Width)
+		require.Len(t, square.RowRoots, 4)
+		require.Len(t, square.ColRoots, 4)
+
+		for i := 0; i < square.Width; i++ {
+			row, err := RecoverShares(append(append([][]byte{}, square.row(i)[:2]...), nil, nil))
+			require.NoError(t, err)
+			extended, err := ExtendShares(row)
+			require.NoError(t, err)
+			require.Equal(t, square.row(i), extended)
+
+			col, err := RecoverShares(append([][]byte{nil, nil}, square.column(i)[2:]...))
+			require.NoError(t, err)
+			extended, err = ExtendShares(col)
+			require.NoError(t, err)
+			require.Equal(t, square.column(i), extended)
+		}
+	})
+
+	t.Run("should verify sampled shares against row roots", func(t *testing.T) {
+		share, proof, err := square.ProveShare(3, 1)
+		require.NoError(t, err)
+		require.True(t, VerifyShare(square.RowRoots[3], share, proof))
+		require.False(t, VerifyShare(square.RowRoots[2], share, proof))
+	})
+
+	t.Run("should commit to the row and column roots", func(t *testing.T) {
+		tree, err := New(append(append([][]byte{}, square.RowRoots...), square.ColRoots...))
+		require.NoError(t, err)
+		root, err := square.DataRoot()
+		require.NoError(t, err)
+		require.Equal(t, tree.Root(), root)
+	})
+
+	t.Run("should return error for non-square data", func(t *testing.T) {
+		_, err := ExtendSquare(shares[:3])
+		require.ErrorIs(t, err, ErrShareSize)
+	})
+}
@@ -0,0 +1,51 @@
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Explain describes step by step how the proof recomputes the root from the
+// leaf data: every hash input, side and intermediate digest, and whether the
+// result matches root. opts give the hashing options the tree was built with;
+// digests are in hex unless WithTextEncoding is among them. It is meant for
+// support cases and for debugging proofs across implementations.
+func (p Proof) Explain(leaf, root []byte, opts ...Option) string {
+	m := newVerifier(opts...)
+	enc := m.TextEncoding().EncodeToString
+	var b strings.Builder
+
+	if name := m.HashAlgorithm(); name != "" {
+		fmt.Fprintf(&b, "hash:          %s\n", name)
+	}
+	if m.scheme == nil && len(m.leafPrefix)+len(m.nodePrefix) > 0 {
+		fmt.Fprintf(&b, "leaf prefix:   %s\nnode prefix:   %s\n", enc(m.leafPrefix), enc(m.nodePrefix))
+	}
+	hash := m.hashLeaf(leaf)
+	fmt.Fprintf(&b, "leaf data:     %s\nleaf hash:     %s\n", enc(leaf), enc(hash))
+
+	h := m.newHash()
+	for i, pe := range p {
+		var left, right []byte
+		switch pe.Side {
+		case Left:
+			left, right = pe.Hash, hash
+		case Right:
+			left, right = hash, pe.Hash
+		default:
+			fmt.Fprintf(&b, "step %d: %v\nresult:        invalid proof\n", i+1, pe.Side)
+			return b.String()
+		}
+		hash = m.hashNodeWith(h, left, right)
+		fmt.Fprintf(&b, "step %d: sibling on the %s\n  left:        %s\n  right:       %s\n  hash:        %s\n", i+1, pe.Side, enc(left), enc(right), enc(hash))
+	}
+
+	fmt.Fprintf(&b, "computed root: %s\nexpected root: %s\n", enc(hash), enc(root))
+	if bytes.Equal(hash, root) {
+		b.WriteString("result:        match\n")
+	} else {
+		b.WriteString("result:        mismatch\n")
+	}
+	return b.String()
+}
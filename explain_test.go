@@ -0,0 +1,52 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Explain(t *testing.T) {
+	tree, err := New([][]byte{[]byte("a"), []byte("b"), []byte("c")}, WithHashFunction(mockHash))
+	require.NoError(t, err)
+	proof, err := tree.GenerateProofAt(2)
+	require.NoError(t, err)
+
+	t.Run("should show every hash computation", func(t *testing.T) {
+		require.Equal(t, ""+
+			"leaf data:     63\n"+
+			"leaf hash:     68617368286329\n"+
+			"step 1: sibling on the right\n"+
+			"  left:        68617368286329\n"+
+			"  right:       68617368286329\n"+
+			"  hash:        6861736828686173682863296861736828632929\n"+
+			"step 2: sibling on the left\n"+
+			"  left:        6861736828686173682861296861736828622929\n"+
+			"  right:       6861736828686173682863296861736828632929\n"+
+			"  hash:        68617368286861736828686173682861296861736828622929686173682868617368286329686173682863292929\n"+
+			"computed root: 68617368286861736828686173682861296861736828622929686173682868617368286329686173682863292929\n"+
+			"expected root: 68617368286861736828686173682861296861736828622929686173682868617368286329686173682863292929\n"+
+			"result:        match\n",
+			proof.Explain([]byte("c"), tree.Root(), WithHashFunction(mockHash)))
+	})
+
+	t.Run("should report mismatches and the hashing configuration", func(t *testing.T) {
+		text := proof.Explain([]byte("c"), tree.Root())
+		require.Contains(t, text, "hash:          sha256\n")
+		require.Contains(t, text, "result:        mismatch\n")
+
+		text = proof.Explain([]byte("c"), tree.Root(), WithDomainSeparation([]byte{0}, []byte{1}))
+		require.Contains(t, text, "leaf prefix:   00\nnode prefix:   01\n")
+	})
+
+	t.Run("should use the configured text encoding", func(t *testing.T) {
+		text := proof.Explain([]byte("c"), tree.Root(), WithHashFunction(mockHash), WithTextEncoding(Base64URL))
+		require.Contains(t, text, "leaf hash:     "+Base64URL.EncodeToString([]byte("hash(c)"))+"\n")
+		require.Contains(t, text, "result:        match\n")
+	})
+
+	t.Run("should stop at invalid sides", func(t *testing.T) {
+		text := Proof{{Hash: []byte("x"), Side: 7}}.Explain([]byte("c"), tree.Root(), WithHashFunction(mockHash))
+		require.Contains(t, text, "step 1: Side(7)\nresult:        invalid proof\n")
+	})
+}
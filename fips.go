@@ -0,0 +1,132 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/blake2s"
+	"golang.org/x/crypto/sha3"
+)
+
+var ErrNotFIPS = errors.New("hash algorithm is not FIPS-approved")
+
+// hashProbe is the input hash functions are identified by
+var hashProbe = []byte("merkle hash algorithm probe")
+
+// hashAlgorithms are the hash functions HashAlgorithm recognizes, and whether
+// FIPS 180-4 or FIPS 202 approves them for collision resistance
+var hashAlgorithms = []struct {
+	name string
+	fn   func() hash.Hash
+	fips bool
+}{
+	{"sha256", sha256.New, true},
+	{"sha224", sha256.New224, true},
+	{"sha384", sha512.New384, true},
+	{"sha512", sha512.New, true},
+	{"sha512/224", sha512.New512_224, true},
+	{"sha512/256", sha512.New512_256, true},
+	{"sha3-224", sha3.New224, true},
+	{"sha3-256", sha3.New256, true},
+	{"sha3-384", sha3.New384, true},
+	{"sha3-512", sha3.New512, true},
+	{"keccak256", sha3.NewLegacyKeccak256, false},
+	{"keccak512", sha3.NewLegacyKeccak512, false},
+	{"blake2b-256", func() hash.Hash { h, _ := blake2b.New256(nil); return h }, false},
+	{"blake2b-512", func() hash.Hash { h, _ := blake2b.New512(nil); return h }, false},
+	{"blake2s-256", func() hash.Hash { h, _ := blake2s.New256(nil); return h }, false},
+	{"sha1", sha1.New, false},
+	{"md5", md5.New, false},
+}
+
+// fipsSchemes are the built-in schemes hashing with SHA-256 only
+var fipsSchemes = []Scheme{SchemeRFC6962, SchemeBitcoin, SchemeCometBFT}
+
+// WithFIPSOnly restricts the tree to FIPS-approved hash algorithms: SHA-2 and
+// SHA-3, optionally keyed with HMAC, or a built-in scheme using SHA-256.
+// Constructors return ErrNotFIPS for any other hash function or scheme.
+func WithFIPSOnly() Option {
+	return func(m *MerkleTree) {
+		m.fipsOnly = true
+	}
+}
+
+// HashAlgorithm names the hash the tree is built with, as recorded in proof
+// bundles, leaf pages and dumps: a built-in scheme's name, or the hash
+// function identified by a known-answer test, prefixed with "hmac-" for keyed
+// trees and suffixed with the truncated length in bytes. It returns "" for an
+// unrecognized hash function.
+func (m *MerkleTree) HashAlgorithm() string {
+	if m.scheme != nil {
+		return m.scheme.Name
+	}
+	name, _ := identifyHash(m.hashFn)
+	if name == "" {
+		return ""
+	}
+	if m.hmacKey != nil {
+		name = "hmac-" + name
+	}
+	if m.truncate > 0 && m.truncate < m.hashFn().Size() {
+		name = fmt.Sprintf("%s-trunc%d", name, m.truncate)
+	}
+	return name
+}
+
+// checkFIPS returns ErrNotFIPS if the tree is restricted to FIPS-approved
+// algorithms and hashes with another one
+func (m *MerkleTree) checkFIPS() error {
+	if !m.fipsOnly {
+		return nil
+	}
+	if m.scheme != nil {
+		if !isFIPSScheme(*m.scheme) {
+			return fmt.Errorf("%w: scheme %s", ErrNotFIPS, m.scheme.Name)
+		}
+		return nil
+	}
+	name, fips := identifyHash(m.hashFn)
+	if !fips {
+		if name == "" {
+			name = "unrecognized hash function"
+		}
+		return fmt.Errorf("%w: %s", ErrNotFIPS, name)
+	}
+	return nil
+}
+
+// identifyHash returns the name of a recognized hash function and whether it
+// is FIPS-approved, by comparing its digest of the probe
+func identifyHash(fn func() hash.Hash) (string, bool) {
+	h := fn()
+	h.Write(hashProbe)
+	digest := h.Sum(nil)
+	for _, alg := range hashAlgorithms {
+		ref := alg.fn()
+		ref.Write(hashProbe)
+		if bytes.Equal(ref.Sum(nil), digest) {
+			return alg.name, alg.fips
+		}
+	}
+	return "", false
+}
+
+// isFIPSScheme reports whether a scheme hashes as the FIPS built-in scheme of
+// the same name, so a custom scheme cannot pass by borrowing its name
+func isFIPSScheme(s Scheme) bool {
+	for _, ref := range fipsSchemes {
+		if s.Name != ref.Name {
+			continue
+		}
+		return bytes.Equal(s.HashLeaf(hashProbe), ref.HashLeaf(hashProbe)) &&
+			bytes.Equal(s.HashNode(hashProbe, hashProbe), ref.HashNode(hashProbe, hashProbe))
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package merkle
+
+import (
+	"crypto/sha512"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func Test_WithFIPSOnly(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+
+	t.Run("should accept SHA-2, SHA-3 and FIPS schemes", func(t *testing.T) {
+		for _, opts := range [][]Option{
+			nil,
+			{WithHashFunction(sha512.New384)},
+			{WithHashFunction(sha3.New256)},
+			{WithHMACKey("k1", []byte("key"))},
+			{WithRFC6962()},
+			{WithScheme(SchemeCometBFT)},
+		} {
+			_, err := New(data, append(opts, WithFIPSOnly())...)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("should reject other hash functions and schemes", func(t *testing.T) {
+		for _, opt := range []Option{
+			WithHashFunction(sha3.NewLegacyKeccak256),
+			WithHashFunction(mockHash),
+			WithScheme(SchemeOZSorted),
+			WithScheme(Scheme{Name: "rfc6962", HashSize: 32, HashLeaf: keccakLeaf, HashNode: sortedKeccakNode}),
+		} {
+			_, err := New(data, opt, WithFIPSOnly())
+			require.ErrorIs(t, err, ErrNotFIPS)
+		}
+	})
+
+	t.Run("should apply to every constructor", func(t *testing.T) {
+		opts := []Option{WithHashFunction(sha3.NewLegacyKeccak256), WithFIPSOnly()}
+		_, err := NewBuilder(opts...).Add([]byte("a")).Build()
+		require.ErrorIs(t, err, ErrNotFIPS)
+		_, err = NewNary(data, opts...)
+		require.ErrorIs(t, err, ErrNotFIPS)
+		_, err = NewPersistent(data, opts...)
+		require.ErrorIs(t, err, ErrNotFIPS)
+	})
+}
+
+func Test_HashAlgorithm(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+
+	t.Run("should name the hash function of the tree", func(t *testing.T) {
+		for expected, opts := range map[string][]Option{
+			"sha256":         nil,
+			"sha3-512":       {WithHashFunction(sha3.New512)},
+			"keccak256":      {WithHashFunction(sha3.NewLegacyKeccak256)},
+			"hmac-sha256":    {WithHMACKey("k1", []byte("key"))},
+			"sha512-trunc20": {WithHashFunction(sha512.New), WithHashTruncation(20)},
+			"bitcoin":        {WithScheme(SchemeBitcoin)},
+			"":               {WithHashFunction(mockHash)},
+		} {
+			tree, err := New(data, opts...)
+			require.NoError(t, err)
+			require.Equal(t, expected, tree.HashAlgorithm())
+		}
+	})
+
+	t.Run("should record the algorithm in serialized artifacts", func(t *testing.T) {
+		tree, err := New(data, WithHashFunction(sha3.New256))
+		require.NoError(t, err)
+
+		bundle, err := tree.GenerateBundle("t1", []byte("a"))
+		require.NoError(t, err)
+		encoded, err := json.Marshal(bundle)
+		require.NoError(t, err)
+		require.Contains(t, string(encoded), `"hash_algorithm":"sha3-256"`)
+
+		page, err := tree.GetLeaves(0, 1, false)
+		require.NoError(t, err)
+		require.Equal(t, "sha3-256", page.HashAlgorithm)
+
+		text, err := tree.Dump().MarshalText()
+		require.NoError(t, err)
+		var d TreeDump
+		require.NoError(t, d.UnmarshalText(text))
+		require.Equal(t, "sha3-256", d.HashAlgorithm)
+	})
+}
@@ -0,0 +1,98 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// decodeFuzzProof turns arbitrary bytes into a proof of 32-byte hashes, each
+// preceded by a raw side byte that may be out of range
+func decodeFuzzProof(b []byte) Proof {
+	var proof Proof
+	for len(b) >= 33 {
+		proof = append(proof, ProofElement{Side: Side(int8(b[0])), Hash: b[1:33:33]})
+		b = b[33:]
+	}
+	return proof
+}
+
+func FuzzVerifyProof(f *testing.F) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	tree, err := New(data)
+	require.NoError(f, err)
+
+	for _, item := range data {
+		proof, err := tree.GenerateProof(item)
+		require.NoError(f, err)
+
+		var seed []byte
+		for _, pe := range proof {
+			seed = append(seed, byte(pe.Side))
+			seed = append(seed, pe.Hash...)
+		}
+		f.Add(item, seed)
+	}
+
+	f.Fuzz(func(t *testing.T, item, b []byte) {
+		proof := decodeFuzzProof(b)
+		hash := tree.hashLeaf(item)
+
+		valid := tree.VerifyProof(hash, proof)
+		err := tree.Verify(hash, proof)
+		require.Equal(t, valid, err == nil)
+
+		for _, pe := range proof {
+			if !pe.Side.Valid() {
+				require.False(t, valid)
+				require.ErrorIs(t, err, ErrInvalidSide)
+			}
+		}
+	})
+}
+
+func FuzzCompressedProof(f *testing.F) {
+	tree, err := New([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	require.NoError(f, err)
+
+	proof, err := tree.GenerateProof([]byte("c"))
+	require.NoError(f, err)
+	seed, err := tree.CompressProof(tree.hashLeaf([]byte("c")), proof).MarshalBinary()
+	require.NoError(f, err)
+	f.Add(seed)
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var cp CompressedProof
+		if cp.UnmarshalBinary(b) != nil {
+			return
+		}
+		// any decodable proof must be handled without panicking
+		tree.VerifyCompressedProof(tree.hashLeaf([]byte("c")), cp)
+	})
+}
+
+func FuzzInvariants(f *testing.F) {
+	f.Add([]byte("abcde"), false, 2)
+	f.Add([]byte("abcdefghijk"), true, 5)
+
+	f.Fuzz(func(t *testing.T, b []byte, promote bool, added int) {
+		if len(b) == 0 {
+			return
+		}
+		var opts []Option
+		if promote {
+			opts = append(opts, WithOddNodePromotion())
+		}
+
+		data := make([][]byte, len(b))
+		for i := range b {
+			data[i] = b[i : i+1]
+		}
+		tree, err := New(data, opts...)
+		require.NoError(t, err)
+		for i := 0; i < added%16; i++ {
+			tree.AddLeaf([]byte{byte(i)})
+		}
+		require.NoError(t, CheckInvariants(tree))
+	})
+}
@@ -2,10 +2,16 @@ module github.com/chakra-guy/merkle
 
 go 1.21.6
 
-require github.com/stretchr/testify v1.9.0
+require (
+	filippo.io/age v1.1.1
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/stretchr/testify v1.9.0
+	golang.org/x/crypto v0.31.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,178 @@
+package merkle
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var ErrUnknownVersion = errors.New("version is not retained")
+
+// RollbackEvent records a RollbackTo: the version the tree was at, the
+// retained version it restored and the new version holding the restored
+// leaves, with the roots before and after the rollback
+type RollbackEvent struct {
+	From     int    `json:"from"`
+	To       int    `json:"to"`
+	Version  int    `json:"version"`
+	FromRoot []byte `json:"from_root"`
+	ToRoot   []byte `json:"to_root"`
+}
+
+// treeVersion is the state of the leaves at a commit
+type treeVersion struct {
+	version int
+	root    []byte
+	keyID   string // the key the leaves were hashed with, for keyed trees
+	leaves  []Node
+}
+
+// WithVersionHistory retains the leaves of the versions of the tree the policy
+// keeps, as a Catalog does its snapshots, so RollbackTo can restore them. Every
+// commit, from the construction of the tree on, is a new version; retaining one
+// copies the leaf references.
+func WithVersionHistory(p RetentionPolicy) Option {
+	return func(m *MerkleTree) {
+		m.history = &p
+	}
+}
+
+// WithRollbackLog calls fn after every RollbackTo, once the changelog, if any,
+// has received the node changes of the restored version. fn runs with the tree
+// locked and must not call into it.
+func WithRollbackLog(fn func(RollbackEvent)) Option {
+	return func(m *MerkleTree) {
+		m.rollbackLog = fn
+	}
+}
+
+// Version returns the current version of the tree
+func (m *MerkleTree) Version() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flushPending()
+	return m.version
+}
+
+// Versions returns the retained versions, oldest first
+func (m *MerkleTree) Versions() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flushPending()
+	versions := make([]int, len(m.versions))
+	for i, v := range m.versions {
+		versions[i] = v.version
+	}
+	return versions
+}
+
+// PinVersion retains a version regardless of the history policy
+func (m *MerkleTree) PinVersion(version int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flushPending()
+	if m.retained(version) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	if m.pinned == nil {
+		m.pinned = map[int]bool{}
+	}
+	m.pinned[version] = true
+	return nil
+}
+
+// UnpinVersion returns a version to the history policy, which may drop it on
+// the next commit
+func (m *MerkleTree) UnpinVersion(version int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pinned, version)
+}
+
+// retained returns the retained version, or nil
+func (m *MerkleTree) retained(version int) *treeVersion {
+	for i := range m.versions {
+		if m.versions[i].version == version {
+			return &m.versions[i]
+		}
+	}
+	return nil
+}
+
+// RollbackTo atomically restores the leaves and root of a retained version,
+// such as the one before a bad batch import, as a new version. Leaves added
+// since are dropped, and so are the records of AppendIdempotent. A keyed tree
+// keeps its current key: leaves of a version hashed under an earlier key are
+// rehashed, so its root then differs from the version's, and their data must
+// be stored.
+func (m *MerkleTree) RollbackTo(version int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flushPending()
+
+	target := m.retained(version)
+	if target == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	rehash := m.hmacKey != nil && target.keyID != m.keyID
+	leafs := make([]*Node, len(target.leaves), max(len(target.leaves), m.capacity))
+	for i, leaf := range target.leaves {
+		if rehash && leaf.data == nil {
+			return ErrDataNotStored
+		}
+		leafs[i] = &Node{hash: leaf.hash, data: m.owned(leaf.data), value: leaf.value, blind: m.owned(leaf.blind)}
+		if rehash {
+			leafs[i].hash = m.hashBlindedLeaf(leafs[i].blind, leafs[i].data)
+		}
+	}
+
+	event := RollbackEvent{From: m.version, To: version, FromRoot: m.root.hash}
+	if m.zeroize {
+		for _, leaf := range m.leafs {
+			clear(leaf.data)
+			clear(leaf.blind)
+		}
+	}
+	m.leafs, m.appendIDs = leafs, nil
+	m.rebuild()
+
+	event.Version, event.ToRoot = m.version, m.root.hash
+	if m.rollbackLog != nil {
+		m.rollbackLog(event)
+	}
+	return nil
+}
+
+// recordVersion retains the leaves of the current version and drops the
+// retained versions the history policy no longer keeps
+func (m *MerkleTree) recordVersion() {
+	if m.history == nil {
+		return
+	}
+	v := treeVersion{version: m.version, root: m.root.hash, keyID: m.keyID, leaves: make([]Node, len(m.leafs))}
+	for i, leaf := range m.leafs {
+		v.leaves[i] = Node{hash: leaf.hash, data: m.owned(leaf.data), value: leaf.value, blind: m.owned(leaf.blind)}
+	}
+	m.versions = append(m.versions, v)
+	m.versions = slices.DeleteFunc(m.versions, func(v treeVersion) bool {
+		// versions count from 1, retention sequences from 0
+		if m.pinned[v.version] || m.history.keeps(v.version-1, m.version) {
+			return false
+		}
+		if m.zeroize {
+			wipeVersions([]treeVersion{v})
+		}
+		return true
+	})
+}
+
+// wipeVersions overwrites the leaf data and blinds of retained versions with zeros
+func wipeVersions(versions []treeVersion) {
+	for _, v := range versions {
+		for _, leaf := range v.leaves {
+			clear(leaf.data)
+			clear(leaf.blind)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RollbackTo(t *testing.T) {
+	t.Run("should restore the leaves and root of a retained version", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a"), []byte("b")}, WithVersionHistory(RetentionPolicy{KeepLast: 3}))
+		require.NoError(t, err)
+		good, root := tree.Version(), tree.Root()
+
+		tree.AddLeaves([][]byte{[]byte("bad1"), []byte("bad2")})
+		require.NoError(t, tree.UpdateLeaf([]byte("a"), []byte("corrupted")))
+		require.Equal(t, []int{1, 2, 3}, tree.Versions())
+
+		require.NoError(t, tree.RollbackTo(good))
+		require.Equal(t, root, tree.Root())
+		require.Len(t, tree.Leaves(), 2)
+		require.Equal(t, []byte("a"), tree.Leaves()[0].Data)
+		require.Equal(t, 4, tree.Version())
+		require.Equal(t, []int{2, 3, 4}, tree.Versions())
+
+		proof, err := tree.GenerateProof([]byte("a"))
+		require.NoError(t, err)
+		require.True(t, tree.VerifyData([]byte("a"), proof))
+	})
+
+	t.Run("should log the rollback after its node changes", func(t *testing.T) {
+		var log []any
+		tree, err := New([][]byte{[]byte("a")}, WithVersionHistory(RetentionPolicy{KeepLast: 2}),
+			WithChangelog(func(changes []NodeChange) { log = append(log, changes) }),
+			WithRollbackLog(func(e RollbackEvent) { log = append(log, e) }))
+		require.NoError(t, err)
+		before := tree.Root()
+		tree.AddLeaf([]byte("b"))
+		after := tree.Root()
+
+		log = nil
+		require.NoError(t, tree.RollbackTo(1))
+		require.Len(t, log, 2)
+		require.Contains(t, log[0], NodeChange{Level: 0, Index: 1, OldHash: tree.hashLeaf([]byte("b"))})
+		require.Contains(t, log[0], NodeChange{Level: 1, Index: 0, OldHash: after})
+		require.Equal(t, RollbackEvent{From: 2, To: 1, Version: 3, FromRoot: after, ToRoot: before}, log[1])
+	})
+
+	t.Run("should fail for versions that are not retained", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a")}, WithVersionHistory(RetentionPolicy{KeepLast: 1}))
+		require.NoError(t, err)
+		tree.AddLeaf([]byte("b"))
+		require.Equal(t, []int{2}, tree.Versions())
+		require.ErrorIs(t, tree.RollbackTo(1), ErrUnknownVersion)
+
+		plain, err := New([][]byte{[]byte("a")})
+		require.NoError(t, err)
+		require.Empty(t, plain.Versions())
+		require.ErrorIs(t, plain.RollbackTo(1), ErrUnknownVersion)
+	})
+
+	t.Run("should rehash versions from before a key rotation under the current key", func(t *testing.T) {
+		data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+		var event RollbackEvent
+		tree, err := New(data, WithHMACKey("k1", []byte("key1")), WithVersionHistory(RetentionPolicy{KeepLast: 5}),
+			WithRollbackLog(func(e RollbackEvent) { event = e }))
+		require.NoError(t, err)
+		k1Root := tree.Root()
+		tree.AddLeaf([]byte("d"))
+		require.NoError(t, tree.RotateKey("k2", []byte("key2")))
+
+		require.NoError(t, tree.RollbackTo(1))
+		fresh, err := New(data, WithHMACKey("k2", []byte("key2")))
+		require.NoError(t, err)
+		require.Equal(t, fresh.Root(), tree.Root())
+		require.Equal(t, tree.Root(), event.ToRoot)
+		require.NotEqual(t, k1Root, tree.Root())
+
+		proof, err := tree.GenerateEpochProof([]byte("b"))
+		require.NoError(t, err)
+		keys := map[string][]byte{"k2": []byte("key2")}
+		roots := map[string][]byte{"k2": tree.Root()}
+		require.NoError(t, VerifyEpochProof(keys, roots, []byte("b"), proof))
+	})
+
+	t.Run("should retain versions by the retention policy and pins", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a")}, WithVersionHistory(RetentionPolicy{KeepLast: 2, KeepEvery: 3}))
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			require.NoError(t, tree.AddLeaf([]byte(fmt.Sprint(i))))
+		}
+		require.NoError(t, tree.PinVersion(2))
+		require.ErrorIs(t, tree.PinVersion(7), ErrUnknownVersion)
+		for i := 2; i < 4; i++ {
+			require.NoError(t, tree.AddLeaf([]byte(fmt.Sprint(i))))
+		}
+		require.Equal(t, []int{1, 2, 4, 5}, tree.Versions())
+
+		tree.UnpinVersion(2)
+		require.NoError(t, tree.AddLeaf([]byte("4")))
+		require.Equal(t, []int{1, 4, 5, 6}, tree.Versions())
+
+		require.NoError(t, tree.RollbackTo(1))
+		require.Len(t, tree.Leaves(), 1)
+	})
+
+	t.Run("should drop idempotency records", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a")}, WithVersionHistory(RetentionPolicy{KeepLast: 2}))
+		require.NoError(t, err)
+		_, _, err = tree.AppendIdempotent("req-1", []byte("b"))
+		require.NoError(t, err)
+		require.NoError(t, tree.RollbackTo(1))
+
+		index, replayed, err := tree.AppendIdempotent("req-1", []byte("b"))
+		require.NoError(t, err)
+		require.False(t, replayed)
+		require.Equal(t, 1, index)
+	})
+
+	t.Run("should keep retained versions intact when zeroizing", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a")}, WithVersionHistory(RetentionPolicy{KeepLast: 2}), WithZeroizeOnDrop())
+		require.NoError(t, err)
+		require.NoError(t, tree.UpdateLeaf([]byte("a"), []byte("b")))
+		require.NoError(t, tree.RollbackTo(1))
+		require.Equal(t, []byte("a"), tree.Leaves()[0].Data)
+		require.NoError(t, tree.Close())
+	})
+}
@@ -0,0 +1,62 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+)
+
+var ErrIdempotencyConflict = errors.New("idempotency ID was already used with different data")
+
+// appendRecord is the leaf appended under an idempotency ID
+type appendRecord struct {
+	node  *Node
+	hash  []byte
+	index int
+}
+
+// AppendIdempotent appends a leaf keyed by a caller-provided idempotency ID,
+// such as a request ID, and returns its index, so retried network appends
+// don't create duplicate leaves. A replay with the same ID and data appends
+// nothing and returns the originally assigned index with replayed set; reusing
+// an ID for different data returns ErrIdempotencyConflict. In trees with
+// sorted leaves the index is the leaf's current position.
+func (m *MerkleTree) AppendIdempotent(id string, data []byte) (index int, replayed bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, err := m.newLeaf(data)
+	if err != nil {
+		return 0, false, err
+	}
+	hash := m.hashLeaf(data)
+
+	if r, ok := m.appendIDs[id]; ok {
+		if !bytes.Equal(r.hash, hash) {
+			return 0, false, ErrIdempotencyConflict
+		}
+		return m.recordIndex(r), true, nil
+	}
+
+	r := appendRecord{node: node, hash: hash, index: len(m.leafs) + len(m.pending)}
+	if m.appendIDs == nil {
+		m.appendIDs = map[string]appendRecord{}
+	}
+	m.appendIDs[id] = r
+	m.pending = append(m.pending, node)
+	m.scheduleRebuild()
+	return m.recordIndex(r), false, nil
+}
+
+// recordIndex returns the index of an appended leaf; m.mu must be held
+func (m *MerkleTree) recordIndex(r appendRecord) int {
+	if !m.sortLeaves {
+		return r.index
+	}
+	m.flushPending()
+	for i, leaf := range m.leafs {
+		if leaf == r.node {
+			return i
+		}
+	}
+	return r.index
+}
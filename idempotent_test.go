@@ -0,0 +1,69 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AppendIdempotent(t *testing.T) {
+	t.Run("should return the original index on replays", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a")})
+		require.NoError(t, err)
+
+		index, replayed, err := tree.AppendIdempotent("req-1", []byte("b"))
+		require.NoError(t, err)
+		require.Equal(t, 1, index)
+		require.False(t, replayed)
+		root := tree.Root()
+
+		index, replayed, err = tree.AppendIdempotent("req-1", []byte("b"))
+		require.NoError(t, err)
+		require.Equal(t, 1, index)
+		require.True(t, replayed)
+		require.Equal(t, root, tree.Root())
+		require.Len(t, tree.Leaves(), 2)
+
+		index, _, err = tree.AppendIdempotent("req-2", []byte("b"))
+		require.NoError(t, err)
+		require.Equal(t, 2, index)
+	})
+
+	t.Run("should reject an ID reused for different data", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a")})
+		require.NoError(t, err)
+		_, _, err = tree.AppendIdempotent("req-1", []byte("b"))
+		require.NoError(t, err)
+		_, _, err = tree.AppendIdempotent("req-1", []byte("c"))
+		require.ErrorIs(t, err, ErrIdempotencyConflict)
+	})
+
+	t.Run("should assign indices to pending leaves", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a")}, WithAsyncRebuild())
+		require.NoError(t, err)
+		first, _, err := tree.AppendIdempotent("req-1", []byte("b"))
+		require.NoError(t, err)
+		second, _, err := tree.AppendIdempotent("req-2", []byte("c"))
+		require.NoError(t, err)
+		require.Equal(t, []int{1, 2}, []int{first, second})
+
+		tree.Flush()
+		proof, err := tree.GenerateProofAt(second)
+		require.NoError(t, err)
+		require.True(t, tree.VerifyData([]byte("c"), proof))
+	})
+
+	t.Run("should return the current position in sorted trees", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a"), []byte("b")}, WithSortedLeaves())
+		require.NoError(t, err)
+		index, _, err := tree.AppendIdempotent("req-1", []byte("c"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("c"), tree.Leaves()[index].Data)
+
+		tree.AddLeaf([]byte("d"))
+		index, replayed, err := tree.AppendIdempotent("req-1", []byte("c"))
+		require.NoError(t, err)
+		require.True(t, replayed)
+		require.Equal(t, []byte("c"), tree.Leaves()[index].Data)
+	})
+}
@@ -0,0 +1,124 @@
+package merkle
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+var ErrNoValidSignature = errors.New("no valid DSSE signature for the key")
+
+const (
+	// InTotoStatementType is the _type of in-toto v1 statements
+	InTotoStatementType = "https://in-toto.io/Statement/v1"
+	// InTotoPayloadType is the DSSE payload type of in-toto statements
+	InTotoPayloadType = "application/vnd.in-toto+json"
+	// RootPredicateType is the predicate type of statements about a Merkle root
+	RootPredicateType = "https://github.com/chakra-guy/merkle/root/v1"
+)
+
+// InTotoSubject is an artifact a statement is about, with its digests by algorithm
+type InTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// InTotoStatement is an in-toto v1 statement
+type InTotoStatement struct {
+	Type          string          `json:"_type"`
+	Subject       []InTotoSubject `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// RootPredicate is the predicate of a statement committing to a Merkle root
+type RootPredicate struct {
+	Root     string            `json:"root"`
+	TreeSize int               `json:"treeSize"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// DSSEEnvelope is a Dead Simple Signing Envelope
+type DSSEEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     []byte          `json:"payload"`
+	Signatures  []DSSESignature `json:"signatures"`
+}
+
+// DSSESignature is a signature over an envelope's payload by the key with KeyID
+type DSSESignature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   []byte `json:"sig"`
+}
+
+// RootStatement creates an in-toto statement committing the subjects to the
+// root of the tree, with optional metadata such as the builder
+func (m *MerkleTree) RootStatement(subjects []InTotoSubject, metadata map[string]string) (InTotoStatement, error) {
+	predicate, err := json.Marshal(RootPredicate{Root: hex.EncodeToString(m.Root()), TreeSize: len(m.leafs), Metadata: metadata})
+	if err != nil {
+		return InTotoStatement{}, err
+	}
+	return InTotoStatement{
+		Type:          InTotoStatementType,
+		Subject:       subjects,
+		PredicateType: RootPredicateType,
+		Predicate:     predicate,
+	}, nil
+}
+
+// SignInToto wraps a statement in a DSSE envelope signed with an Ed25519 key
+func SignInToto(statement InTotoStatement, keyID string, key ed25519.PrivateKey) (DSSEEnvelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return DSSEEnvelope{}, err
+	}
+	return SignDSSE(InTotoPayloadType, payload, keyID, key), nil
+}
+
+// SignDSSE creates an envelope over the payload signed with an Ed25519 key
+func SignDSSE(payloadType string, payload []byte, keyID string, key ed25519.PrivateKey) DSSEEnvelope {
+	sig := ed25519.Sign(key, dssePAE(payloadType, payload))
+	return DSSEEnvelope{
+		PayloadType: payloadType,
+		Payload:     payload,
+		Signatures:  []DSSESignature{{KeyID: keyID, Sig: sig}},
+	}
+}
+
+// Verify checks that the envelope has a valid signature by the key with the
+// given ID; an empty ID accepts signatures without a key ID
+func (e DSSEEnvelope) Verify(keyID string, pub ed25519.PublicKey) error {
+	pae := dssePAE(e.PayloadType, e.Payload)
+	for _, sig := range e.Signatures {
+		if sig.KeyID == keyID && ed25519.Verify(pub, pae, sig.Sig) {
+			return nil
+		}
+	}
+	return ErrNoValidSignature
+}
+
+// InTotoStatement decodes the envelope's payload as an in-toto statement
+func (e DSSEEnvelope) InTotoStatement() (InTotoStatement, error) {
+	var s InTotoStatement
+	if e.PayloadType != InTotoPayloadType {
+		return s, ErrMalformed
+	}
+	if err := json.Unmarshal(e.Payload, &s); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// dssePAE is the DSSE pre-authentication encoding that signatures cover
+func dssePAE(payloadType string, payload []byte) []byte {
+	pae := []byte("DSSEv1 ")
+	pae = strconv.AppendInt(pae, int64(len(payloadType)), 10)
+	pae = append(pae, ' ')
+	pae = append(pae, payloadType...)
+	pae = append(pae, ' ')
+	pae = strconv.AppendInt(pae, int64(len(payload)), 10)
+	pae = append(pae, ' ')
+	return append(pae, payload...)
+}
@@ -0,0 +1,67 @@
+package merkle
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RootStatement(t *testing.T) {
+	tree, err := New([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	require.NoError(t, err)
+	subjects := []InTotoSubject{{Name: "release.tar", Digest: map[string]string{"sha256": "ab12"}}}
+
+	t.Run("should commit to the root in the predicate", func(t *testing.T) {
+		s, err := tree.RootStatement(subjects, map[string]string{"builder": "ci"})
+		require.NoError(t, err)
+		require.Equal(t, InTotoStatementType, s.Type)
+		require.Equal(t, RootPredicateType, s.PredicateType)
+
+		var p RootPredicate
+		require.NoError(t, json.Unmarshal(s.Predicate, &p))
+		require.Equal(t, RootPredicate{Root: hex.EncodeToString(tree.Root()), TreeSize: 3, Metadata: map[string]string{"builder": "ci"}}, p)
+	})
+}
+
+func Test_SignInToto(t *testing.T) {
+	tree, err := New([][]byte{[]byte("a"), []byte("b")})
+	require.NoError(t, err)
+	s, err := tree.RootStatement(nil, nil)
+	require.NoError(t, err)
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	env, err := SignInToto(s, "release-key", priv)
+	require.NoError(t, err)
+
+	t.Run("should verify after a round trip", func(t *testing.T) {
+		b, err := json.Marshal(env)
+		require.NoError(t, err)
+		var decoded DSSEEnvelope
+		require.NoError(t, json.Unmarshal(b, &decoded))
+
+		require.NoError(t, decoded.Verify("release-key", pub))
+		statement, err := decoded.InTotoStatement()
+		require.NoError(t, err)
+		require.Equal(t, s.PredicateType, statement.PredicateType)
+	})
+
+	t.Run("should return error for other keys or tampered payloads", func(t *testing.T) {
+		require.ErrorIs(t, env.Verify("other-key", pub), ErrNoValidSignature)
+
+		tampered := env
+		tampered.PayloadType = "text/plain"
+		require.ErrorIs(t, tampered.Verify("release-key", pub), ErrNoValidSignature)
+		_, err := tampered.InTotoStatement()
+		require.ErrorIs(t, err, ErrMalformed)
+	})
+}
+
+func Test_dssePAE(t *testing.T) {
+	t.Run("should match the DSSE specification", func(t *testing.T) {
+		require.Equal(t, "DSSEv1 29 http://example.com/HelloWorld 11 hello world", string(dssePAE("http://example.com/HelloWorld", []byte("hello world"))))
+	})
+}
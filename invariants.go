@@ -0,0 +1,78 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+var ErrInvariant = errors.New("tree invariant violated")
+
+// CheckInvariants verifies the internal consistency of a tree, for downstream
+// integration tests and fuzzing harnesses: every interior node links to its
+// children and hashes them, stored leaf data hashes to its leaf, the depth
+// matches the leaf count, and the right-edge frontier of a compact appender
+// over the leaves reaches the same root. Pending leaves are flushed first.
+func CheckInvariants(m *MerkleTree) error {
+	m.Flush()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.leafs) == 0 || len(m.levels) == 0 {
+		return fmt.Errorf("%w: empty tree", ErrInvariant)
+	}
+	if depth := bits.Len(uint(len(m.leafs)-1)) + 1; len(m.levels) != depth {
+		return fmt.Errorf("%w: %d levels for %d leaves, expected %d", ErrInvariant, len(m.levels), len(m.leafs), depth)
+	}
+
+	for i, leaf := range m.leafs {
+		if m.levels[0][i] != leaf {
+			return fmt.Errorf("%w: leaf %d is not on the leaf level", ErrInvariant, i)
+		}
+		if leaf.data != nil && !bytes.Equal(leaf.hash, m.hashBlindedLeaf(leaf.blind, leaf.data)) {
+			return fmt.Errorf("%w: leaf %d does not hash its data", ErrInvariant, i)
+		}
+	}
+
+	for k, level := range m.levels[:len(m.levels)-1] {
+		parents := m.levels[k+1]
+		if len(parents) != (len(level)+1)/2 {
+			return fmt.Errorf("%w: level %d has %d nodes, expected %d", ErrInvariant, k+1, len(parents), (len(level)+1)/2)
+		}
+		for j, parent := range parents {
+			left, right := level[2*j], level[2*j]
+			if 2*j+1 < len(level) {
+				right = level[2*j+1]
+			} else if m.promoteOdd {
+				if parent != left {
+					return fmt.Errorf("%w: level %d node %d is not promoted", ErrInvariant, k, 2*j)
+				}
+				continue
+			}
+
+			switch {
+			case parent.left != left || parent.right != right:
+				return fmt.Errorf("%w: level %d node %d has the wrong children", ErrInvariant, k+1, j)
+			case left.parent != parent || right.parent != parent:
+				return fmt.Errorf("%w: level %d node %d is not linked to its parent", ErrInvariant, k, 2*j)
+			case !bytes.Equal(parent.hash, m.hashNode(left.hash, right.hash)):
+				return fmt.Errorf("%w: level %d node %d does not hash its children", ErrInvariant, k+1, j)
+			}
+		}
+	}
+
+	if top := m.levels[len(m.levels)-1]; len(top) != 1 || top[0] != m.root || m.root.parent != nil {
+		return fmt.Errorf("%w: the last level does not hold the root", ErrInvariant)
+	}
+
+	frontier := &CompactAppender{m: m, h: m.newHash()}
+	for _, leaf := range m.leafs {
+		frontier.AppendHash(leaf.hash)
+	}
+	if root, err := frontier.Root(); err != nil || !bytes.Equal(root, m.root.hash) {
+		return fmt.Errorf("%w: the frontier does not reach the root", ErrInvariant)
+	}
+
+	return nil
+}
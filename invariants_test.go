@@ -0,0 +1,72 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CheckInvariants(t *testing.T) {
+	var data [][]byte
+	for i := 0; i < 11; i++ {
+		data = append(data, []byte(fmt.Sprint(i)))
+	}
+
+	t.Run("should hold for every tree shape", func(t *testing.T) {
+		for _, opts := range [][]Option{
+			nil,
+			{WithOddNodePromotion()},
+			{WithRFC6962()},
+			{WithSortedLeaves()},
+			{WithBlinding()},
+			{WithoutStoringData()},
+			{WithHMACKey("k1", []byte("key"))},
+			{WithScheme(SchemeOZSorted)},
+		} {
+			for n := 1; n <= len(data); n++ {
+				tree, err := New(data[:n], opts...)
+				require.NoError(t, err)
+				require.NoError(t, CheckInvariants(tree))
+			}
+		}
+	})
+
+	t.Run("should hold after changes", func(t *testing.T) {
+		tree, err := New(data[:3], WithRebuildDebounce(time.Hour))
+		require.NoError(t, err)
+		tree.AddLeaves(data[3:7])
+		tree.AddLeaf(data[7])
+		require.NoError(t, tree.UpdateLeaf(data[1], []byte("x")))
+		require.NoError(t, CheckInvariants(tree))
+	})
+
+	t.Run("should detect corrupted node hashes", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		tree.levels[1][2].hash = tree.levels[1][3].hash
+		require.ErrorIs(t, CheckInvariants(tree), ErrInvariant)
+	})
+
+	t.Run("should detect corrupted leaves", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		tree.leafs[4].data = []byte("x")
+		require.ErrorIs(t, CheckInvariants(tree), ErrInvariant)
+	})
+
+	t.Run("should detect broken parent links", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		tree.leafs[0].parent = tree.leafs[1].parent.parent
+		require.ErrorIs(t, CheckInvariants(tree), ErrInvariant)
+	})
+
+	t.Run("should detect a stale root", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		tree.root = tree.levels[1][0]
+		require.ErrorIs(t, CheckInvariants(tree), ErrInvariant)
+	})
+}
@@ -0,0 +1,74 @@
+package merkle
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidJWS = errors.New("invalid JWS proof envelope")
+
+// JWSType is the typ header of proof bundle envelopes
+const JWSType = "merkle-proof+json"
+
+// jwsHeader is the protected header of a proof bundle envelope
+type jwsHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// SignJWS wraps the bundle in a JWS compact serialization signed with an
+// Ed25519 key (alg EdDSA), so any JOSE library can validate it
+func (b ProofBundle) SignJWS(keyID string, key ed25519.PrivateKey) (string, error) {
+	header, err := json.Marshal(jwsHeader{Alg: "EdDSA", Kid: keyID, Typ: JWSType})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(b)
+	if err != nil {
+		return "", err
+	}
+
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return input + "." + base64.RawURLEncoding.EncodeToString(ed25519.Sign(key, []byte(input))), nil
+}
+
+// ParseJWSBundle verifies a JWS proof envelope with the key named by its kid
+// header and returns the bundle; only the EdDSA algorithm is accepted
+func ParseJWSBundle(token string, keys map[string]ed25519.PublicKey) (ProofBundle, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ProofBundle{}, ErrInvalidJWS
+	}
+
+	var header jwsHeader
+	if err := decodeJWSPart(parts[0], &header); err != nil || header.Alg != "EdDSA" {
+		return ProofBundle{}, ErrInvalidJWS
+	}
+	pub, ok := keys[header.Kid]
+	if !ok {
+		return ProofBundle{}, ErrUnknownKey
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !ed25519.Verify(pub, []byte(parts[0]+"."+parts[1]), sig) {
+		return ProofBundle{}, ErrInvalidSignature
+	}
+
+	var b ProofBundle
+	if err := decodeJWSPart(parts[1], &b); err != nil {
+		return ProofBundle{}, ErrInvalidJWS
+	}
+	return b, nil
+}
+
+// decodeJWSPart decodes a base64url-encoded JSON part
+func decodeJWSPart(part string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,63 @@
+package merkle
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SignJWS(t *testing.T) {
+	tree, err := New([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	require.NoError(t, err)
+	bundle, err := tree.GenerateBundle("tree-1", []byte("b"))
+	require.NoError(t, err)
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	keys := map[string]ed25519.PublicKey{"k1": pub}
+
+	token, err := bundle.SignJWS("k1", priv)
+	require.NoError(t, err)
+
+	t.Run("should use standard headers", func(t *testing.T) {
+		header, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+		require.NoError(t, err)
+		var h map[string]string
+		require.NoError(t, json.Unmarshal(header, &h))
+		require.Equal(t, map[string]string{"alg": "EdDSA", "kid": "k1", "typ": JWSType}, h)
+	})
+
+	t.Run("should verify and decode the bundle", func(t *testing.T) {
+		decoded, err := ParseJWSBundle(token, keys)
+		require.NoError(t, err)
+		require.Equal(t, bundle.TreeID, decoded.TreeID)
+		require.NoError(t, VerifyAcross(map[string][]byte{"tree-1": tree.Root()}, []ProofBundle{decoded}))
+	})
+
+	t.Run("should return error for unknown keys", func(t *testing.T) {
+		_, err := ParseJWSBundle(token, map[string]ed25519.PublicKey{})
+		require.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("should return error for tampered envelopes", func(t *testing.T) {
+		parts := strings.Split(token, ".")
+		other, err := ProofBundle{TreeID: "tree-2"}.SignJWS("k1", priv)
+		require.NoError(t, err)
+
+		_, err = ParseJWSBundle(parts[0]+"."+strings.Split(other, ".")[1]+"."+parts[2], keys)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("should return error for other algorithms", func(t *testing.T) {
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"k1"}`))
+		parts := strings.Split(token, ".")
+		_, err := ParseJWSBundle(header+"."+parts[1]+".", keys)
+		require.ErrorIs(t, err, ErrInvalidJWS)
+		_, err = ParseJWSBundle("a.b", keys)
+		require.ErrorIs(t, err, ErrInvalidJWS)
+	})
+}
@@ -0,0 +1,132 @@
+package merkle
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// K12ChunkSize is the chunk size of the KangarooTwelve tree
+const K12ChunkSize = 8192
+
+const (
+	turboSHAKE128Rate = 168
+	k12ChainingSize   = 32
+)
+
+// K12 computes the KangarooTwelve digest of data under the customization
+// string, as specified in RFC 9861. Inputs longer than a chunk use its
+// built-in tree: each chunk after the first is hashed into a chaining value,
+// and the final node hashes the first chunk followed by all chaining values.
+func K12(data, custom []byte, size int) []byte {
+	s := make([]byte, 0, len(data)+len(custom)+9)
+	s = append(append(append(s, data...), custom...), k12LengthEncode(uint64(len(custom)))...)
+	if len(s) <= K12ChunkSize {
+		return turboSHAKE128(s, 0x07, size)
+	}
+
+	final := append(append([]byte{}, s[:K12ChunkSize]...), 0x03, 0, 0, 0, 0, 0, 0, 0)
+	chunks := 0
+	for rest := s[K12ChunkSize:]; len(rest) > 0; chunks++ {
+		chunk := rest[:min(K12ChunkSize, len(rest))]
+		final = append(final, turboSHAKE128(chunk, 0x0b, k12ChainingSize)...)
+		rest = rest[len(chunk):]
+	}
+	final = append(append(final, k12LengthEncode(uint64(chunks))...), 0xff, 0xff)
+	return turboSHAKE128(final, 0x06, size)
+}
+
+// k12LengthEncode encodes x as its big endian bytes without leading zeros,
+// followed by their count
+func k12LengthEncode(x uint64) []byte {
+	var b [9]byte
+	binary.BigEndian.PutUint64(b[:8], x)
+	n := (bits.Len64(x) + 7) / 8
+	b[8] = byte(n)
+	return b[8-n:]
+}
+
+// turboSHAKE128 computes TurboSHAKE128 of the message with the domain
+// separation byte, a sponge over the 12 round Keccak permutation
+func turboSHAKE128(msg []byte, domain byte, size int) []byte {
+	return keccakSponge(msg, domain, size, turboSHAKE128Rate, 12)
+}
+
+// keccakSponge absorbs the message padded with the domain byte and squeezes
+// size bytes, using Keccak-p[1600] reduced to the given number of rounds
+func keccakSponge(msg []byte, domain byte, size, rate, rounds int) []byte {
+	var state [25]uint64
+	var block [200]byte
+
+	absorb := func() {
+		for i := 0; i < rate/8; i++ {
+			state[i] ^= binary.LittleEndian.Uint64(block[i*8:])
+		}
+		keccakP1600(&state, rounds)
+	}
+	for ; len(msg) >= rate; msg = msg[rate:] {
+		copy(block[:rate], msg)
+		absorb()
+	}
+	block = [200]byte{}
+	copy(block[:], msg)
+	block[len(msg)] ^= domain
+	block[rate-1] ^= 0x80
+	absorb()
+
+	out := make([]byte, 0, size)
+	for {
+		for i := 0; i < rate/8; i++ {
+			binary.LittleEndian.PutUint64(block[i*8:], state[i])
+		}
+		out = append(out, block[:min(rate, size-len(out))]...)
+		if len(out) == size {
+			return out
+		}
+		keccakP1600(&state, rounds)
+	}
+}
+
+var keccakRoundConstants = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
+	0x000000000000808b, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008a, 0x0000000000000088, 0x0000000080008009, 0x000000008000000a,
+	0x000000008000808b, 0x800000000000008b, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800a, 0x800000008000000a,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+var (
+	keccakRotations = [24]int{1, 3, 6, 10, 15, 21, 28, 36, 45, 55, 2, 14, 27, 41, 56, 8, 25, 43, 62, 18, 39, 61, 20, 44}
+	keccakLanes     = [24]int{10, 7, 11, 17, 18, 3, 5, 16, 8, 21, 24, 4, 15, 23, 19, 13, 12, 2, 20, 14, 22, 9, 6, 1}
+)
+
+// keccakP1600 applies the last rounds of the 24 round Keccak-f[1600]
+// permutation; x/crypto/sha3 does not expose reduced round variants
+func keccakP1600(a *[25]uint64, rounds int) {
+	for round := 24 - rounds; round < 24; round++ {
+		var c [5]uint64
+		for x := 0; x < 5; x++ {
+			c[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			d := c[(x+4)%5] ^ bits.RotateLeft64(c[(x+1)%5], 1)
+			for y := 0; y < 25; y += 5 {
+				a[y+x] ^= d
+			}
+		}
+
+		lane := a[1]
+		for i, j := range keccakLanes {
+			a[j], lane = bits.RotateLeft64(lane, keccakRotations[i]), a[j]
+		}
+
+		for y := 0; y < 25; y += 5 {
+			row := [5]uint64{a[y], a[y+1], a[y+2], a[y+3], a[y+4]}
+			for x := 0; x < 5; x++ {
+				a[y+x] = row[x] ^ ^row[(x+1)%5]&row[(x+2)%5]
+			}
+		}
+
+		a[0] ^= keccakRoundConstants[round]
+	}
+}
@@ -0,0 +1,62 @@
+package merkle
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+// k12Pattern is the ptn(n) test message of RFC 9861
+func k12Pattern(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}
+
+func Test_K12(t *testing.T) {
+	t.Run("should match the RFC 9861 test vectors", func(t *testing.T) {
+		for n, expected := range map[int]string{
+			0:     "1ac2d450fc3b4205d19da7bfca1b37513c0803577ac7167f06fe2ce1f0ef39e5",
+			1:     "2bda92450e8b147f8a7cb629e784a058efca7cf7d8218e02d345dfaa65244a1f",
+			17:    "6bf75fa2239198db4772e36478f8e19b0f371205f6a9a93a273f51df37122888",
+			289:   "0c315ebcdedbf61426de7dcf8fb725d1e74675d7f5327a5067f367b108ecb67c",
+			4913:  "cb552e2ec77d9910701d578b457ddf772c12e322e4ee7fe417f92c758f0d59d0",
+			83521: "8701045e22205345ff4dda05555cbb5c3af1a771c2b89baef37db43d9998b9fe",
+		} {
+			require.Equal(t, expected, hex.EncodeToString(K12(k12Pattern(n), nil, 32)), "ptn(%d)", n)
+		}
+	})
+
+	t.Run("should hash across the chunk boundary", func(t *testing.T) {
+		for n, expected := range map[int]string{
+			8191:  "1b577636f723643e990cc7d6a659837436fd6a103626600eb8301cd1dbe553d6",
+			8192:  "48f256f6772f9edfb6a8b661ec92dc93b95ebd05a08a17b39ae3490870c926c3",
+			8193:  "bb66fe72eaea5179418d5295ee1344854d8ad7f3fa17efcb467ec152341284cf",
+			16384: "82778f7f7234c83352e76837b721fbdbb5270b88010d84fa5ab0b61ec8ce0956",
+		} {
+			require.Equal(t, expected, hex.EncodeToString(K12(k12Pattern(n), nil, 32)), "ptn(%d)", n)
+		}
+	})
+
+	t.Run("should apply the customization string", func(t *testing.T) {
+		require.Equal(t, "e3bdc13183ef1db59225fc53ee37089ca00fc56ee57bf0c152905754fe611997", hex.EncodeToString(K12(k12Pattern(1), []byte("c"), 32)))
+		require.Equal(t, "f023242d66bba786adee4439fb123171473779e02d4dd78abaf6f50a07ec4947", hex.EncodeToString(K12(k12Pattern(8192), []byte("c"), 32)))
+	})
+
+	t.Run("should squeeze outputs longer than the rate", func(t *testing.T) {
+		long := K12(nil, nil, 400)
+		require.Len(t, long, 400)
+		require.Equal(t, K12(nil, nil, 32), long[:32])
+	})
+
+	t.Run("should match SHAKE128 with all 24 rounds", func(t *testing.T) {
+		msg := k12Pattern(500)
+		expected := make([]byte, 300)
+		sha3.ShakeSum128(expected, msg)
+		require.Equal(t, expected, keccakSponge(msg, 0x1f, 300, turboSHAKE128Rate, 24))
+	})
+}
@@ -0,0 +1,113 @@
+package merkle
+
+import (
+	"errors"
+)
+
+var (
+	ErrUnknownKey    = errors.New("unknown key ID")
+	ErrDataNotStored = errors.New("leaf data is not stored")
+	ErrTreeNotKeyed  = errors.New("tree is not keyed")
+)
+
+// EpochRoot is the root a keyed tree had while the key with KeyID was active
+type EpochRoot struct {
+	KeyID string
+	Root  []byte
+}
+
+// EpochProof is a proof tagged with the ID of the key its tree was hashed with
+type EpochProof struct {
+	KeyID string
+	Proof Proof
+}
+
+// WithHMACKey makes the tree keyed: every leaf and node hash is an HMAC under
+// key using the configured hash function, identified by keyID
+func WithHMACKey(keyID string, key []byte) Option {
+	return func(m *MerkleTree) {
+		m.keyID = keyID
+		m.hmacKey = m.owned(key)
+	}
+}
+
+// KeyID returns the ID of the key the tree is currently hashed with
+func (m *MerkleTree) KeyID() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.keyID
+}
+
+// RotateKey starts a new epoch: the current root is retained under the current
+// key ID and the whole tree is rebuilt under the new key. The leaf data must be
+// stored, since every leaf hash depends on the key.
+func (m *MerkleTree) RotateKey(keyID string, key []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.hmacKey == nil {
+		return ErrTreeNotKeyed
+	}
+	m.flushPending()
+	for _, leaf := range m.leafs {
+		if leaf.data == nil {
+			return ErrDataNotStored
+		}
+	}
+
+	m.epochs = append(m.epochs, EpochRoot{KeyID: m.keyID, Root: m.root.hash})
+	if m.zeroize {
+		clear(m.hmacKey)
+	}
+	m.keyID, m.hmacKey = keyID, m.owned(key)
+	for _, leaf := range m.leafs {
+		leaf.hash = m.hashBlindedLeaf(leaf.blind, leaf.data)
+	}
+	m.rebuild()
+
+	return nil
+}
+
+// EpochRoots returns the roots of all past epochs followed by the current one
+func (m *MerkleTree) EpochRoots() []EpochRoot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append(append([]EpochRoot{}, m.epochs...), EpochRoot{KeyID: m.keyID, Root: m.root.hash})
+}
+
+// GenerateEpochProof generates a proof for a given leaf tagged with the current
+// key ID, both read under the lock so a concurrent RotateKey cannot split them
+func (m *MerkleTree) GenerateEpochProof(data []byte) (EpochProof, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flushPending()
+	proof, err := m.GenerateProof(data)
+	if err != nil {
+		return EpochProof{}, err
+	}
+	return EpochProof{KeyID: m.keyID, Proof: proof}, nil
+}
+
+// VerifyEpochProof verifies a tagged proof for the given data, using the key
+// and the root of the epoch the proof is tagged with. Keys and roots are looked
+// up by key ID; opts give the rest of the hashing configuration.
+func VerifyEpochProof(keys, roots map[string][]byte, data []byte, p EpochProof, opts ...Option) error {
+	key, ok := keys[p.KeyID]
+	if !ok {
+		return ErrUnknownKey
+	}
+	root, ok := roots[p.KeyID]
+	if !ok {
+		return ErrUnknownRoot
+	}
+
+	m := newVerifier(opts...)
+	m.keyID, m.hmacKey = p.KeyID, key
+	hash, err := m.hashData(data)
+	if err != nil {
+		return err
+	}
+	if !m.provesRoot(m.newHash(), hash, p.Proof, root) {
+		return ErrInvalidProof
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package merkle
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithHMACKey(t *testing.T) {
+	t.Run("should hash with an HMAC under the key", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a")}, WithHMACKey("k1", []byte("secret")))
+		require.NoError(t, err)
+
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte("a"))
+		require.Equal(t, mac.Sum(nil), tree.Root())
+		require.Equal(t, "k1", tree.KeyID())
+	})
+}
+
+func Test_RotateKey(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	tree, err := New(data, WithHMACKey("k1", []byte("first")))
+	require.NoError(t, err)
+
+	oldProof, err := tree.GenerateEpochProof([]byte("b"))
+	require.NoError(t, err)
+	oldRoot := tree.Root()
+
+	require.NoError(t, tree.RotateKey("k2", []byte("second")))
+	newProof, err := tree.GenerateEpochProof([]byte("b"))
+	require.NoError(t, err)
+
+	keys := map[string][]byte{"k1": []byte("first"), "k2": []byte("second")}
+	roots := map[string][]byte{}
+	for _, epoch := range tree.EpochRoots() {
+		roots[epoch.KeyID] = epoch.Root
+	}
+
+	t.Run("should retain the old roots", func(t *testing.T) {
+		require.Equal(t, []EpochRoot{{KeyID: "k1", Root: oldRoot}, {KeyID: "k2", Root: tree.Root()}}, tree.EpochRoots())
+		require.NotEqual(t, oldRoot, tree.Root())
+	})
+
+	t.Run("should verify proofs from every epoch", func(t *testing.T) {
+		require.Equal(t, "k1", oldProof.KeyID)
+		require.NoError(t, VerifyEpochProof(keys, roots, []byte("b"), oldProof))
+		require.Equal(t, "k2", newProof.KeyID)
+		require.NoError(t, VerifyEpochProof(keys, roots, []byte("b"), newProof))
+	})
+
+	t.Run("should reject proofs tagged with the wrong epoch", func(t *testing.T) {
+		mislabeled := EpochProof{KeyID: "k2", Proof: oldProof.Proof}
+		require.ErrorIs(t, VerifyEpochProof(keys, roots, []byte("b"), mislabeled), ErrInvalidProof)
+	})
+
+	t.Run("should reject unknown key IDs", func(t *testing.T) {
+		unknown := EpochProof{KeyID: "k3", Proof: newProof.Proof}
+		require.ErrorIs(t, VerifyEpochProof(keys, roots, []byte("b"), unknown), ErrUnknownKey)
+	})
+
+	t.Run("should rotate while leaves are added concurrently", func(t *testing.T) {
+		tree, err := New(data, WithHMACKey("k0", []byte("zero")))
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 1; i <= 10; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				require.NoError(t, tree.AddLeaf([]byte(fmt.Sprint("leaf", i))))
+			}(i)
+			go func(i int) {
+				defer wg.Done()
+				require.NoError(t, tree.RotateKey(fmt.Sprint("k", i), []byte(fmt.Sprint("key", i))))
+			}(i)
+		}
+		wg.Wait()
+
+		tree.Flush()
+		require.Len(t, tree.Leaves(), len(data)+10)
+		require.Len(t, tree.EpochRoots(), 11)
+	})
+
+	t.Run("should tag proofs generated during rotations with their key", func(t *testing.T) {
+		tree, err := New(data, WithHMACKey("k0", []byte("key0")))
+		require.NoError(t, err)
+
+		keys := map[string][]byte{"k0": []byte("key0")}
+		proofs := make(chan EpochProof, 100)
+		var wg sync.WaitGroup
+		for i := 1; i <= 10; i++ {
+			keys[fmt.Sprint("k", i)] = []byte(fmt.Sprint("key", i))
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				require.NoError(t, tree.RotateKey(fmt.Sprint("k", i), []byte(fmt.Sprint("key", i))))
+			}(i)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					proof, err := tree.GenerateEpochProof([]byte("b"))
+					require.NoError(t, err)
+					proofs <- proof
+				}
+			}()
+		}
+		wg.Wait()
+		close(proofs)
+
+		roots := map[string][]byte{}
+		for _, epoch := range tree.EpochRoots() {
+			roots[epoch.KeyID] = epoch.Root
+		}
+		for proof := range proofs {
+			require.NoError(t, VerifyEpochProof(keys, roots, []byte("b"), proof))
+		}
+	})
+
+	t.Run("should return error when the tree cannot be rehashed", func(t *testing.T) {
+		unkeyed, err := New(data)
+		require.NoError(t, err)
+		require.ErrorIs(t, unkeyed.RotateKey("k", []byte("k")), ErrTreeNotKeyed)
+
+		hashesOnly, err := New(data, WithHMACKey("k1", []byte("first")), WithoutStoringData())
+		require.NoError(t, err)
+		require.ErrorIs(t, hashesOnly.RotateKey("k2", []byte("second")), ErrDataNotStored)
+	})
+}
@@ -0,0 +1,203 @@
+package merkle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidLeafURI    = errors.New("invalid leaf URI")
+	ErrAlgorithmMismatch = errors.New("root ID names a different hash algorithm")
+)
+
+const leafURIScheme = "merkle://"
+
+// LeafURI is a stable reference to a committed leaf by the ID of its root, the
+// size of its tree and its index, written "merkle://<root ID>/<size>/<index>",
+// such as "merkle://sha256:8e4f.../100/42". The size comes with the root rather
+// than from the resolver, so a fetched proof is bound to the index.
+type LeafURI struct {
+	Root     RootID
+	TreeSize int
+	Index    int
+}
+
+// ResolvedLeaf is a leaf fetched for a LeafURI: its data, the size of the tree
+// it was proven in and its proof
+type ResolvedLeaf struct {
+	Data     []byte
+	TreeSize int
+	Proof    Proof
+}
+
+// Resolver fetches the leaf at index in the tree with the given root, such as
+// from a local store, a proof server or a static site
+type Resolver interface {
+	Resolve(ctx context.Context, root RootID, index int) (ResolvedLeaf, error)
+}
+
+// LeafURI returns the URI of the leaf at index
+func (m *MerkleTree) LeafURI(index int) (LeafURI, error) {
+	if index < 0 || index >= len(m.leafs) {
+		return LeafURI{}, ErrOutOfRange
+	}
+	root, err := m.RootID()
+	if err != nil {
+		return LeafURI{}, err
+	}
+	return LeafURI{Root: root, TreeSize: len(m.leafs), Index: index}, nil
+}
+
+// ParseLeafURI parses a leaf URI
+func ParseLeafURI(s string) (LeafURI, error) {
+	rest, ok := strings.CutPrefix(s, leafURIScheme)
+	if !ok {
+		return LeafURI{}, fmt.Errorf("%w: %q", ErrInvalidLeafURI, s)
+	}
+	rest, index, ok := cutNumber(rest)
+	if !ok {
+		return LeafURI{}, fmt.Errorf("%w: bad index in %q", ErrInvalidLeafURI, s)
+	}
+	rest, size, ok := cutNumber(rest)
+	if !ok || index >= size {
+		return LeafURI{}, fmt.Errorf("%w: bad tree size in %q", ErrInvalidLeafURI, s)
+	}
+	root, err := ParseRootID(rest)
+	if err != nil {
+		return LeafURI{}, fmt.Errorf("%w: %v", ErrInvalidLeafURI, err)
+	}
+	return LeafURI{Root: root, TreeSize: size, Index: index}, nil
+}
+
+// cutNumber cuts the canonical non-negative number after the last slash of s
+func cutNumber(s string) (string, int, bool) {
+	slash := strings.LastIndexByte(s, '/')
+	if slash < 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(s[slash+1:])
+	if err != nil || n < 0 || strconv.Itoa(n) != s[slash+1:] {
+		return "", 0, false
+	}
+	return s[:slash], n, true
+}
+
+// String returns the URI
+func (u LeafURI) String() string {
+	return leafURIScheme + u.Root.String() + "/" + strconv.Itoa(u.TreeSize) + "/" + strconv.Itoa(u.Index)
+}
+
+// MarshalText encodes the URI
+func (u LeafURI) MarshalText() ([]byte, error) {
+	if _, err := u.Root.MarshalText(); err != nil {
+		return nil, err
+	}
+	if u.Index < 0 || u.Index >= u.TreeSize {
+		return nil, ErrInvalidLeafURI
+	}
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText decodes a URI
+func (u *LeafURI) UnmarshalText(b []byte) error {
+	parsed, err := ParseLeafURI(string(b))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+// Fetch resolves the leaf and returns its data once its proof verifies against
+// the root for the referenced index in a tree of the referenced size; opts give
+// the hashing options the tree was built with and must hash with the algorithm
+// the root ID names
+func (u LeafURI) Fetch(ctx context.Context, r Resolver, opts ...Option) ([]byte, error) {
+	m := newVerifier(opts...)
+	if name := m.HashAlgorithm(); name != u.Root.Algorithm {
+		return nil, fmt.Errorf("%w: %s, verifying with %q", ErrAlgorithmMismatch, u.Root.Algorithm, name)
+	}
+
+	leaf, err := r.Resolve(ctx, u.Root, u.Index)
+	if err != nil {
+		return nil, err
+	}
+	if leaf.TreeSize != u.TreeSize || !m.pathMatches(u.Index, u.TreeSize, leaf.Proof) {
+		return nil, fmt.Errorf("%w: not a proof for index %d", ErrInvalidProof, u.Index)
+	}
+	if !VerifyDataProof(u.Root.Digest, leaf.Data, leaf.Proof, opts...) {
+		return nil, ErrInvalidProof
+	}
+	return leaf.Data, nil
+}
+
+// pathMatches reports whether the sides of proof are those of the leaf at
+// index in a tree of size leaves, binding a verified proof to the index
+func (m *MerkleTree) pathMatches(index, size int, proof Proof) bool {
+	sides, err := proofSides(index, size, m.promoteOdd)
+	if err != nil || len(sides) != len(proof) {
+		return false
+	}
+	for i, side := range sides {
+		if proof[i].Side != side {
+			return false
+		}
+	}
+	return true
+}
+
+// TreeResolver resolves leaves of the trees added to it
+type TreeResolver struct {
+	mu    sync.RWMutex
+	trees map[string]*MerkleTree
+}
+
+// NewTreeResolver creates a resolver of the leaves of the given trees
+func NewTreeResolver(trees ...*MerkleTree) (*TreeResolver, error) {
+	r := &TreeResolver{trees: map[string]*MerkleTree{}}
+	for _, m := range trees {
+		if err := r.Add(m); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Add adds a tree by the ID of its current root
+func (r *TreeResolver) Add(m *MerkleTree) error {
+	root, err := m.RootID()
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trees[root.String()] = m
+	return nil
+}
+
+// Resolve returns the leaf at index in the tree with the given root
+func (r *TreeResolver) Resolve(ctx context.Context, root RootID, index int) (ResolvedLeaf, error) {
+	r.mu.RLock()
+	m, ok := r.trees[root.String()]
+	r.mu.RUnlock()
+	if !ok || !root.Matches(m) {
+		return ResolvedLeaf{}, fmt.Errorf("%w: %s", ErrUnknownRoot, root)
+	}
+
+	leaves := m.Leaves()
+	if index < 0 || index >= len(leaves) {
+		return ResolvedLeaf{}, ErrOutOfRange
+	}
+	if leaves[index].Data == nil {
+		return ResolvedLeaf{}, ErrDataNotStored
+	}
+	proof, err := m.GenerateProofAt(index)
+	if err != nil {
+		return ResolvedLeaf{}, err
+	}
+	return ResolvedLeaf{Data: leaves[index].Data, TreeSize: len(leaves), Proof: proof}, nil
+}
@@ -0,0 +1,135 @@
+package merkle
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// resolverFunc adapts a function to Resolver
+type resolverFunc func(ctx context.Context, root RootID, index int) (ResolvedLeaf, error)
+
+func (f resolverFunc) Resolve(ctx context.Context, root RootID, index int) (ResolvedLeaf, error) {
+	return f(ctx, root, index)
+}
+
+func Test_LeafURI(t *testing.T) {
+	var data [][]byte
+	for i := 0; i < 5; i++ {
+		data = append(data, []byte(fmt.Sprint("leaf", i)))
+	}
+
+	t.Run("should round trip through text", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		u, err := tree.LeafURI(3)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("merkle://sha256:%x/5/3", tree.Root()), u.String())
+
+		parsed, err := ParseLeafURI(u.String())
+		require.NoError(t, err)
+		require.Equal(t, u, parsed)
+
+		var decoded LeafURI
+		text, err := u.MarshalText()
+		require.NoError(t, err)
+		require.NoError(t, decoded.UnmarshalText(text))
+		require.Equal(t, u, decoded)
+
+		_, err = tree.LeafURI(5)
+		require.ErrorIs(t, err, ErrOutOfRange)
+	})
+
+	t.Run("should reject malformed URIs", func(t *testing.T) {
+		for _, s := range []string{
+			"", "https://sha256:00/2/1", "merkle://sha256:00", "merkle://sha256:00/1", "merkle://sha256:zz/2/1",
+			"merkle://sha256:00/2/-1", "merkle://sha256:00/2/01", "merkle://sha256:00/2/x", "merkle://sha256:00/x/1", "merkle://sha256:00/2/2",
+		} {
+			_, err := ParseLeafURI(s)
+			require.ErrorIs(t, err, ErrInvalidLeafURI, s)
+		}
+	})
+
+	t.Run("should fetch and verify every leaf", func(t *testing.T) {
+		for _, opts := range [][]Option{nil, {WithOddNodePromotion()}} {
+			tree, err := New(data, opts...)
+			require.NoError(t, err)
+			r, err := NewTreeResolver(tree)
+			require.NoError(t, err)
+			for i := range data {
+				u, err := tree.LeafURI(i)
+				require.NoError(t, err)
+				got, err := u.Fetch(context.Background(), r, opts...)
+				require.NoError(t, err)
+				require.Equal(t, data[i], got)
+			}
+		}
+	})
+
+	t.Run("should reject leaves from another index", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		r, err := NewTreeResolver(tree)
+		require.NoError(t, err)
+		swapped := resolverFunc(func(ctx context.Context, root RootID, index int) (ResolvedLeaf, error) {
+			return r.Resolve(ctx, root, index+1)
+		})
+
+		u, err := tree.LeafURI(1)
+		require.NoError(t, err)
+		_, err = u.Fetch(context.Background(), swapped)
+		require.ErrorIs(t, err, ErrInvalidProof)
+	})
+
+	t.Run("should bind the index to the tree size of the URI", func(t *testing.T) {
+		abc := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+		promoted, err := New(abc, WithOddNodePromotion())
+		require.NoError(t, err)
+		proof, err := promoted.GenerateProofAt(2)
+		require.NoError(t, err)
+		lying := resolverFunc(func(ctx context.Context, root RootID, index int) (ResolvedLeaf, error) {
+			return ResolvedLeaf{Data: []byte("c"), TreeSize: 2, Proof: proof}, nil
+		})
+		u, err := promoted.LeafURI(1)
+		require.NoError(t, err)
+		_, err = u.Fetch(context.Background(), lying, WithOddNodePromotion())
+		require.ErrorIs(t, err, ErrInvalidProof)
+
+		// the padding of an odd level must not prove a fourth leaf
+		paired, err := New(abc)
+		require.NoError(t, err)
+		r, err := NewTreeResolver(paired)
+		require.NoError(t, err)
+		u, err = paired.LeafURI(2)
+		require.NoError(t, err)
+		u.Index = 3
+		padded := resolverFunc(func(ctx context.Context, root RootID, index int) (ResolvedLeaf, error) {
+			leaf, err := r.Resolve(ctx, root, 2)
+			leaf.TreeSize = 4
+			return leaf, err
+		})
+		_, err = u.Fetch(context.Background(), padded)
+		require.ErrorIs(t, err, ErrInvalidProof)
+	})
+
+	t.Run("should fail for unknown roots and other algorithms", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		r, err := NewTreeResolver()
+		require.NoError(t, err)
+		u, err := tree.LeafURI(0)
+		require.NoError(t, err)
+
+		_, err = u.Fetch(context.Background(), r)
+		require.ErrorIs(t, err, ErrUnknownRoot)
+		_, err = u.Fetch(context.Background(), r, WithScheme(SchemeRFC6962))
+		require.ErrorIs(t, err, ErrAlgorithmMismatch)
+
+		require.NoError(t, r.Add(tree))
+		tree.AddLeaf([]byte("later"))
+		_, err = u.Fetch(context.Background(), r)
+		require.ErrorIs(t, err, ErrUnknownRoot)
+	})
+}
@@ -0,0 +1,79 @@
+package merkle
+
+import "bytes"
+
+// Leaf is a read-only view of a leaf node
+type Leaf struct {
+	Index int    `json:"index"`
+	Data  []byte `json:"data,omitempty"`
+	Hash  []byte `json:"hash"`
+	Value any    `json:"value,omitempty"`
+}
+
+// LeafPage is a page of consecutive leaves, optionally with their proofs, and
+// the size and root of the tree they were read from
+type LeafPage struct {
+	TreeSize int     `json:"tree_size"`
+	Root     []byte  `json:"root"`
+	Leaves   []Leaf  `json:"leaves"`
+	Proofs   []Proof `json:"proofs,omitempty"`
+	// HashAlgorithm is the tree's HashAlgorithm
+	HashAlgorithm string `json:"hash_algorithm,omitempty"`
+}
+
+// Leaves returns every leaf of the tree in order, with its attached value
+func (m *MerkleTree) Leaves() []Leaf {
+	leaves := make([]Leaf, len(m.leafs))
+	for i, leaf := range m.leafs {
+		leaves[i] = leaf.view(i)
+	}
+	return leaves
+}
+
+// view returns the leaf at the given index as a Leaf, with copies of its data
+// and hash so that callers cannot modify the tree through them
+func (n *Node) view(index int) Leaf {
+	return Leaf{Index: index, Data: bytes.Clone(n.data), Hash: bytes.Clone(n.hash), Value: n.value}
+}
+
+// GetLeaves returns up to count leaves starting at index start, with a proof
+// for each of them if withProofs is set
+func (m *MerkleTree) GetLeaves(start, count int, withProofs bool) (LeafPage, error) {
+	if start < 0 || start >= len(m.leafs) || count <= 0 {
+		return LeafPage{}, ErrOutOfRange
+	}
+	end := min(start+count, len(m.leafs))
+
+	page := LeafPage{TreeSize: len(m.leafs), Root: bytes.Clone(m.root.hash), Leaves: make([]Leaf, 0, end-start), HashAlgorithm: m.HashAlgorithm()}
+	for i, leaf := range m.leafs[start:end] {
+		page.Leaves = append(page.Leaves, leaf.view(start+i))
+		if withProofs {
+			proof, err := m.auditedProof(leaf)
+			if err != nil {
+				return LeafPage{}, err
+			}
+			page.Proofs = append(page.Proofs, proof)
+		}
+	}
+	return page, nil
+}
+
+// SetValue attaches an arbitrary value to the leaf holding the given data; the
+// value is not part of the leaf hash and survives updates of the leaf data
+func (m *MerkleTree) SetValue(data []byte, v any) error {
+	node := m.findLeaf(data)
+	if node == nil {
+		return ErrNotFoundData
+	}
+	node.value = v
+	return nil
+}
+
+// Value returns the value attached to the leaf holding the given data
+func (m *MerkleTree) Value(data []byte) (any, error) {
+	node := m.findLeaf(data)
+	if node == nil {
+		return nil, ErrNotFoundData
+	}
+	return node.value, nil
+}
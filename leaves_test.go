@@ -0,0 +1,111 @@
+package merkle
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SetValue(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	tree, err := New(data, WithHashFunction(mockHash))
+	require.NoError(t, err)
+
+	t.Run("should attach a value without changing the root", func(t *testing.T) {
+		oldRoot := tree.Root()
+		require.NoError(t, tree.SetValue([]byte("b"), "user-42"))
+		require.Equal(t, oldRoot, tree.Root())
+
+		v, err := tree.Value([]byte("b"))
+		require.NoError(t, err)
+		require.Equal(t, "user-42", v)
+	})
+
+	t.Run("should keep the value when the leaf is updated", func(t *testing.T) {
+		require.NoError(t, tree.UpdateLeaf([]byte("b"), []byte("b2")))
+		v, err := tree.Value([]byte("b2"))
+		require.NoError(t, err)
+		require.Equal(t, "user-42", v)
+	})
+
+	t.Run("should return error for non-existent leaf", func(t *testing.T) {
+		require.ErrorIs(t, tree.SetValue([]byte("e"), 1), ErrNotFoundData)
+		_, err := tree.Value([]byte("e"))
+		require.ErrorIs(t, err, ErrNotFoundData)
+	})
+}
+
+func Test_Leaves(t *testing.T) {
+	tree, err := New([][]byte{[]byte("a"), []byte("b")}, WithHashFunction(mockHash))
+	require.NoError(t, err)
+	require.NoError(t, tree.SetValue([]byte("a"), 7))
+
+	t.Run("should list leaves with their values", func(t *testing.T) {
+		leaves := tree.Leaves()
+		require.Len(t, leaves, 2)
+		require.Equal(t, Leaf{Index: 0, Data: []byte("a"), Hash: []byte("hash(a)"), Value: 7}, leaves[0])
+		require.Nil(t, leaves[1].Value)
+	})
+
+	t.Run("should not let callers modify the tree through the leaves", func(t *testing.T) {
+		leaves := tree.Leaves()
+		leaves[0].Data[0], leaves[0].Hash[0] = 'x', 'x'
+
+		page, err := tree.GetLeaves(0, 1, false)
+		require.NoError(t, err)
+		page.Leaves[0].Data[0], page.Leaves[0].Hash[0], page.Root[0] = 'x', 'x', 'x'
+
+		require.Equal(t, []byte("a"), tree.Leaves()[0].Data)
+		require.Equal(t, []byte("hash(a)"), tree.Leaves()[0].Hash)
+		require.True(t, tree.VerifyData([]byte("a"), mustProof(t, tree, []byte("a"))))
+	})
+
+	t.Run("should carry the value through serialized bundles", func(t *testing.T) {
+		bundle, err := tree.GenerateBundle("t", []byte("a"))
+		require.NoError(t, err)
+		b, err := json.Marshal(bundle)
+		require.NoError(t, err)
+
+		var decoded ProofBundle
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		require.EqualValues(t, 7, decoded.Value)
+	})
+}
+
+func Test_GetLeaves(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	tree, err := New(data, WithHashFunction(mockHash))
+	require.NoError(t, err)
+
+	t.Run("should return a page of leaves with the tree header", func(t *testing.T) {
+		page, err := tree.GetLeaves(1, 2, false)
+		require.NoError(t, err)
+		require.Equal(t, 5, page.TreeSize)
+		require.Equal(t, tree.Root(), page.Root)
+		require.Equal(t, []Leaf{
+			{Index: 1, Data: []byte("b"), Hash: []byte("hash(b)")},
+			{Index: 2, Data: []byte("c"), Hash: []byte("hash(c)")},
+		}, page.Leaves)
+		require.Nil(t, page.Proofs)
+	})
+
+	t.Run("should return proofs for the page", func(t *testing.T) {
+		page, err := tree.GetLeaves(3, 10, true)
+		require.NoError(t, err)
+		require.Len(t, page.Leaves, 2)
+		require.Len(t, page.Proofs, 2)
+		for i, leaf := range page.Leaves {
+			require.True(t, tree.VerifyProof(leaf.Hash, page.Proofs[i]))
+		}
+	})
+
+	t.Run("should return error for pages outside the tree", func(t *testing.T) {
+		_, err := tree.GetLeaves(5, 1, false)
+		require.ErrorIs(t, err, ErrOutOfRange)
+		_, err = tree.GetLeaves(-1, 1, false)
+		require.ErrorIs(t, err, ErrOutOfRange)
+		_, err = tree.GetLeaves(0, 0, false)
+		require.ErrorIs(t, err, ErrOutOfRange)
+	})
+}
@@ -1,21 +1,77 @@
-package main
+package merkle
 
 import (
 	"bytes"
-	"crypto/sha256"
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"hash"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+	"time"
 )
 
 var (
 	ErrEmptyData    = errors.New("data cannot be empty")
 	ErrNotFoundData = errors.New("data not found in the tree")
+	ErrOutOfRange   = errors.New("index out of range")
+	ErrMalformed    = errors.New("malformed proof")
 )
 
 type MerkleTree struct {
 	root   *Node
 	leafs  []*Node
+	levels [][]*Node // levels[0] are the leaves, the last level holds the root
 	hashFn func() hash.Hash
+
+	promoteOdd bool
+	dropData   bool
+	sortLeaves bool
+	capacity   int
+	arity      int
+	commitment VectorCommitment
+	scheme     *Scheme
+	truncate   int
+	swapOrder  bool
+	reverse    bool
+
+	preimageAudit bool
+	logger        *slog.Logger
+	blinding      bool
+	audited       bool
+	constantTime  bool
+	zeroize       bool
+	fipsOnly      bool
+	changelog     func([]NodeChange)
+	committed     [][][]byte // node hashes at the last commit, kept for the changelog
+	leafPrefix    []byte
+	nodePrefix    []byte
+	textEncoding  TextEncoding
+
+	personalization []byte
+
+	keyID   string
+	hmacKey []byte
+	epochs  []EpochRoot
+
+	appendIDs map[string]appendRecord // leaves appended by AppendIdempotent, by ID
+
+	version     int              // number of commits so far
+	history     *RetentionPolicy // the versions to retain, or nil to retain none
+	versions    []treeVersion    // retained versions, oldest first
+	pinned      map[int]bool     // versions retained regardless of the history policy
+	rollbackLog func(RollbackEvent)
+
+	mu       sync.Mutex // guards the tree against scheduled rebuilds
+	pending  []*Node    // leaves added since the last rebuild
+	debounce time.Duration
+	timer    *time.Timer
+	async    bool
+	ready    chan struct{} // closed once the pending leaves are rebuilt, nil when none are pending
 }
 
 type Node struct {
@@ -24,6 +80,8 @@ type Node struct {
 	right  *Node
 	hash   []byte
 	data   []byte
+	value  any
+	blind  []byte
 }
 
 type Option func(*MerkleTree)
@@ -44,29 +102,45 @@ type Proof []ProofElement
 
 // New creates a new Merkle tree from a list of data
 func New(data [][]byte, opts ...Option) (*MerkleTree, error) {
-	if len(data) == 0 {
+	return build(len(data), opts, func(m *MerkleTree, i int) (*Node, error) {
+		return m.newLeaf(data[i])
+	})
+}
+
+// build creates a tree of n leaves, the i-th made by leaf once the options
+// are applied and checked; New and Builder.Build both construct through it
+func build(n int, opts []Option, leaf func(m *MerkleTree, i int) (*Node, error)) (*MerkleTree, error) {
+	if n == 0 {
 		return nil, ErrEmptyData
 	}
 
-	m := &MerkleTree{hashFn: sha256.New}
-
-	for _, opt := range opts {
-		opt(m)
+	m := newVerifier(opts...)
+	if err := m.checkOptions(); err != nil {
+		return nil, err
 	}
 
-	for _, item := range data {
-		node := &Node{
-			hash: m.hash(item),
-			data: item,
+	m.leafs = make([]*Node, 0, max(n, m.capacity))
+	for i := 0; i < n; i++ {
+		node, err := leaf(m, i)
+		if err != nil {
+			return nil, err
 		}
 		m.leafs = append(m.leafs, node)
 	}
 
-	m.root = m.buildTree(m.leafs)
+	m.rebuild()
 
 	return m, nil
 }
 
+// checkOptions rejects options a binary tree cannot be built with
+func (m *MerkleTree) checkOptions() error {
+	if m.arity > 2 || m.commitment != nil {
+		return ErrArity
+	}
+	return m.checkFIPS()
+}
+
 // WithHashFunction sets a custom hash function for the MerkleTree
 func WithHashFunction(h func() hash.Hash) Option {
 	return func(m *MerkleTree) {
@@ -74,20 +148,374 @@ func WithHashFunction(h func() hash.Hash) Option {
 	}
 }
 
+// WithOddNodePromotion carries an unpaired node up to the next level unchanged,
+// instead of hashing it with a duplicate of itself
+func WithOddNodePromotion() Option {
+	return func(m *MerkleTree) {
+		m.promoteOdd = true
+	}
+}
+
+// WithoutStoringData keeps only the leaf hashes, discarding the leaf data once
+// hashed; leaves are then looked up by the hash of the data given
+func WithoutStoringData() Option {
+	return func(m *MerkleTree) {
+		m.dropData = true
+	}
+}
+
+// WithSortedLeaves orders the leaves by hash before every build, so the root
+// commits to the multiset of leaves regardless of insertion order
+func WithSortedLeaves() Option {
+	return func(m *MerkleTree) {
+		m.sortLeaves = true
+	}
+}
+
+// WithCapacity pre-allocates room for n leaves, avoiding repeated slice growth
+// when the leaf count is known ahead of a bulk load
+func WithCapacity(n int) Option {
+	return func(m *MerkleTree) {
+		m.capacity = n
+	}
+}
+
+// WithDomainSeparation prefixes every leaf and interior node hash input with the
+// given bytes, so leaves can never be confused for interior nodes
+func WithDomainSeparation(leafPrefix, nodePrefix []byte) Option {
+	return func(m *MerkleTree) {
+		m.leafPrefix = leafPrefix
+		m.nodePrefix = nodePrefix
+	}
+}
+
+// WithPersonalization mixes an application-specific domain string, such as
+// "myapp:v1", into every leaf and node hash, so roots from different
+// applications or schema versions can never collide or be replayed
+func WithPersonalization(domain string) Option {
+	return func(m *MerkleTree) {
+		// length-prefixed, so one domain can never be a prefix-shifted form of another
+		m.personalization = binary.AppendUvarint(nil, uint64(len(domain)))
+		m.personalization = append(m.personalization, domain...)
+	}
+}
+
+// WithHashTruncation keeps only the first n bytes of every leaf and node hash,
+// for protocols that commit to 16 or 20 byte hashes. Truncation weakens the
+// tree: a collision, such as two leaf sets with the same root, takes about
+// 2^(4n) work and a second preimage about 2^(8n), so n below 16 only suits
+// trees whose leaves no adversary chooses. n of 0 or at least the hash size
+// keeps the full hashes.
+func WithHashTruncation(n int) Option {
+	return func(m *MerkleTree) {
+		m.truncate = n
+	}
+}
+
+// WithReversedConcatenation hashes interior nodes as right || left, as some
+// chains do, instead of left || right
+func WithReversedConcatenation() Option {
+	return func(m *MerkleTree) {
+		m.swapOrder = true
+	}
+}
+
+// WithReversedHashBytes keeps every leaf and node hash in reversed byte order,
+// such as Bitcoin's display order: child hashes are reversed back before
+// being hashed, and each result is reversed before it is stored
+func WithReversedHashBytes() Option {
+	return func(m *MerkleTree) {
+		m.reverse = true
+	}
+}
+
+// Root returns the root hash of the tree
+func (m *MerkleTree) Root() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.root.hash
+}
+
 // GenerateProof generates a Merkle proof for a given leaf node
 func (m *MerkleTree) GenerateProof(data []byte) (Proof, error) {
-	var node *Node
-	for _, leaf := range m.leafs {
-		if bytes.Equal(leaf.data, data) {
-			node = leaf
-			break
-		}
+	if err := m.auditLeaf(data); err != nil {
+		return nil, err
+	}
+	node := m.findLeaf(data)
+	if node == nil {
+		return nil, ErrNotFoundData
 	}
 
+	return m.auditedProof(node)
+}
+
+// GenerateProofByHash generates a Merkle proof for the leaf with the given hash,
+// for callers that only retain leaf hashes
+func (m *MerkleTree) GenerateProofByHash(leafHash []byte) (Proof, error) {
+	node := m.findLeafByHash(leafHash)
 	if node == nil {
 		return nil, ErrNotFoundData
 	}
 
+	return m.auditedProof(node)
+}
+
+// GenerateProofAt generates a Merkle proof for the leaf at the given index
+func (m *MerkleTree) GenerateProofAt(index int) (Proof, error) {
+	if index < 0 || index >= len(m.leafs) {
+		return nil, ErrOutOfRange
+	}
+	return m.auditedProof(m.leafs[index])
+}
+
+// VerifyProof verifies a Merkle proof
+func (m *MerkleTree) VerifyProof(hash []byte, proof Proof) bool {
+	return bytes.Equal(m.rootFromProof(m.newHash(), hash, proof), m.root.hash)
+}
+
+// Verify verifies a Merkle proof like VerifyProof, but reports why it failed:
+// ErrInvalidSide for a malformed element, or ErrInvalidProof for a root mismatch
+func (m *MerkleTree) Verify(hash []byte, proof Proof) error {
+	for i, pe := range proof {
+		if !pe.Side.Valid() {
+			return fmt.Errorf("element %d: %w", i, ErrInvalidSide)
+		}
+	}
+	if !m.VerifyProof(hash, proof) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// VerifyData verifies a Merkle proof for given data
+func (m *MerkleTree) VerifyData(data []byte, proof Proof) bool {
+	hash, err := m.hashData(data)
+	if err != nil {
+		return false
+	}
+	if m.blinding {
+		node := m.findLeaf(data)
+		return node != nil && m.VerifyBlindedData(data, node.blind, proof)
+	}
+	return m.VerifyProof(hash, proof)
+}
+
+// VerifyDataProof verifies a Merkle proof for given data against a root,
+// without the tree; opts give the hashing options the tree was built with
+func VerifyDataProof(root, data []byte, proof Proof, opts ...Option) bool {
+	m := newVerifier(opts...)
+	hash, err := m.hashData(data)
+	return err == nil && m.provesRoot(m.newHash(), hash, proof, root)
+}
+
+// AddLeaf adds a new leaf node to the tree; leaves rejected by the preimage
+// audit are logged and left out with ErrInteriorPreimage
+func (m *MerkleTree) AddLeaf(data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	leaf, err := m.newLeaf(data)
+	if err != nil {
+		return err
+	}
+	m.pending = append(m.pending, leaf)
+	m.scheduleRebuild()
+	return nil
+}
+
+// AddLeaves adds a batch of leaf nodes to the tree with a single rebuild; if
+// the preimage audit rejects any of them, none are added
+func (m *MerkleTree) AddLeaves(data [][]byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	leaves := make([]*Node, len(data))
+	for i, item := range data {
+		leaf, err := m.newLeaf(item)
+		if err != nil {
+			return err
+		}
+		leaves[i] = leaf
+	}
+	m.pending = append(m.pending, leaves...)
+	m.scheduleRebuild()
+	return nil
+}
+
+// UpdateLeaf updates a leaf node and recalculates the tree
+func (m *MerkleTree) UpdateLeaf(oldData, newData []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flushPending()
+
+	updated, err := m.newLeaf(newData)
+	if err != nil {
+		return err
+	}
+	node := m.findLeaf(oldData)
+	if node == nil {
+		return ErrNotFoundData
+	}
+
+	if m.zeroize {
+		clear(node.data)
+		clear(node.blind)
+	}
+	node.data, node.hash, node.blind = updated.data, updated.hash, updated.blind
+	m.rebuild()
+	return nil
+}
+
+// newHash creates a hasher for the configured hash function, keyed when the
+// tree is keyed
+func (m *MerkleTree) newHash() hash.Hash {
+	if m.hmacKey != nil {
+		return hmac.New(m.hashFn, m.hmacKey)
+	}
+	return m.hashFn()
+}
+
+// hashLeaf computes the hash of a leaf value without auditing it; leaf data
+// given to the tree goes through newLeaf or hashData instead
+func (m *MerkleTree) hashLeaf(v []byte) []byte {
+	if m.scheme != nil {
+		return m.scheme.HashLeaf(v)
+	}
+	h := m.newHash()
+	h.Write(m.personalization)
+	h.Write(m.leafPrefix)
+	h.Write(v)
+	return m.finish(h.Sum(nil))
+}
+
+// hashNode computes the hash of an interior node from its children's hashes
+func (m *MerkleTree) hashNode(left, right []byte) []byte {
+	return m.hashNodeWith(m.newHash(), left, right)
+}
+
+// hashNodeWith computes the hash of an interior node, reusing the given hasher
+func (m *MerkleTree) hashNodeWith(h hash.Hash, left, right []byte) []byte {
+	if m.scheme != nil {
+		return m.scheme.HashNode(left, right)
+	}
+	if m.swapOrder {
+		left, right = right, left
+	}
+	h.Reset()
+	h.Write(m.personalization)
+	h.Write(m.nodePrefix)
+	h.Write(m.unreversed(left))
+	h.Write(m.unreversed(right))
+	return m.finish(h.Sum(nil))
+}
+
+// finish applies the configured truncation and byte order to a computed hash
+func (m *MerkleTree) finish(sum []byte) []byte {
+	if m.truncate > 0 && m.truncate < len(sum) {
+		sum = sum[:m.truncate:m.truncate]
+	}
+	if m.reverse {
+		slices.Reverse(sum)
+	}
+	return sum
+}
+
+// unreversed returns a stored hash in the byte order it was computed in
+func (m *MerkleTree) unreversed(hash []byte) []byte {
+	if !m.reverse {
+		return hash
+	}
+	hash = slices.Clone(hash)
+	slices.Reverse(hash)
+	return hash
+}
+
+// newLeaf creates a leaf node for the given data once the preimage audit
+// passes it; every tree builds its leaves from data here
+func (m *MerkleTree) newLeaf(data []byte) (*Node, error) {
+	if err := m.auditLeaf(data); err != nil {
+		return nil, err
+	}
+	node := &Node{}
+	if m.blinding {
+		node.blind = newBlind()
+	}
+	node.hash = m.hashBlindedLeaf(node.blind, data)
+	if !m.dropData {
+		node.data = m.owned(data)
+	}
+	return node, nil
+}
+
+// hashData computes the leaf hash of data once the preimage audit passes it,
+// for the trees and verifiers that keep only leaf hashes
+func (m *MerkleTree) hashData(data []byte) ([]byte, error) {
+	if err := m.auditLeaf(data); err != nil {
+		return nil, err
+	}
+	return m.hashLeaf(data), nil
+}
+
+// findLeaf returns the first leaf holding the given data, or nil. Trees that
+// don't store data are searched by the leaf hash instead.
+func (m *MerkleTree) findLeaf(data []byte) *Node {
+	if i := m.leafIndex(data); i >= 0 {
+		return m.leafs[i]
+	}
+	return nil
+}
+
+// leafIndex returns the index of the first leaf holding the given data, or -1
+func (m *MerkleTree) leafIndex(data []byte) int {
+	if m.dropData {
+		hash := m.hashLeaf(data)
+		if m.constantTime {
+			return m.scanLeafIndex(func(leaf *Node) int {
+				return subtle.ConstantTimeCompare(leaf.hash, hash)
+			})
+		}
+		for i, leaf := range m.leafs {
+			if bytes.Equal(leaf.hash, hash) {
+				return i
+			}
+		}
+		return -1
+	}
+	if m.constantTime {
+		return m.scanLeafIndex(func(leaf *Node) int {
+			return subtle.ConstantTimeCompare(leaf.data, data)
+		})
+	}
+	for i, leaf := range m.leafs {
+		if bytes.Equal(leaf.data, data) {
+			return i
+		}
+	}
+	return -1
+}
+
+// findLeafByHash returns the first leaf with the given hash, or nil
+func (m *MerkleTree) findLeafByHash(hash []byte) *Node {
+	if m.constantTime {
+		i := m.scanLeafIndex(func(leaf *Node) int {
+			return subtle.ConstantTimeCompare(leaf.hash, hash)
+		})
+		if i < 0 {
+			return nil
+		}
+		return m.leafs[i]
+	}
+	for _, leaf := range m.leafs {
+		if bytes.Equal(leaf.hash, hash) {
+			return leaf
+		}
+	}
+	return nil
+}
+
+// proofFor collects the sibling hashes on the path from a leaf to the root
+func (m *MerkleTree) proofFor(node *Node) Proof {
+	if m.constantTime {
+		return m.scanProof(m.nodeIndex(node))
+	}
 	var proof Proof
 	for node.parent != nil {
 		var pe ProofElement
@@ -99,56 +527,42 @@ func (m *MerkleTree) GenerateProof(data []byte) (Proof, error) {
 		proof = append(proof, pe)
 		node = node.parent
 	}
-
-	return proof, nil
+	return proof
 }
 
-// VerifyProof verifies a Merkle proof
-func (m *MerkleTree) VerifyProof(hash []byte, proof Proof) bool {
+// rootFromProof computes the root implied by a proof for the given leaf hash,
+// or nil if the proof has an element with an unknown side
+func (m *MerkleTree) rootFromProof(h hash.Hash, hash []byte, proof Proof) []byte {
 	for _, node := range proof {
 		switch node.Side {
 		case Left:
-			hash = m.hash(append(node.Hash, hash...))
+			hash = m.hashNodeWith(h, node.Hash, hash)
 		case Right:
-			hash = m.hash(append(hash, node.Hash...))
+			hash = m.hashNodeWith(h, hash, node.Hash)
+		default:
+			return nil
 		}
 	}
-	return bytes.Equal(hash, m.root.hash)
+	return hash
 }
 
-// VerifyData verifies a Merkle proof for given data
-func (m *MerkleTree) VerifyData(data []byte, proof Proof) bool {
-	return m.VerifyProof(m.hash(data), proof)
+// provesRoot reports whether a proof for the given leaf hash leads to root; a
+// proof never proves a missing root, and one with an unknown side proves none
+func (m *MerkleTree) provesRoot(h hash.Hash, hash []byte, proof Proof, root []byte) bool {
+	computed := m.rootFromProof(h, hash, proof)
+	return len(root) > 0 && computed != nil && bytes.Equal(computed, root)
 }
 
-// AddLeaf adds a new leaf node to the tree
-func (m *MerkleTree) AddLeaf(data []byte) {
-	node := &Node{
-		hash: m.hash(data),
-		data: data,
+// rebuild recalculates the tree from the current leaves
+func (m *MerkleTree) rebuild() {
+	if m.sortLeaves {
+		sort.SliceStable(m.leafs, func(i, j int) bool {
+			return bytes.Compare(m.leafs[i].hash, m.leafs[j].hash) < 0
+		})
 	}
-	m.leafs = append(m.leafs, node)
+	m.levels = nil
 	m.root = m.buildTree(m.leafs)
-}
-
-// UpdateLeaf updates a leaf node and recalculates the tree
-func (m *MerkleTree) UpdateLeaf(oldData, newData []byte) error {
-	for i, leaf := range m.leafs {
-		if bytes.Equal(leaf.data, oldData) {
-			m.leafs[i].data = newData
-			m.leafs[i].hash = m.hash(newData)
-			m.root = m.buildTree(m.leafs)
-			return nil
-		}
-	}
-	return ErrNotFoundData
-}
-
-// hash computes the hash of a given value
-func (m *MerkleTree) hash(v []byte) []byte {
-	h := m.hashFn()
-	h.Write(v)
-	return h.Sum(nil)
+	m.commit()
 }
 
 // buildTree recursively builds the Merkle tree
@@ -156,21 +570,25 @@ func (m *MerkleTree) buildTree(nodes []*Node) *Node {
 	if len(nodes) == 0 {
 		return nil
 	}
+	m.levels = append(m.levels, nodes)
 	if len(nodes) == 1 {
 		return nodes[0]
 	}
 
-	var parents []*Node
+	parents := make([]*Node, 0, (len(nodes)+1)/2)
 	for i := 0; i < len(nodes); i += 2 {
 		left, right := nodes[i], nodes[i] // default right to left for odd number of nodes
 		if i+1 < len(nodes) {
 			right = nodes[i+1]
+		} else if m.promoteOdd {
+			parents = append(parents, left)
+			continue
 		}
 
 		parent := &Node{
 			left:  left,
 			right: right,
-			hash:  m.hash(append(left.hash, right.hash...)),
+			hash:  m.hashNode(left.hash, right.hash),
 		}
 
 		left.parent = parent
@@ -181,3 +599,23 @@ func (m *MerkleTree) buildTree(nodes []*Node) *Node {
 
 	return m.buildTree(parents)
 }
+
+// proofSides derives the side of every proof element for the leaf at index from
+// its position alone, skipping the levels where a promoted node has no sibling
+func proofSides(index, leafCount int, promoteOdd bool) ([]Side, error) {
+	if index < 0 || index >= leafCount {
+		return nil, ErrOutOfRange
+	}
+
+	var sides []Side
+	for width := leafCount; width > 1; width = (width + 1) / 2 {
+		switch {
+		case index%2 == 1:
+			sides = append(sides, Left)
+		case index+1 < width || !promoteOdd:
+			sides = append(sides, Right)
+		}
+		index /= 2
+	}
+	return sides, nil
+}
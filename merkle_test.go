@@ -1,9 +1,10 @@
-package main
+package merkle
 
 import (
 	"crypto/sha256"
 	"fmt"
 	"hash"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -30,6 +31,90 @@ func Test_New(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, sha256.Size, len(tree.root.hash))
 	})
+
+	t.Run("should promote odd nodes", func(t *testing.T) {
+		data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+		tree, err := New(data, WithHashFunction(mockHash), WithOddNodePromotion())
+		require.NoError(t, err)
+		require.Equal(t, "hash(hash(hash(a)hash(b))hash(c))", string(tree.root.hash))
+	})
+
+	t.Run("should commit to the multiset of sorted leaves", func(t *testing.T) {
+		a, err := New([][]byte{[]byte("c"), []byte("a"), []byte("b"), []byte("a")}, WithSortedLeaves())
+		require.NoError(t, err)
+		b, err := New([][]byte{[]byte("a"), []byte("b"), []byte("a"), []byte("c")}, WithSortedLeaves())
+		require.NoError(t, err)
+		c, err := New([][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("c")}, WithSortedLeaves())
+		require.NoError(t, err)
+		require.Equal(t, a.Root(), b.Root())
+		require.NotEqual(t, a.Root(), c.Root())
+
+		b.AddLeaf([]byte("d"))
+		require.NoError(t, c.UpdateLeaf([]byte("c"), []byte("d")))
+		c.AddLeaf([]byte("a"))
+		require.Equal(t, b.Root(), c.Root())
+	})
+
+	t.Run("should personalize leaf and node hashes", func(t *testing.T) {
+		data := [][]byte{[]byte("a"), []byte("b")}
+		tree, err := New(data, WithHashFunction(mockHash), WithPersonalization("app:v1"))
+		require.NoError(t, err)
+		require.Equal(t, "hash(\x06app:v1hash(\x06app:v1a)hash(\x06app:v1b))", string(tree.root.hash))
+
+		other, err := New(data, WithPersonalization("app:v2"))
+		require.NoError(t, err)
+		plain, err := New(data)
+		require.NoError(t, err)
+		require.NotEqual(t, plain.Root(), other.Root())
+	})
+
+	t.Run("should prefix leaf and node hashes", func(t *testing.T) {
+		data := [][]byte{[]byte("a"), []byte("b")}
+		tree, err := New(data, WithHashFunction(mockHash), WithDomainSeparation([]byte("L"), []byte("N")))
+		require.NoError(t, err)
+		require.Equal(t, "hash(Nhash(La)hash(Lb))", string(tree.root.hash))
+	})
+
+	t.Run("should truncate leaf and node hashes", func(t *testing.T) {
+		data := [][]byte{[]byte("a"), []byte("b")}
+		tree, err := New(data, WithHashFunction(mockHash), WithHashTruncation(6))
+		require.NoError(t, err)
+		require.Equal(t, "hash(h", string(tree.root.hash))
+
+		truncated, err := New([][]byte{[]byte("a"), []byte("b"), []byte("c")}, WithHashTruncation(20))
+		require.NoError(t, err)
+		require.Len(t, truncated.Root(), 20)
+		proof, err := truncated.GenerateProofAt(2)
+		require.NoError(t, err)
+		require.True(t, truncated.VerifyData([]byte("c"), proof))
+		require.True(t, VerifyDataProof(truncated.Root(), []byte("c"), proof, WithHashTruncation(20)))
+		require.False(t, VerifyDataProof(truncated.Root(), []byte("c"), proof))
+	})
+
+	t.Run("should concatenate children right to left", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a"), []byte("b"), []byte("c")}, WithHashFunction(mockHash), WithReversedConcatenation())
+		require.NoError(t, err)
+		require.Equal(t, "hash(hash(hash(c)hash(c))hash(hash(b)hash(a)))", string(tree.root.hash))
+
+		proof, err := tree.GenerateProofAt(1)
+		require.NoError(t, err)
+		require.True(t, tree.VerifyData([]byte("b"), proof))
+	})
+
+	t.Run("should keep hashes in reversed byte order", func(t *testing.T) {
+		data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+		tree, err := New(data, WithHashFunction(mockHash), WithReversedHashBytes())
+		require.NoError(t, err)
+		require.Equal(t, ")a(hsah", string(tree.leafs[0].hash))
+
+		expected := []byte("hash(hash(hash(a)hash(b))hash(hash(c)hash(c)))")
+		slices.Reverse(expected)
+		require.Equal(t, expected, tree.root.hash)
+
+		proof, err := tree.GenerateProofAt(2)
+		require.NoError(t, err)
+		require.True(t, tree.VerifyData([]byte("c"), proof))
+	})
 }
 
 func Test_GenerateProof(t *testing.T) {
@@ -51,6 +136,51 @@ func Test_GenerateProof(t *testing.T) {
 	})
 }
 
+func Test_GenerateProofByHash(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
+	tree, err := New(data, WithHashFunction(mockHash), WithoutStoringData())
+	require.NoError(t, err)
+
+	t.Run("should generate the same proof as by data", func(t *testing.T) {
+		byHash, err := tree.GenerateProofByHash([]byte("hash(c)"))
+		require.NoError(t, err)
+		byData, err := tree.GenerateProof([]byte("c"))
+		require.NoError(t, err)
+		require.Equal(t, byData, byHash)
+		require.True(t, tree.VerifyProof([]byte("hash(c)"), byHash))
+	})
+
+	t.Run("should not keep the leaf data", func(t *testing.T) {
+		for _, leaf := range tree.leafs {
+			require.Nil(t, leaf.data)
+		}
+	})
+
+	t.Run("should return error for non-existent hash", func(t *testing.T) {
+		_, err := tree.GenerateProofByHash([]byte("hash(e)"))
+		require.ErrorIs(t, err, ErrNotFoundData)
+	})
+}
+
+func Test_GenerateProofAt(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
+	tree, err := New(data, WithHashFunction(mockHash))
+	require.NoError(t, err)
+
+	t.Run("should generate the same proof as by data", func(t *testing.T) {
+		byIndex, err := tree.GenerateProofAt(1)
+		require.NoError(t, err)
+		byData, err := tree.GenerateProof([]byte("b"))
+		require.NoError(t, err)
+		require.Equal(t, byData, byIndex)
+	})
+
+	t.Run("should return error for out of range index", func(t *testing.T) {
+		_, err := tree.GenerateProofAt(4)
+		require.ErrorIs(t, err, ErrOutOfRange)
+	})
+}
+
 func Test_VerifyProof(t *testing.T) {
 	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
 	tree, err := New(data, WithHashFunction(mockHash))
@@ -69,6 +199,35 @@ func Test_VerifyProof(t *testing.T) {
 		valid := tree.VerifyProof([]byte("hash(b)"), invalidProof)
 		require.False(t, valid)
 	})
+
+	t.Run("should not verify proof with unknown side", func(t *testing.T) {
+		invalidProof := append(Proof{}, proof...)
+		invalidProof = append(invalidProof, ProofElement{Hash: []byte("ignored"), Side: Side(7)})
+		valid := tree.VerifyProof([]byte("hash(b)"), invalidProof)
+		require.False(t, valid)
+	})
+}
+
+func Test_Verify(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
+	tree, err := New(data, WithHashFunction(mockHash))
+	require.NoError(t, err)
+
+	proof, err := tree.GenerateProof([]byte("b"))
+	require.NoError(t, err)
+
+	t.Run("should verify valid proof", func(t *testing.T) {
+		require.NoError(t, tree.Verify([]byte("hash(b)"), proof))
+	})
+
+	t.Run("should report unknown sides", func(t *testing.T) {
+		invalidProof := Proof{proof[0], {Hash: proof[1].Hash, Side: Side(2)}}
+		require.ErrorIs(t, tree.Verify([]byte("hash(b)"), invalidProof), ErrInvalidSide)
+	})
+
+	t.Run("should report root mismatch", func(t *testing.T) {
+		require.ErrorIs(t, tree.Verify([]byte("hash(c)"), proof), ErrInvalidProof)
+	})
 }
 
 func Test_VerifyData(t *testing.T) {
@@ -88,6 +247,21 @@ func Test_VerifyData(t *testing.T) {
 		valid := tree.VerifyData([]byte("e"), proof)
 		require.False(t, valid)
 	})
+
+	t.Run("should verify against a root without the tree", func(t *testing.T) {
+		require.True(t, VerifyDataProof(tree.Root(), []byte("b"), proof, WithHashFunction(mockHash)))
+		require.False(t, VerifyDataProof(tree.Root(), []byte("b"), proof))
+	})
+
+	t.Run("should not verify a proof with a bad side against a nil root", func(t *testing.T) {
+		bad := Proof{{Side: 9}}
+		require.False(t, VerifyDataProof(nil, []byte("forged"), bad))
+		require.False(t, VerifyShare(nil, []byte("forged"), bad))
+		require.False(t, VerifyTruncatedProof(Canopy{Hashes: [][]byte{nil}}, []byte("forged"), 0, bad))
+		require.ErrorIs(t, VerifyAcross(map[string][]byte{"t": nil}, []ProofBundle{{TreeID: "t", Proof: bad}}), ErrInvalidProof)
+		keys, roots := map[string][]byte{"k": []byte("key")}, map[string][]byte{"k": nil}
+		require.ErrorIs(t, VerifyEpochProof(keys, roots, []byte("forged"), EpochProof{KeyID: "k", Proof: bad}), ErrInvalidProof)
+	})
 }
 
 func Test_AddLeaf(t *testing.T) {
@@ -103,6 +277,29 @@ func Test_AddLeaf(t *testing.T) {
 		require.NotEqual(t, oldRoot, tree.root.hash)
 		require.Equal(t, "hash(hash(hash(a)hash(b))hash(hash(c)hash(c)))", string(tree.root.hash))
 	})
+
+	t.Run("should not grow leaves within the reserved capacity", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a")}, WithCapacity(4))
+		require.NoError(t, err)
+		require.Equal(t, 4, cap(tree.leafs))
+
+		first := &tree.leafs[0]
+		tree.AddLeaf([]byte("b"))
+		tree.AddLeaf([]byte("c"))
+		tree.AddLeaf([]byte("d"))
+		require.Same(t, first, &tree.leafs[0])
+	})
+}
+
+func Test_AddLeaves(t *testing.T) {
+	t.Run("should add every leaf and update root hash", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a")}, WithHashFunction(mockHash))
+		require.NoError(t, err)
+
+		tree.AddLeaves([][]byte{[]byte("b"), []byte("c")})
+		require.Len(t, tree.leafs, 3)
+		require.Equal(t, "hash(hash(hash(a)hash(b))hash(hash(c)hash(c)))", string(tree.root.hash))
+	})
 }
 
 func Test_UpdateLeaf(t *testing.T) {
@@ -0,0 +1,204 @@
+package merklelog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"github.com/chakra-guy/merkle"
+)
+
+// Log is a read-only view of a log file. Leaf data and node hashes are read in
+// place from the mapped file, which a Log sees as it was when opened.
+type Log struct {
+	data        []byte
+	unmap       func() error
+	leaves      [][]byte
+	nodes       map[[2]int][]byte // stored node hashes by level and index
+	checkpoints []Checkpoint
+	end         int // offset past the last complete record
+}
+
+// Open maps a log file for reading
+func Open(path string) (*Log, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	l, err := Parse(data)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	l.unmap = unmap
+	return l, nil
+}
+
+// Parse reads a log from its contents, which must not change while the Log is in use
+func Parse(data []byte) (*Log, error) {
+	if !bytes.HasPrefix(data, []byte(header)) {
+		return nil, fmt.Errorf("%w: missing header", ErrCorrupt)
+	}
+	l := &Log{data: data, nodes: map[[2]int][]byte{}, end: len(header)}
+
+	for r := (reader{b: data, off: l.end}); r.off < len(data); l.end = r.off {
+		switch data[r.off] {
+		case recordLeaf:
+			r.off++
+			n, ok := r.uvarint(true)
+			leaf, ok := r.next(n, ok)
+			if !ok {
+				return l, nil
+			}
+			l.leaves = append(l.leaves, leaf)
+		case recordCheckpoint:
+			r.off++
+			size, ok := r.uvarint(true)
+			root, ok := r.next(hashSize, ok)
+			count, ok := r.uvarint(ok)
+			if !ok {
+				return l, nil
+			}
+			// bound the untrusted sizes before converting them to ints
+			if size > uint64(len(l.leaves)) || len(l.checkpoints) > 0 && int(size) <= l.checkpoints[len(l.checkpoints)-1].Size {
+				return nil, fmt.Errorf("%w: checkpoint at size %d", ErrCorrupt, size)
+			}
+			nodes := make(map[[2]int][]byte)
+			for i := uint64(0); i < count && ok; i++ {
+				var level, index uint64
+				var hash []byte
+				level, ok = r.uvarint(ok)
+				index, ok = r.uvarint(ok)
+				if hash, ok = r.next(hashSize, ok); ok {
+					if level == 0 || level > 62 || index >= size>>level {
+						return nil, fmt.Errorf("%w: node %d/%d outside checkpoint %d", ErrCorrupt, level, index, size)
+					}
+					nodes[[2]int{int(level), int(index)}] = hash
+				}
+			}
+			if !ok {
+				return l, nil
+			}
+			for k, hash := range nodes {
+				l.nodes[k] = hash
+			}
+			l.checkpoints = append(l.checkpoints, Checkpoint{Size: int(size), Root: root})
+		default:
+			return nil, fmt.Errorf("%w: unknown record type %#x at offset %d", ErrCorrupt, data[r.off], r.off)
+		}
+	}
+	return l, nil
+}
+
+// Close unmaps the file
+func (l *Log) Close() error {
+	if l.unmap == nil {
+		return nil
+	}
+	return l.unmap()
+}
+
+// Size returns the number of leaves in the log
+func (l *Log) Size() int {
+	return len(l.leaves)
+}
+
+// Leaf returns a copy of the data of the leaf at index
+func (l *Log) Leaf(index int) ([]byte, error) {
+	if index < 0 || index >= len(l.leaves) {
+		return nil, merkle.ErrOutOfRange
+	}
+	return bytes.Clone(l.leaves[index]), nil
+}
+
+// Checkpoints returns the tree heads stored in the log, oldest first
+func (l *Log) Checkpoints() []Checkpoint {
+	return l.checkpoints
+}
+
+// Root returns the root of the tree over the first size leaves
+func (l *Log) Root(size int) ([]byte, error) {
+	if size <= 0 || size > len(l.leaves) {
+		return nil, merkle.ErrOutOfRange
+	}
+	return l.subtree(0, size), nil
+}
+
+// Prove returns the inclusion proof of the leaf at index in the tree over the
+// first size leaves, using stored nodes wherever the log has them
+func (l *Log) Prove(index, size int) (merkle.Proof, error) {
+	if size <= 0 || size > len(l.leaves) || index < 0 || index >= size {
+		return nil, merkle.ErrOutOfRange
+	}
+	return l.path(index, 0, size), nil
+}
+
+// path returns the audit path of the leaf at index in the subtree over the
+// leaves from start to end, as in RFC 9162 section 2.1.3.1
+func (l *Log) path(index, start, end int) merkle.Proof {
+	if end-start == 1 {
+		return nil
+	}
+	k := splitPoint(end - start)
+	if index < start+k {
+		return append(l.path(index, start, start+k), merkle.ProofElement{Hash: l.subtree(start+k, end), Side: merkle.Right})
+	}
+	return append(l.path(index, start+k, end), merkle.ProofElement{Hash: l.subtree(start, start+k), Side: merkle.Left})
+}
+
+// subtree returns the hash of the subtree over the leaves from start to end
+func (l *Log) subtree(start, end int) []byte {
+	if n := end - start; n&(n-1) == 0 && start%n == 0 {
+		level := bits.TrailingZeros(uint(n))
+		return l.hash(level, start>>level)
+	}
+	k := splitPoint(end - start)
+	return merkle.SchemeRFC6962.HashNode(l.subtree(start, start+k), l.subtree(start+k, end))
+}
+
+// hash returns the hash of the perfect subtree at level and index, reading it
+// from the log if stored and computing it from its children otherwise
+func (l *Log) hash(level, index int) []byte {
+	if level == 0 {
+		return merkle.SchemeRFC6962.HashLeaf(l.leaves[index])
+	}
+	if hash, ok := l.nodes[[2]int{level, index}]; ok {
+		return hash
+	}
+	return merkle.SchemeRFC6962.HashNode(l.hash(level-1, 2*index), l.hash(level-1, 2*index+1))
+}
+
+// splitPoint returns the largest power of two smaller than n
+func splitPoint(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// reader decodes record fields, reporting false once the data runs out
+type reader struct {
+	b   []byte
+	off int
+}
+
+// uvarint reads a uvarint if ok
+func (r *reader) uvarint(ok bool) (uint64, bool) {
+	if !ok {
+		return 0, false
+	}
+	v, n := binary.Uvarint(r.b[r.off:])
+	if n <= 0 {
+		return 0, false
+	}
+	r.off += n
+	return v, true
+}
+
+// next reads n bytes if ok
+func (r *reader) next(n uint64, ok bool) ([]byte, bool) {
+	if !ok || n > uint64(len(r.b)-r.off) {
+		return nil, false
+	}
+	b := r.b[r.off : r.off+int(n)]
+	r.off += int(n)
+	return b, true
+}
@@ -0,0 +1,95 @@
+package merklelog
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Log(t *testing.T) {
+	t.Run("should prove every leaf against every tree size", func(t *testing.T) {
+		data := leafData(11)
+		path := writeLog(t, data[:9], 4)
+		w, err := OpenWriter(path, 0)
+		require.NoError(t, err)
+		for _, item := range data[9:] {
+			_, err := w.Append(item)
+			require.NoError(t, err)
+		}
+		// leave the last leaves without a checkpoint
+		require.NoError(t, w.f.Close())
+
+		l, err := Open(path)
+		require.NoError(t, err)
+		defer l.Close()
+		require.Equal(t, 11, l.Size())
+
+		for size := 1; size <= l.Size(); size++ {
+			tree := reference(t, data[:size])
+			root, err := l.Root(size)
+			require.NoError(t, err)
+			require.Equal(t, tree.Root(), root)
+			for i := 0; i < size; i++ {
+				want, err := tree.GenerateProofAt(i)
+				require.NoError(t, err)
+				got, err := l.Prove(i, size)
+				require.NoError(t, err)
+				require.Equal(t, want, got, "size=%d i=%d", size, i)
+			}
+		}
+	})
+
+	t.Run("should read leaves", func(t *testing.T) {
+		data := leafData(3)
+		l, err := Open(writeLog(t, data, 0))
+		require.NoError(t, err)
+		defer l.Close()
+
+		leaf, err := l.Leaf(1)
+		require.NoError(t, err)
+		require.Equal(t, data[1], leaf)
+		_, err = l.Leaf(3)
+		require.ErrorIs(t, err, merkle.ErrOutOfRange)
+		_, err = l.Prove(3, 3)
+		require.ErrorIs(t, err, merkle.ErrOutOfRange)
+		_, err = l.Root(4)
+		require.ErrorIs(t, err, merkle.ErrOutOfRange)
+	})
+
+	t.Run("should ignore a record cut short at the end", func(t *testing.T) {
+		b, err := os.ReadFile(writeLog(t, leafData(4), 0))
+		require.NoError(t, err)
+		for cut := len(header); cut < len(b); cut++ {
+			l, err := Parse(b[:cut])
+			require.NoError(t, err)
+			require.LessOrEqual(t, l.Size(), 4)
+			require.Empty(t, l.Checkpoints())
+		}
+	})
+
+	t.Run("should reject corrupt logs", func(t *testing.T) {
+		leaves, err := os.ReadFile(writeLog(t, leafData(2), 0))
+		require.NoError(t, err)
+		// a checkpoint node whose index overflows when shifted to its level
+		overflow := append(append(leaves, recordCheckpoint, 2), make([]byte, hashSize)...)
+		overflow = append(append(overflow, 1, 1), binary.AppendUvarint(nil, 1<<63)...)
+		overflow = append(overflow, make([]byte, hashSize)...)
+
+		for _, b := range [][]byte{
+			overflow,
+			nil,
+			[]byte("not a log\n"),
+			append([]byte(header), 0x07),
+			// a checkpoint beyond the leaves
+			append(append([]byte(header), recordCheckpoint, 1), make([]byte, hashSize+1)...),
+			// a checkpoint size that wraps when converted to an int
+			append(append(append([]byte(header), recordCheckpoint), binary.AppendUvarint(nil, 1<<63)...), make([]byte, hashSize+1)...),
+		} {
+			_, err := Parse(b)
+			require.ErrorIs(t, err, ErrCorrupt)
+		}
+	})
+}
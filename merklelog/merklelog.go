@@ -0,0 +1,201 @@
+// Package merklelog is a single-file append-only log format embedding its
+// Merkle tree, so the log is both the storage and the proof source, like a
+// self-contained tlog. Leaf records are interleaved with periodic checkpoints
+// storing the tree head and the interior nodes completed since the previous
+// checkpoint, hashed as in RFC 6962; a Log maps the file and reads proofs
+// straight from it.
+//
+// A file starts with the line "merklelog v1", followed by records:
+//
+//	leaf:       0x00 | uvarint length | data
+//	checkpoint: 0x01 | uvarint size | root | uvarint count | count × (uvarint level | uvarint index | hash)
+//
+// The checkpoint node at level and index covers the leaves from index<<level
+// up to (index+1)<<level. A record cut short at the end of the file, as left
+// by a crash, is ignored.
+package merklelog
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"os"
+
+	"github.com/chakra-guy/merkle"
+)
+
+const header = "merklelog v1\n"
+
+const hashSize = sha256.Size
+
+const (
+	recordLeaf       byte = 0x00
+	recordCheckpoint byte = 0x01
+)
+
+var (
+	ErrCorrupt = errors.New("corrupt merkle log")
+	ErrEmpty   = errors.New("merkle log has no leaves")
+)
+
+// Checkpoint is a tree head stored in the log
+type Checkpoint struct {
+	Size int
+	Root []byte
+}
+
+// node is the root of the perfect subtree at level and index
+type node struct {
+	level, index int
+	hash         []byte
+}
+
+// Writer appends leaves to a log file. It is not safe for concurrent use.
+type Writer struct {
+	f         *os.File
+	interval  int
+	size      int
+	frontier  []node // roots of the perfect subtrees making up the tree, largest first
+	completed []node // interior nodes completed since the last checkpoint
+	last      Checkpoint
+}
+
+// Create creates a new log file, writing a checkpoint every interval leaves,
+// or only on Checkpoint and Close if interval is zero
+func Create(path string, interval int) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.WriteString(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Writer{f: f, interval: interval}, nil
+}
+
+// OpenWriter opens an existing log file for appending, discarding a record cut
+// short at its end
+func OpenWriter(path string, interval int) (*Writer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	l, err := Parse(b)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Writer{interval: interval}
+	if n := len(l.checkpoints); n > 0 {
+		w.last = l.checkpoints[n-1]
+	}
+	// every perfect subtree ending by the last checkpoint is stored in the log
+	start := 0
+	for level := 62; level >= 0; level-- {
+		if w.last.Size&(1<<level) != 0 {
+			w.frontier = append(w.frontier, node{level: level, index: start >> level, hash: l.hash(level, start>>level)})
+			start += 1 << level
+		}
+	}
+	w.size = w.last.Size
+	for i := w.last.Size; i < l.Size(); i++ {
+		w.push(l.hash(0, i))
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(int64(l.end)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	w.f = f
+	return w, nil
+}
+
+// Append appends a leaf and returns its index
+func (w *Writer) Append(data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, merkle.ErrEmptyData
+	}
+	record := binary.AppendUvarint([]byte{recordLeaf}, uint64(len(data)))
+	if _, err := w.f.Write(append(record, data...)); err != nil {
+		return 0, err
+	}
+	index := w.size
+	w.push(merkle.SchemeRFC6962.HashLeaf(data))
+
+	if w.interval > 0 && w.size-w.last.Size >= w.interval {
+		if _, err := w.Checkpoint(); err != nil {
+			return 0, err
+		}
+	}
+	return index, nil
+}
+
+// Size returns the number of leaves in the log
+func (w *Writer) Size() int {
+	return w.size
+}
+
+// Checkpoint writes the current tree head and the nodes completed since the
+// previous checkpoint, and returns the head
+func (w *Writer) Checkpoint() (Checkpoint, error) {
+	if w.size == 0 {
+		return Checkpoint{}, ErrEmpty
+	}
+	if w.size == w.last.Size {
+		return w.last, nil
+	}
+
+	c := Checkpoint{Size: w.size, Root: w.root()}
+	record := binary.AppendUvarint([]byte{recordCheckpoint}, uint64(c.Size))
+	record = append(record, c.Root...)
+	record = binary.AppendUvarint(record, uint64(len(w.completed)))
+	for _, n := range w.completed {
+		record = binary.AppendUvarint(record, uint64(n.level))
+		record = binary.AppendUvarint(record, uint64(n.index))
+		record = append(record, n.hash...)
+	}
+	if _, err := w.f.Write(record); err != nil {
+		return Checkpoint{}, err
+	}
+	w.last, w.completed = c, nil
+	return c, nil
+}
+
+// Close writes a checkpoint covering any leaves appended since the last one
+// and closes the file
+func (w *Writer) Close() error {
+	if w.size > w.last.Size {
+		if _, err := w.Checkpoint(); err != nil {
+			w.f.Close()
+			return err
+		}
+	}
+	return w.f.Close()
+}
+
+// push adds a leaf hash, merging the perfect subtrees it completes
+func (w *Writer) push(hash []byte) {
+	n := node{index: w.size, hash: hash}
+	for len(w.frontier) > 0 && w.frontier[len(w.frontier)-1].level == n.level {
+		left := w.frontier[len(w.frontier)-1]
+		w.frontier = w.frontier[:len(w.frontier)-1]
+		n = node{level: n.level + 1, index: left.index / 2, hash: merkle.SchemeRFC6962.HashNode(left.hash, n.hash)}
+		w.completed = append(w.completed, n)
+	}
+	w.frontier = append(w.frontier, n)
+	w.size++
+}
+
+// root folds the frontier into the tree root
+func (w *Writer) root() []byte {
+	root := w.frontier[len(w.frontier)-1].hash
+	for i := len(w.frontier) - 2; i >= 0; i-- {
+		root = merkle.SchemeRFC6962.HashNode(w.frontier[i].hash, root)
+	}
+	return root
+}
@@ -0,0 +1,115 @@
+package merklelog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+// leafData returns the data of n distinct leaves
+func leafData(n int) [][]byte {
+	data := make([][]byte, n)
+	for i := range data {
+		data[i] = []byte(fmt.Sprint("entry", i))
+	}
+	return data
+}
+
+// reference builds the RFC 6962 tree the log embeds
+func reference(t *testing.T, data [][]byte) *merkle.MerkleTree {
+	tree, err := merkle.New(data, merkle.WithScheme(merkle.SchemeRFC6962))
+	require.NoError(t, err)
+	return tree
+}
+
+// writeLog writes a log of the given leaves
+func writeLog(t *testing.T, data [][]byte, interval int) string {
+	path := filepath.Join(t.TempDir(), "log")
+	w, err := Create(path, interval)
+	require.NoError(t, err)
+	for i, item := range data {
+		index, err := w.Append(item)
+		require.NoError(t, err)
+		require.Equal(t, i, index)
+	}
+	require.NoError(t, w.Close())
+	return path
+}
+
+func Test_Writer(t *testing.T) {
+	t.Run("should checkpoint the RFC 6962 tree head every interval", func(t *testing.T) {
+		data := leafData(10)
+		l, err := Open(writeLog(t, data, 4))
+		require.NoError(t, err)
+		defer l.Close()
+
+		var sizes []int
+		for _, c := range l.Checkpoints() {
+			sizes = append(sizes, c.Size)
+			require.Equal(t, reference(t, data[:c.Size]).Root(), c.Root)
+		}
+		require.Equal(t, []int{4, 8, 10}, sizes)
+	})
+
+	t.Run("should not write empty checkpoints", func(t *testing.T) {
+		w, err := Create(filepath.Join(t.TempDir(), "log"), 0)
+		require.NoError(t, err)
+		_, err = w.Checkpoint()
+		require.ErrorIs(t, err, ErrEmpty)
+
+		_, err = w.Append([]byte("a"))
+		require.NoError(t, err)
+		first, err := w.Checkpoint()
+		require.NoError(t, err)
+		again, err := w.Checkpoint()
+		require.NoError(t, err)
+		require.Equal(t, first, again)
+		require.NoError(t, w.Close())
+	})
+
+	t.Run("should reject empty leaves and existing files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "log")
+		w, err := Create(path, 0)
+		require.NoError(t, err)
+		_, err = w.Append(nil)
+		require.ErrorIs(t, err, merkle.ErrEmptyData)
+		require.NoError(t, w.Close())
+
+		_, err = Create(path, 0)
+		require.ErrorIs(t, err, os.ErrExist)
+	})
+
+	t.Run("should resume appending after a crash", func(t *testing.T) {
+		data := leafData(13)
+		path := writeLog(t, data[:5], 2)
+
+		// a torn leaf record at the end of the file
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		require.NoError(t, err)
+		_, err = f.Write([]byte{recordLeaf, 9, 'x'})
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		w, err := OpenWriter(path, 3)
+		require.NoError(t, err)
+		require.Equal(t, 5, w.Size())
+		for _, item := range data[5:] {
+			_, err := w.Append(item)
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		l, err := Open(path)
+		require.NoError(t, err)
+		defer l.Close()
+		require.Equal(t, 13, l.Size())
+		for _, c := range l.Checkpoints() {
+			require.Equal(t, reference(t, data[:c.Size]).Root(), c.Root)
+		}
+		require.Equal(t, 13, l.Checkpoints()[len(l.Checkpoints())-1].Size)
+	})
+}
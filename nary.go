@@ -0,0 +1,230 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+var (
+	ErrArity        = errors.New("option requires an n-ary tree built with NewNary")
+	ErrBinaryScheme = errors.New("scheme only defines binary trees")
+)
+
+// WithArity sets the branching factor of trees built with NewNary; wider
+// trees are shallower, so proofs have fewer levels but more siblings per level
+func WithArity(k int) Option {
+	return func(m *MerkleTree) {
+		m.arity = k
+	}
+}
+
+// NaryTree is a Merkle tree whose interior nodes have up to arity children.
+// A short last group is padded by repeating its last child, or promoted
+// unchanged if it has a single child and odd nodes are promoted, so an arity
+// of 2 gives the same root as New.
+type NaryTree struct {
+	m      *MerkleTree
+	arity  int
+	leaves []*Node
+	levels [][][]byte // levels[0] are the leaf hashes, the last level holds the root
+}
+
+// NaryProofStep is one level of an n-ary proof: the position of the proven
+// node within its group and the hashes of the other children, in order. With
+// a vector commitment it instead carries the parent's commitment and the
+// opening of the position.
+type NaryProofStep struct {
+	Index      int      `json:"index"`
+	Siblings   [][]byte `json:"siblings,omitempty"`
+	Commitment []byte   `json:"commitment,omitempty"`
+	Opening    []byte   `json:"opening,omitempty"`
+}
+
+// NaryProof is a proof for a leaf of an n-ary tree, from the leaf level up
+type NaryProof []NaryProofStep
+
+// NewNary creates an n-ary Merkle tree with the arity set by WithArity; a
+// scheme only defines binary nodes, so it requires an arity of 2
+func NewNary(data [][]byte, opts ...Option) (*NaryTree, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyData
+	}
+	m := newVerifier(opts...)
+	if err := m.checkFIPS(); err != nil {
+		return nil, err
+	}
+	arity := max(m.arity, 2)
+	if m.scheme != nil && arity > 2 {
+		return nil, fmt.Errorf("%w: %s with arity %d", ErrBinaryScheme, m.scheme.Name, arity)
+	}
+
+	t := &NaryTree{m: m, arity: arity, leaves: make([]*Node, len(data))}
+	hashes := make([][]byte, len(data))
+	for i, item := range data {
+		leaf, err := m.newLeaf(item)
+		if err != nil {
+			return nil, err
+		}
+		t.leaves[i] = leaf
+		hashes[i] = t.leaves[i].hash
+	}
+
+	h := m.newHash()
+	t.levels = append(t.levels, hashes)
+	for len(hashes) > 1 {
+		parents := make([][]byte, 0, (len(hashes)+arity-1)/arity)
+		for i := 0; i < len(hashes); i += arity {
+			group := t.group(hashes, i)
+			if len(group) == 1 && m.promoteOdd {
+				parents = append(parents, group[0])
+				continue
+			}
+			parent, err := m.commitChildren(h, group)
+			if err != nil {
+				return nil, err
+			}
+			parents = append(parents, parent)
+		}
+		hashes = parents
+		t.levels = append(t.levels, hashes)
+	}
+
+	return t, nil
+}
+
+// Root returns the root hash of the tree
+func (t *NaryTree) Root() []byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Arity returns the branching factor of the tree
+func (t *NaryTree) Arity() int {
+	return t.arity
+}
+
+// Len returns the number of leaves
+func (t *NaryTree) Len() int {
+	return len(t.leaves)
+}
+
+// GenerateProof generates a proof for the first leaf holding the given data
+func (t *NaryTree) GenerateProof(data []byte) (NaryProof, error) {
+	for i, leaf := range t.leaves {
+		if bytes.Equal(leaf.data, data) {
+			return t.GenerateProofAt(i)
+		}
+	}
+	return nil, ErrNotFoundData
+}
+
+// GenerateProofAt generates a proof for the leaf at the given index
+func (t *NaryTree) GenerateProofAt(index int) (NaryProof, error) {
+	if index < 0 || index >= len(t.leaves) {
+		return nil, ErrOutOfRange
+	}
+
+	var proof NaryProof
+	for level, hashes := range t.levels[:len(t.levels)-1] {
+		start := index / t.arity * t.arity
+		group := t.group(hashes, start)
+		pos := index - start
+		index /= t.arity
+		switch {
+		case len(group) == 1 && t.m.promoteOdd:
+		case t.m.commitment != nil:
+			opening, err := t.m.commitment.Open(group, pos)
+			if err != nil {
+				return nil, err
+			}
+			commitment := t.levels[level+1][index]
+			proof = append(proof, NaryProofStep{Index: pos, Commitment: commitment, Opening: opening})
+		default:
+			siblings := append(append([][]byte{}, group[:pos]...), group[pos+1:]...)
+			proof = append(proof, NaryProofStep{Index: pos, Siblings: siblings})
+		}
+	}
+	return proof, nil
+}
+
+// VerifyProof verifies a proof for a leaf hash against the root
+func (t *NaryTree) VerifyProof(hash []byte, proof NaryProof) bool {
+	return bytes.Equal(t.m.naryRoot(hash, proof), t.Root())
+}
+
+// VerifyData verifies a proof for given data against the root
+func (t *NaryTree) VerifyData(data []byte, proof NaryProof) bool {
+	hash, err := t.m.hashData(data)
+	return err == nil && t.VerifyProof(hash, proof)
+}
+
+// VerifyNaryProof verifies a proof for a leaf hash against a root; the arity
+// does not need to be known, since every step carries its whole group or the
+// opening of its commitment
+func VerifyNaryProof(root, hash []byte, proof NaryProof, opts ...Option) bool {
+	return bytes.Equal(newVerifier(opts...).naryRoot(hash, proof), root)
+}
+
+// naryRoot computes the root implied by an n-ary proof for the given leaf
+// hash, or nil if a step has an index outside its group or an opening that
+// does not verify
+func (m *MerkleTree) naryRoot(hash []byte, proof NaryProof) []byte {
+	if m.commitment != nil {
+		for _, step := range proof {
+			if !m.commitment.Verify(step.Commitment, step.Index, hash, step.Opening) {
+				return nil
+			}
+			hash = step.Commitment
+		}
+		return hash
+	}
+
+	h := m.newHash()
+	for _, step := range proof {
+		if step.Index < 0 || step.Index > len(step.Siblings) {
+			return nil
+		}
+		group := make([][]byte, 0, len(step.Siblings)+1)
+		group = append(group, step.Siblings[:step.Index]...)
+		group = append(group, hash)
+		group = append(group, step.Siblings[step.Index:]...)
+		if m.scheme != nil && len(group) != 2 {
+			return nil
+		}
+		hash = m.hashChildren(h, group)
+	}
+	return hash
+}
+
+// group returns the children of the group starting at start, padded to the
+// arity by repeating the last child unless odd nodes are promoted
+func (t *NaryTree) group(hashes [][]byte, start int) [][]byte {
+	end := min(start+t.arity, len(hashes))
+	group := hashes[start:end:end]
+	if t.m.promoteOdd {
+		return group
+	}
+	for len(group) < t.arity {
+		group = append(group, hashes[end-1])
+	}
+	return group
+}
+
+// hashChildren computes the hash of an interior node from all its children;
+// a pair is hashed as a binary node, so schemes apply
+func (m *MerkleTree) hashChildren(h hash.Hash, children [][]byte) []byte {
+	if len(children) == 2 {
+		return m.hashNodeWith(h, children[0], children[1])
+	}
+	h.Reset()
+	h.Write(m.personalization)
+	h.Write(m.nodePrefix)
+	for i := range children {
+		if m.swapOrder {
+			i = len(children) - 1 - i
+		}
+		h.Write(m.unreversed(children[i]))
+	}
+	return m.finish(h.Sum(nil))
+}
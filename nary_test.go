@@ -0,0 +1,90 @@
+package merkle
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewNary(t *testing.T) {
+	var data [][]byte
+	for i := 0; i < 10; i++ {
+		data = append(data, []byte(fmt.Sprint(i)))
+	}
+
+	t.Run("should match binary trees with an arity of 2", func(t *testing.T) {
+		for _, opts := range [][]Option{nil, {WithOddNodePromotion()}, {WithScheme(SchemeBitcoin)}, {WithScheme(SchemeOZSorted)}} {
+			nary, err := NewNary(data, opts...)
+			require.NoError(t, err)
+			tree, err := New(data, opts...)
+			require.NoError(t, err)
+			require.Equal(t, tree.Root(), nary.Root())
+		}
+	})
+
+	t.Run("should group children by the arity", func(t *testing.T) {
+		tree, err := NewNary(data[:5], WithArity(4), WithHashFunction(mockHash))
+		require.NoError(t, err)
+		require.Equal(t, 4, tree.Arity())
+		require.Equal(t, "hash(hash(hash(0)hash(1)hash(2)hash(3))"+strings.Repeat("hash(hash(4)hash(4)hash(4)hash(4))", 3)+")", string(tree.Root()))
+
+		promoted, err := NewNary(data[:6], WithArity(4), WithOddNodePromotion(), WithHashFunction(mockHash))
+		require.NoError(t, err)
+		require.Equal(t, "hash(hash(hash(0)hash(1)hash(2)hash(3))hash(hash(4)hash(5)))", string(promoted.Root()))
+	})
+
+	for _, arity := range []int{3, 4, 8} {
+		for _, promote := range []bool{false, true} {
+			opts := []Option{WithArity(arity)}
+			if promote {
+				opts = append(opts, WithOddNodePromotion())
+			}
+			tree, err := NewNary(data, opts...)
+			require.NoError(t, err)
+
+			t.Run(fmt.Sprintf("should verify proofs with an arity of %d", arity), func(t *testing.T) {
+				for i, leaf := range data {
+					proof, err := tree.GenerateProofAt(i)
+					require.NoError(t, err)
+					require.True(t, tree.VerifyData(leaf, proof))
+					require.True(t, VerifyNaryProof(tree.Root(), tree.m.hashLeaf(leaf), proof))
+					require.False(t, tree.VerifyData([]byte("x"), proof))
+				}
+			})
+		}
+	}
+
+	t.Run("should find leaves by data", func(t *testing.T) {
+		tree, err := NewNary(data, WithArity(3))
+		require.NoError(t, err)
+		proof, err := tree.GenerateProof([]byte("7"))
+		require.NoError(t, err)
+		require.True(t, tree.VerifyData([]byte("7"), proof))
+
+		_, err = tree.GenerateProof([]byte("x"))
+		require.ErrorIs(t, err, ErrNotFoundData)
+	})
+
+	t.Run("should reject steps outside their group", func(t *testing.T) {
+		tree, err := NewNary(data, WithArity(3))
+		require.NoError(t, err)
+		proof, err := tree.GenerateProofAt(0)
+		require.NoError(t, err)
+		proof[0].Index = 5
+		require.False(t, tree.VerifyData(data[0], proof))
+	})
+
+	t.Run("should return error for schemes with a wider arity", func(t *testing.T) {
+		_, err := NewNary(data, WithArity(4), WithScheme(SchemeRFC6962))
+		require.ErrorIs(t, err, ErrBinaryScheme)
+	})
+
+	t.Run("should return error for binary trees with a wider arity", func(t *testing.T) {
+		_, err := New(data, WithArity(4))
+		require.ErrorIs(t, err, ErrArity)
+		_, err = NewBuilder(WithArity(4)).Add(data[0]).Build()
+		require.ErrorIs(t, err, ErrArity)
+	})
+}
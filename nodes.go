@@ -0,0 +1,50 @@
+package merkle
+
+// Height returns the number of levels above the leaves
+func (m *MerkleTree) Height() int {
+	return len(m.levels) - 1
+}
+
+// LevelHashes returns the hashes of all nodes at the given level, where level 0
+// holds the leaves and level Height() the root; it returns nil for levels
+// outside the tree
+func (m *MerkleTree) LevelHashes(level int) [][]byte {
+	if level < 0 || level >= len(m.levels) {
+		return nil
+	}
+
+	hashes := make([][]byte, len(m.levels[level]))
+	for i, node := range m.levels[level] {
+		hashes[i] = node.hash
+	}
+	return hashes
+}
+
+// Position addresses a node by its level (0 for leaves) and its index within the level
+type Position struct {
+	Level int
+	Index int
+}
+
+// NodeInfo describes the node at a position
+type NodeInfo struct {
+	Position Position
+	Hash     []byte
+	Children []Position // a single child for nodes whose left child had no sibling
+}
+
+// GetNode returns the node at the given position, addressed the same way the
+// tree is built level by level
+func (m *MerkleTree) GetNode(level, index int) (NodeInfo, error) {
+	if level < 0 || level >= len(m.levels) || index < 0 || index >= len(m.levels[level]) {
+		return NodeInfo{}, ErrOutOfRange
+	}
+
+	info := NodeInfo{Position: Position{Level: level, Index: index}, Hash: m.levels[level][index].hash}
+	if level > 0 {
+		for child := index * 2; child < len(m.levels[level-1]) && child <= index*2+1; child++ {
+			info.Children = append(info.Children, Position{Level: level - 1, Index: child})
+		}
+	}
+	return info, nil
+}
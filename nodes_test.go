@@ -0,0 +1,65 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LevelHashes(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	tree, err := New(data, WithHashFunction(mockHash))
+	require.NoError(t, err)
+
+	t.Run("should return the hashes of every level", func(t *testing.T) {
+		require.Equal(t, 2, tree.Height())
+		require.Equal(t, [][]byte{[]byte("hash(a)"), []byte("hash(b)"), []byte("hash(c)")}, tree.LevelHashes(0))
+		require.Equal(t, [][]byte{[]byte("hash(hash(a)hash(b))"), []byte("hash(hash(c)hash(c))")}, tree.LevelHashes(1))
+		require.Equal(t, [][]byte{tree.Root()}, tree.LevelHashes(2))
+	})
+
+	t.Run("should keep promoted nodes on every level they pass through", func(t *testing.T) {
+		tree, err := New(data, WithHashFunction(mockHash), WithOddNodePromotion())
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("hash(hash(a)hash(b))"), []byte("hash(c)")}, tree.LevelHashes(1))
+	})
+
+	t.Run("should return nil for levels outside the tree", func(t *testing.T) {
+		require.Nil(t, tree.LevelHashes(-1))
+		require.Nil(t, tree.LevelHashes(3))
+	})
+}
+
+func Test_GetNode(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	tree, err := New(data, WithHashFunction(mockHash))
+	require.NoError(t, err)
+
+	t.Run("should return the root with its children", func(t *testing.T) {
+		node, err := tree.GetNode(2, 0)
+		require.NoError(t, err)
+		require.Equal(t, tree.Root(), node.Hash)
+		require.Equal(t, []Position{{Level: 1, Index: 0}, {Level: 1, Index: 1}}, node.Children)
+	})
+
+	t.Run("should return a single child for unpaired nodes", func(t *testing.T) {
+		node, err := tree.GetNode(1, 1)
+		require.NoError(t, err)
+		require.Equal(t, []byte("hash(hash(c)hash(c))"), node.Hash)
+		require.Equal(t, []Position{{Level: 0, Index: 2}}, node.Children)
+	})
+
+	t.Run("should return leaves without children", func(t *testing.T) {
+		node, err := tree.GetNode(0, 1)
+		require.NoError(t, err)
+		require.Equal(t, []byte("hash(b)"), node.Hash)
+		require.Empty(t, node.Children)
+	})
+
+	t.Run("should return error for out of range positions", func(t *testing.T) {
+		_, err := tree.GetNode(1, 2)
+		require.ErrorIs(t, err, ErrOutOfRange)
+		_, err = tree.GetNode(3, 0)
+		require.ErrorIs(t, err, ErrOutOfRange)
+	})
+}
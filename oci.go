@@ -0,0 +1,91 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"strings"
+)
+
+var ErrDigestMismatch = errors.New("content does not match its OCI descriptor")
+
+// OCIDescriptor is an OCI content descriptor
+type OCIDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// OCIManifest is the part of an OCI image manifest that the tree commits to
+type OCIManifest struct {
+	Config OCIDescriptor   `json:"config"`
+	Layers []OCIDescriptor `json:"layers"`
+}
+
+// ParseOCIManifest decodes an OCI image manifest
+func ParseOCIManifest(b []byte) (OCIManifest, error) {
+	var m OCIManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return OCIManifest{}, err
+	}
+	return m, nil
+}
+
+// OCILeaf encodes a descriptor as leaf data: the uvarint-length-prefixed media
+// type and digest followed by the 8-byte big-endian size
+func OCILeaf(d OCIDescriptor) []byte {
+	leaf := binary.AppendUvarint(nil, uint64(len(d.MediaType)))
+	leaf = append(leaf, d.MediaType...)
+	leaf = binary.AppendUvarint(leaf, uint64(len(d.Digest)))
+	leaf = append(leaf, d.Digest...)
+	return binary.BigEndian.AppendUint64(leaf, uint64(d.Size))
+}
+
+// NewFromOCIManifest creates a Merkle tree over an image's config descriptor,
+// at index 0, followed by its layer descriptors in manifest order
+func NewFromOCIManifest(manifest OCIManifest, opts ...Option) (*MerkleTree, error) {
+	data := make([][]byte, 0, len(manifest.Layers)+1)
+	data = append(data, OCILeaf(manifest.Config))
+	for _, layer := range manifest.Layers {
+		data = append(data, OCILeaf(layer))
+	}
+	return New(data, opts...)
+}
+
+// VerifyOCILayer checks that the content matches the layer descriptor and that
+// the descriptor is included under root, so a single pulled layer can be
+// verified without the rest of the image
+func VerifyOCILayer(root []byte, layer OCIDescriptor, content []byte, proof Proof, opts ...Option) error {
+	if int64(len(content)) != layer.Size {
+		return ErrDigestMismatch
+	}
+
+	algorithm, encoded, _ := strings.Cut(layer.Digest, ":")
+	var h hash.Hash
+	switch algorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return ErrDigestMismatch
+	}
+	h.Write(content)
+	if hex.EncodeToString(h.Sum(nil)) != encoded {
+		return ErrDigestMismatch
+	}
+
+	m := newVerifier(opts...)
+	hash, err := m.hashData(OCILeaf(layer))
+	if err != nil {
+		return err
+	}
+	if !m.provesRoot(m.newHash(), hash, proof, root) {
+		return ErrInvalidProof
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_VerifyOCILayer(t *testing.T) {
+	descriptor := func(mediaType string, content []byte) OCIDescriptor {
+		sum := sha256.Sum256(content)
+		return OCIDescriptor{MediaType: mediaType, Digest: "sha256:" + hex.EncodeToString(sum[:]), Size: int64(len(content))}
+	}
+	layers := [][]byte{[]byte("base layer"), []byte("app layer")}
+	raw, err := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"config":        descriptor("application/vnd.oci.image.config.v1+json", []byte("{}")),
+		"layers": []OCIDescriptor{
+			descriptor("application/vnd.oci.image.layer.v1.tar+gzip", layers[0]),
+			descriptor("application/vnd.oci.image.layer.v1.tar+gzip", layers[1]),
+		},
+	})
+	require.NoError(t, err)
+
+	manifest, err := ParseOCIManifest(raw)
+	require.NoError(t, err)
+	require.Len(t, manifest.Layers, 2)
+	tree, err := NewFromOCIManifest(manifest)
+	require.NoError(t, err)
+
+	proof, err := tree.GenerateProofAt(2)
+	require.NoError(t, err)
+
+	t.Run("should verify a single layer", func(t *testing.T) {
+		require.NoError(t, VerifyOCILayer(tree.Root(), manifest.Layers[1], layers[1], proof))
+	})
+
+	t.Run("should return error for content not matching the descriptor", func(t *testing.T) {
+		err := VerifyOCILayer(tree.Root(), manifest.Layers[1], []byte("app layeR"), proof)
+		require.ErrorIs(t, err, ErrDigestMismatch)
+		err = VerifyOCILayer(tree.Root(), manifest.Layers[1], layers[0], proof)
+		require.ErrorIs(t, err, ErrDigestMismatch)
+	})
+
+	t.Run("should return error for layers not in the image", func(t *testing.T) {
+		err := VerifyOCILayer(tree.Root(), manifest.Layers[0], layers[0], proof)
+		require.ErrorIs(t, err, ErrInvalidProof)
+	})
+}
@@ -0,0 +1,223 @@
+// Package p2p serves leaves, inclusion proofs and consistency proofs of a tree
+// to peers over a lightweight request/response protocol, one request per
+// stream, framed as length-prefixed JSON.
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/chakra-guy/merkle"
+)
+
+// ProtocolID identifies the proof exchange protocol
+const ProtocolID = "/merkle/proof/1.0.0"
+
+// maxMessageSize bounds the size of a message read from a peer
+const maxMessageSize = 4 << 20
+
+var (
+	ErrUnknownRequest = errors.New("unknown request type")
+	ErrMessageSize    = errors.New("message exceeds the maximum size")
+	ErrTreeModified   = errors.New("served tree was modified in place, replace it with SetTree")
+)
+
+// RequestType is the kind of a request
+type RequestType string
+
+const (
+	// LeafRequest asks for the leaf at Index
+	LeafRequest RequestType = "leaf"
+	// ProofRequest asks for the inclusion proof of the leaf at Index, or of
+	// the leaf with hash Hash when it is set
+	ProofRequest RequestType = "proof"
+	// ConsistencyRequest asks for the consistency proof from OldSize
+	ConsistencyRequest RequestType = "consistency"
+)
+
+// Request is a request to a peer
+type Request struct {
+	Type    RequestType `json:"type"`
+	Index   int         `json:"index,omitempty"`
+	Hash    []byte      `json:"hash,omitempty"`
+	OldSize int         `json:"old_size,omitempty"`
+}
+
+// Response is the answer to a request, with the size and root of the tree
+// that answered it; Error is set if the request failed
+type Response struct {
+	TreeSize    int          `json:"tree_size"`
+	Root        []byte       `json:"root"`
+	Leaf        *merkle.Leaf `json:"leaf,omitempty"`
+	Proof       merkle.Proof `json:"proof,omitempty"`
+	Consistency [][]byte     `json:"consistency,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// Stream is a bidirectional stream to a peer, such as a libp2p network.Stream
+type Stream interface {
+	io.ReadWriteCloser
+}
+
+// Host opens and accepts streams. A libp2p host is adapted with a few lines:
+//
+//	func (a adapter) SetStreamHandler(id string, handler func(p2p.Stream)) {
+//		a.h.SetStreamHandler(protocol.ID(id), func(s network.Stream) { handler(s) })
+//	}
+//
+//	func (a adapter) NewStream(ctx context.Context, p, id string) (p2p.Stream, error) {
+//		pid, err := peer.Decode(p)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return a.h.NewStream(ctx, pid, protocol.ID(id))
+//	}
+type Host interface {
+	SetStreamHandler(protocolID string, handler func(Stream))
+	NewStream(ctx context.Context, peer, protocolID string) (Stream, error)
+}
+
+// Node serves a tree over a host and requests proofs from other peers
+type Node struct {
+	h    Host
+	mu   sync.RWMutex
+	tree *merkle.MerkleTree
+	size int    // size of the served tree when it was set
+	root []byte // root of the served tree when it was set
+}
+
+// NewNode registers the protocol handler on the host, serving the tree; the
+// tree may be nil for a node that only makes requests. A served tree must not
+// be modified, since answers read it piecewise: requests fail with
+// ErrTreeModified once it changes, until a new tree is set with SetTree
+func NewNode(h Host, tree *merkle.MerkleTree) *Node {
+	n := &Node{h: h}
+	n.SetTree(tree)
+	h.SetStreamHandler(ProtocolID, n.handle)
+	return n
+}
+
+// SetTree replaces the served tree, such as with a copy the leaves were
+// appended to; the tree must not be modified while it is served
+func (n *Node) SetTree(tree *merkle.MerkleTree) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.tree, n.size, n.root = tree, 0, nil
+	if tree != nil {
+		n.size, n.root = len(tree.Leaves()), tree.Root()
+	}
+}
+
+// Request sends a request to a peer and returns its response; a response
+// carrying an error is returned as one
+func (n *Node) Request(ctx context.Context, peer string, req Request) (*Response, error) {
+	s, err := n.h.NewStream(ctx, peer, ProtocolID)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	if err := writeMessage(s, req); err != nil {
+		return nil, err
+	}
+	var resp Response
+	if err := readMessage(bufio.NewReader(s), &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("peer %s: %s", peer, resp.Error)
+	}
+	return &resp, nil
+}
+
+// handle answers the request read from a stream
+func (n *Node) handle(s Stream) {
+	defer s.Close()
+
+	var req Request
+	if err := readMessage(bufio.NewReader(s), &req); err != nil {
+		return
+	}
+	_ = writeMessage(s, n.answer(req))
+}
+
+// answer builds the response to a request from the served tree, checking
+// before and after reading it that the tree still has the size and root it
+// was set with, so a response never mixes two versions of the tree
+func (n *Node) answer(req Request) Response {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.tree == nil {
+		return Response{Error: "no tree is served"}
+	}
+	if n.modified() {
+		return Response{TreeSize: n.size, Root: n.root, Error: ErrTreeModified.Error()}
+	}
+
+	resp := Response{TreeSize: n.size, Root: n.root}
+	var err error
+	switch req.Type {
+	case LeafRequest:
+		if leaves := n.tree.Leaves(); req.Index < 0 || req.Index >= len(leaves) {
+			err = merkle.ErrOutOfRange
+		} else {
+			resp.Leaf = &leaves[req.Index]
+		}
+	case ProofRequest:
+		if req.Hash != nil {
+			resp.Proof, err = n.tree.GenerateProofByHash(req.Hash)
+		} else {
+			resp.Proof, err = n.tree.GenerateProofAt(req.Index)
+		}
+	case ConsistencyRequest:
+		resp.Consistency, err = n.tree.GenerateConsistencyProof(req.OldSize)
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnknownRequest, req.Type)
+	}
+	if err == nil && n.modified() {
+		err = ErrTreeModified
+	}
+	if err != nil {
+		return Response{TreeSize: n.size, Root: n.root, Error: err.Error()}
+	}
+	return resp
+}
+
+// modified reports whether the served tree changed since it was set
+func (n *Node) modified() bool {
+	return len(n.tree.Leaves()) != n.size || !bytes.Equal(n.tree.Root(), n.root)
+}
+
+// writeMessage writes a message as a uvarint length followed by its JSON
+func writeMessage(w io.Writer, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	msg := binary.AppendUvarint(nil, uint64(len(body)))
+	_, err = w.Write(append(msg, body...))
+	return err
+}
+
+// readMessage reads a message written by writeMessage
+func readMessage(r *bufio.Reader, v any) error {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return err
+	}
+	if size > maxMessageSize {
+		return ErrMessageSize
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
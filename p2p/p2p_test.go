@@ -0,0 +1,123 @@
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+// network connects in-process hosts by peer name over pipes
+type network map[string]*pipeHost
+
+type pipeHost struct {
+	net      network
+	handlers map[string]func(Stream)
+}
+
+func (n network) host(name string) *pipeHost {
+	h := &pipeHost{net: n, handlers: map[string]func(Stream){}}
+	n[name] = h
+	return h
+}
+
+func (h *pipeHost) SetStreamHandler(protocolID string, handler func(Stream)) {
+	h.handlers[protocolID] = handler
+}
+
+func (h *pipeHost) NewStream(ctx context.Context, peer, protocolID string) (Stream, error) {
+	remote, ok := h.net[peer]
+	if !ok || remote.handlers[protocolID] == nil {
+		return nil, fmt.Errorf("peer %s does not support %s", peer, protocolID)
+	}
+	local, conn := net.Pipe()
+	go remote.handlers[protocolID](conn)
+	return local, nil
+}
+
+func Test_Node(t *testing.T) {
+	ctx := context.Background()
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	tree, err := merkle.New(data, merkle.WithRFC6962())
+	require.NoError(t, err)
+	old, err := merkle.New(data[:3], merkle.WithRFC6962())
+	require.NoError(t, err)
+
+	peers := network{}
+	NewNode(peers.host("server"), tree)
+	client := NewNode(peers.host("client"), nil)
+
+	t.Run("should fetch a leaf by index", func(t *testing.T) {
+		resp, err := client.Request(ctx, "server", Request{Type: LeafRequest, Index: 2})
+		require.NoError(t, err)
+		require.Equal(t, 5, resp.TreeSize)
+		require.Equal(t, tree.Root(), resp.Root)
+		require.Equal(t, []byte("c"), resp.Leaf.Data)
+	})
+
+	t.Run("should fetch verifiable proofs by index and by hash", func(t *testing.T) {
+		resp, err := client.Request(ctx, "server", Request{Type: ProofRequest, Index: 3})
+		require.NoError(t, err)
+		require.True(t, tree.VerifyData([]byte("d"), resp.Proof))
+
+		leaf := tree.Leaves()[1].Hash
+		resp, err = client.Request(ctx, "server", Request{Type: ProofRequest, Hash: leaf})
+		require.NoError(t, err)
+		require.True(t, tree.VerifyProof(leaf, resp.Proof))
+	})
+
+	t.Run("should fetch verifiable consistency proofs", func(t *testing.T) {
+		resp, err := client.Request(ctx, "server", Request{Type: ConsistencyRequest, OldSize: 3})
+		require.NoError(t, err)
+		require.NoError(t, merkle.VerifyConsistency(3, resp.TreeSize, old.Root(), resp.Root, resp.Consistency, merkle.WithRFC6962()))
+	})
+
+	t.Run("should return errors reported by the peer", func(t *testing.T) {
+		_, err := client.Request(ctx, "server", Request{Type: LeafRequest, Index: 5})
+		require.ErrorContains(t, err, merkle.ErrOutOfRange.Error())
+		_, err = client.Request(ctx, "server", Request{Type: "unknown"})
+		require.ErrorContains(t, err, ErrUnknownRequest.Error())
+		_, err = client.Request(ctx, "client", Request{Type: LeafRequest})
+		require.ErrorContains(t, err, "no tree is served")
+	})
+
+	t.Run("should refuse to answer from a tree modified in place", func(t *testing.T) {
+		served, err := merkle.New(data[:3], merkle.WithRFC6962())
+		require.NoError(t, err)
+		server := NewNode(peers.host("modified"), served)
+		require.NoError(t, served.AddLeaf([]byte("d")))
+
+		_, err = client.Request(ctx, "modified", Request{Type: ProofRequest, Index: 3})
+		require.ErrorContains(t, err, ErrTreeModified.Error())
+
+		server.SetTree(served)
+		resp, err := client.Request(ctx, "modified", Request{Type: ProofRequest, Index: 3})
+		require.NoError(t, err)
+		require.Equal(t, 4, resp.TreeSize)
+		require.True(t, served.VerifyData([]byte("d"), resp.Proof))
+	})
+
+	t.Run("should serve a replaced tree", func(t *testing.T) {
+		server := NewNode(peers.host("replaced"), old)
+		server.SetTree(tree)
+		resp, err := client.Request(ctx, "replaced", Request{Type: LeafRequest})
+		require.NoError(t, err)
+		require.Equal(t, tree.Root(), resp.Root)
+	})
+}
+
+func Test_readMessage(t *testing.T) {
+	t.Run("should reject oversized messages", func(t *testing.T) {
+		local, remote := net.Pipe()
+		go func() {
+			_, _ = remote.Write([]byte{0xff, 0xff, 0xff, 0xff, 0x0f})
+			remote.Close()
+		}()
+		var req Request
+		require.ErrorIs(t, readMessage(bufio.NewReader(local), &req), ErrMessageSize)
+	})
+}
@@ -0,0 +1,214 @@
+package merkle
+
+import "bytes"
+
+// PersistentTree is an immutable Merkle tree: AddLeaf and UpdateLeaf return a
+// new tree that shares every unchanged subtree with the original, so snapshots
+// are cheap and concurrent readers never need locks. It honours the hashing
+// options of New; leaf sorting does not apply.
+type PersistentTree struct {
+	m      *MerkleTree
+	root   *pnode
+	size   int
+	height int
+}
+
+// pnode is an immutable node; leaves have no children, and an interior node
+// without a right child pairs its left child with itself (or promotes it)
+type pnode struct {
+	left  *pnode
+	right *pnode
+	hash  []byte
+	data  []byte
+}
+
+// NewPersistent creates a new persistent Merkle tree from a list of data
+func NewPersistent(data [][]byte, opts ...Option) (*PersistentTree, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyData
+	}
+
+	t := &PersistentTree{m: newVerifier(opts...), size: len(data)}
+	if err := t.m.checkFIPS(); err != nil {
+		return nil, err
+	}
+	for 1<<t.height < t.size {
+		t.height++
+	}
+
+	leaves := make([]*pnode, len(data))
+	for i, item := range data {
+		hash, err := t.m.hashData(item)
+		if err != nil {
+			return nil, err
+		}
+		leaves[i] = &pnode{hash: hash, data: item}
+	}
+	t.root = t.build(t.height, 0, leaves)
+
+	return t, nil
+}
+
+// Root returns the root hash of the tree
+func (t *PersistentTree) Root() []byte {
+	return t.root.hash
+}
+
+// Len returns the number of leaves
+func (t *PersistentTree) Len() int {
+	return t.size
+}
+
+// AddLeaf returns a new tree with the leaf appended
+func (t *PersistentTree) AddLeaf(data []byte) (*PersistentTree, error) {
+	hash, err := t.m.hashData(data)
+	if err != nil {
+		return nil, err
+	}
+	next := &PersistentTree{m: t.m, root: t.root, size: t.size + 1, height: t.height}
+	if t.size == 1<<t.height {
+		next.root = next.join(t.root, nil)
+		next.height++
+	}
+	next.root = next.set(next.root, next.height, t.size, &pnode{hash: hash, data: data})
+	return next, nil
+}
+
+// UpdateLeaf returns a new tree with the first leaf holding oldData replaced
+func (t *PersistentTree) UpdateLeaf(oldData, newData []byte) (*PersistentTree, error) {
+	hash, err := t.m.hashData(newData)
+	if err != nil {
+		return nil, err
+	}
+	index := t.indexOf(oldData)
+	if index < 0 {
+		return nil, ErrNotFoundData
+	}
+
+	next := *t
+	next.root = t.set(t.root, t.height, index, &pnode{hash: hash, data: newData})
+	return &next, nil
+}
+
+// GenerateProof generates a Merkle proof for a given leaf
+func (t *PersistentTree) GenerateProof(data []byte) (Proof, error) {
+	index := t.indexOf(data)
+	if index < 0 {
+		return nil, ErrNotFoundData
+	}
+	return t.GenerateProofAt(index)
+}
+
+// GenerateProofAt generates a Merkle proof for the leaf at the given index
+func (t *PersistentTree) GenerateProofAt(index int) (Proof, error) {
+	if index < 0 || index >= t.size {
+		return nil, ErrOutOfRange
+	}
+
+	// collect siblings top-down, then reverse them into the leaf-to-root order
+	var proof Proof
+	node := t.root
+	for level := t.height; level > 0; level-- {
+		goRight := index>>(level-1)&1 == 1
+		switch {
+		case goRight:
+			proof = append(proof, ProofElement{Hash: node.left.hash, Side: Left})
+			node = node.right
+		case node.right != nil:
+			proof = append(proof, ProofElement{Hash: node.right.hash, Side: Right})
+			node = node.left
+		case !t.m.promoteOdd:
+			proof = append(proof, ProofElement{Hash: node.left.hash, Side: Right})
+			node = node.left
+		default:
+			node = node.left
+		}
+	}
+
+	for i, j := 0, len(proof)-1; i < j; i, j = i+1, j-1 {
+		proof[i], proof[j] = proof[j], proof[i]
+	}
+	return proof, nil
+}
+
+// VerifyProof verifies a Merkle proof against the root of this version
+func (t *PersistentTree) VerifyProof(hash []byte, proof Proof) bool {
+	return bytes.Equal(t.m.rootFromProof(t.m.newHash(), hash, proof), t.root.hash)
+}
+
+// VerifyData verifies a Merkle proof for given data
+func (t *PersistentTree) VerifyData(data []byte, proof Proof) bool {
+	hash, err := t.m.hashData(data)
+	return err == nil && t.VerifyProof(hash, proof)
+}
+
+// build builds the subtree at the given level and index over its leaves
+func (t *PersistentTree) build(level, index int, leaves []*pnode) *pnode {
+	if level == 0 {
+		return leaves[index]
+	}
+
+	left := t.build(level-1, index*2, leaves)
+	var right *pnode
+	if (index*2+1)<<(level-1) < len(leaves) {
+		right = t.build(level-1, index*2+1, leaves)
+	}
+	return t.join(left, right)
+}
+
+// set returns a copy of the subtree with the leaf at index replaced, copying
+// only the nodes on the path to it
+func (t *PersistentTree) set(node *pnode, level, index int, leaf *pnode) *pnode {
+	if level == 0 {
+		return leaf
+	}
+
+	var left, right *pnode
+	if node != nil {
+		left, right = node.left, node.right
+	}
+	if index>>(level-1)&1 == 1 {
+		right = t.set(right, level-1, index, leaf)
+	} else {
+		left = t.set(left, level-1, index, leaf)
+	}
+	return t.join(left, right)
+}
+
+// join creates an interior node from its children
+func (t *PersistentTree) join(left, right *pnode) *pnode {
+	node := &pnode{left: left, right: right}
+	switch {
+	case right != nil:
+		node.hash = t.m.hashNode(left.hash, right.hash)
+	case t.m.promoteOdd:
+		node.hash = left.hash
+	default:
+		node.hash = t.m.hashNode(left.hash, left.hash)
+	}
+	return node
+}
+
+// indexOf returns the index of the first leaf holding the given data, or -1
+func (t *PersistentTree) indexOf(data []byte) int {
+	index := 0
+	var find func(node *pnode, level int) bool
+	find = func(node *pnode, level int) bool {
+		if node == nil {
+			return false
+		}
+		if level == 0 {
+			if bytes.Equal(node.data, data) {
+				return true
+			}
+			index++
+			return false
+		}
+		return find(node.left, level-1) || find(node.right, level-1)
+	}
+
+	if !find(t.root, t.height) {
+		return -1
+	}
+	return index
+}
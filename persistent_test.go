@@ -0,0 +1,85 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewPersistent(t *testing.T) {
+	t.Run("should match the mutable tree", func(t *testing.T) {
+		data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+		tree, err := NewPersistent(data, WithHashFunction(mockHash))
+		require.NoError(t, err)
+		require.Equal(t, "hash(hash(hash(a)hash(b))hash(hash(c)hash(c)))", string(tree.Root()))
+		require.Equal(t, 3, tree.Len())
+	})
+
+	t.Run("should return error for empty data", func(t *testing.T) {
+		_, err := NewPersistent(nil)
+		require.ErrorIs(t, err, ErrEmptyData)
+	})
+}
+
+func Test_PersistentTree_AddLeaf(t *testing.T) {
+	for name, opts := range map[string][]Option{"default": nil, "rfc6962": {WithRFC6962()}} {
+		t.Run("should keep every version intact in "+name+" mode", func(t *testing.T) {
+			data := [][]byte{[]byte("0")}
+			versions := []*PersistentTree{}
+
+			p, err := NewPersistent(data, opts...)
+			require.NoError(t, err)
+			versions = append(versions, p)
+
+			for n := 1; n < 20; n++ {
+				item := []byte(fmt.Sprint(n))
+				data = append(data, item)
+				p, err = p.AddLeaf(item)
+				require.NoError(t, err)
+				versions = append(versions, p)
+			}
+
+			for i, v := range versions {
+				tree, err := New(data[:i+1], opts...)
+				require.NoError(t, err)
+				require.Equal(t, tree.Root(), v.Root(), "version %d", i)
+
+				for j, item := range data[:i+1] {
+					proof, err := v.GenerateProofAt(j)
+					require.NoError(t, err)
+					expected, err := tree.GenerateProof(item)
+					require.NoError(t, err)
+					require.Equal(t, expected, proof)
+					require.True(t, v.VerifyData(item, proof))
+				}
+			}
+		})
+	}
+}
+
+func Test_PersistentTree_UpdateLeaf(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
+	original, err := NewPersistent(data, WithHashFunction(mockHash))
+	require.NoError(t, err)
+
+	t.Run("should return a new version and share unchanged subtrees", func(t *testing.T) {
+		updated, err := original.UpdateLeaf([]byte("d"), []byte("d2"))
+		require.NoError(t, err)
+
+		require.Equal(t, "hash(hash(hash(a)hash(b))hash(hash(c)hash(d)))", string(original.Root()))
+		require.Equal(t, "hash(hash(hash(a)hash(b))hash(hash(c)hash(d2)))", string(updated.Root()))
+		require.Same(t, original.root.left, updated.root.left)
+		require.Same(t, original.root.right.left, updated.root.right.left)
+
+		proof, err := updated.GenerateProof([]byte("d2"))
+		require.NoError(t, err)
+		require.True(t, updated.VerifyData([]byte("d2"), proof))
+		require.False(t, original.VerifyData([]byte("d2"), proof))
+	})
+
+	t.Run("should return error for non-existent leaf", func(t *testing.T) {
+		_, err := original.UpdateLeaf([]byte("e"), []byte("e2"))
+		require.ErrorIs(t, err, ErrNotFoundData)
+	})
+}
@@ -0,0 +1,139 @@
+// Package qr encodes roots and compressed proofs as QR codes and decodes them
+// from scanned images, for offline and air-gapped verification flows such as
+// paper backups and physical audit trails.
+//
+// A root code holds the text "merkle:root:<root>"; a proof code holds
+// "merkle:proof:<root>:<proof>", where the proof is a CompressedProof in its
+// binary encoding. Both are unpadded base64url.
+//
+// The package is a module of its own, so the QR codec is not a dependency of
+// the merkle module.
+package qr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/png"
+	"strings"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/makiuchi-d/gozxing"
+	zxingqr "github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/skip2/go-qrcode"
+)
+
+var (
+	ErrInvalidPayload = errors.New("not a merkle QR payload")
+	ErrNoProof        = errors.New("QR payload holds no proof")
+)
+
+const (
+	rootPrefix  = "merkle:root:"
+	proofPrefix = "merkle:proof:"
+)
+
+// Payload is the content of a QR code: a root and, for proof codes, the
+// compressed proof of a leaf under it
+type Payload struct {
+	Root  []byte
+	Proof *merkle.CompressedProof
+}
+
+// RootPayload returns the payload of a root code for the tree
+func RootPayload(m *merkle.MerkleTree) Payload {
+	return Payload{Root: m.Root()}
+}
+
+// ProofPayload returns the payload of a proof code for the leaf at index
+func ProofPayload(m *merkle.MerkleTree, index int) (Payload, error) {
+	proof, err := m.GenerateProofAt(index)
+	if err != nil {
+		return Payload{}, err
+	}
+	cp := m.CompressProof(m.Leaves()[index].Hash, proof)
+	return Payload{Root: m.Root(), Proof: &cp}, nil
+}
+
+// String returns the text the QR code holds
+func (p Payload) String() string {
+	root := base64.RawURLEncoding.EncodeToString(p.Root)
+	if p.Proof == nil {
+		return rootPrefix + root
+	}
+	proof, _ := p.Proof.MarshalBinary()
+	return proofPrefix + root + ":" + base64.RawURLEncoding.EncodeToString(proof)
+}
+
+// ParsePayload parses the text of a QR code
+func ParsePayload(s string) (Payload, error) {
+	if root, ok := strings.CutPrefix(s, rootPrefix); ok {
+		b, err := base64.RawURLEncoding.DecodeString(root)
+		if err != nil || len(b) == 0 {
+			return Payload{}, fmt.Errorf("%w: bad root", ErrInvalidPayload)
+		}
+		return Payload{Root: b}, nil
+	}
+
+	rest, ok := strings.CutPrefix(s, proofPrefix)
+	root, proof, found := strings.Cut(rest, ":")
+	if !ok || !found {
+		return Payload{}, ErrInvalidPayload
+	}
+	p := Payload{Proof: &merkle.CompressedProof{}}
+	var err error
+	if p.Root, err = base64.RawURLEncoding.DecodeString(root); err != nil || len(p.Root) == 0 {
+		return Payload{}, fmt.Errorf("%w: bad root", ErrInvalidPayload)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(proof)
+	if err != nil {
+		return Payload{}, fmt.Errorf("%w: bad proof", ErrInvalidPayload)
+	}
+	if err := p.Proof.UnmarshalBinary(b); err != nil {
+		return Payload{}, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	return p, nil
+}
+
+// Verify verifies the payload's proof for data against its root; opts give
+// the hashing options the tree was built with. The root should be checked
+// against one obtained separately, such as from a root code.
+func (p Payload) Verify(data []byte, opts ...merkle.Option) error {
+	if p.Proof == nil {
+		return ErrNoProof
+	}
+	if !merkle.VerifyCompressedDataProof(p.Root, data, *p.Proof, opts...) {
+		return merkle.ErrInvalidProof
+	}
+	return nil
+}
+
+// Encode renders the payload as a PNG QR code of size by size pixels, with
+// medium error correction
+func Encode(p Payload, size int) ([]byte, error) {
+	return qrcode.Encode(p.String(), qrcode.Medium, size)
+}
+
+// Decode reads the payload of a QR code from an image, such as a scan
+func Decode(img image.Image) (Payload, error) {
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return Payload{}, err
+	}
+	result, err := zxingqr.NewQRCodeReader().Decode(bmp, nil)
+	if err != nil {
+		return Payload{}, err
+	}
+	return ParsePayload(result.GetText())
+}
+
+// DecodePNG reads the payload of a QR code from a PNG image
+func DecodePNG(b []byte) (Payload, error) {
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return Payload{}, err
+	}
+	return Decode(img)
+}
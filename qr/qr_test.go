@@ -0,0 +1,69 @@
+package qr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+func newTree(t *testing.T, n int) *merkle.MerkleTree {
+	var data [][]byte
+	for i := 0; i < n; i++ {
+		data = append(data, []byte(fmt.Sprint("record", i)))
+	}
+	tree, err := merkle.New(data)
+	require.NoError(t, err)
+	return tree
+}
+
+func Test_Payload(t *testing.T) {
+	tree := newTree(t, 7)
+
+	t.Run("should round trip root and proof payloads as text", func(t *testing.T) {
+		root := RootPayload(tree)
+		parsed, err := ParsePayload(root.String())
+		require.NoError(t, err)
+		require.Equal(t, root, parsed)
+
+		proof, err := ProofPayload(tree, 6)
+		require.NoError(t, err)
+		parsed, err = ParsePayload(proof.String())
+		require.NoError(t, err)
+		require.Equal(t, proof, parsed)
+		require.NoError(t, parsed.Verify([]byte("record6")))
+		require.ErrorIs(t, parsed.Verify([]byte("record5")), merkle.ErrInvalidProof)
+	})
+
+	t.Run("should not verify root payloads", func(t *testing.T) {
+		require.ErrorIs(t, RootPayload(tree).Verify([]byte("record0")), ErrNoProof)
+	})
+
+	t.Run("should reject other text", func(t *testing.T) {
+		for _, s := range []string{"", "https://example.com", "merkle:root:", "merkle:root:!!", "merkle:proof:AAAA", "merkle:proof:AAAA:AQ"} {
+			_, err := ParsePayload(s)
+			require.ErrorIs(t, err, ErrInvalidPayload, s)
+		}
+	})
+}
+
+func Test_QR(t *testing.T) {
+	t.Run("should decode the codes it encodes", func(t *testing.T) {
+		tree := newTree(t, 1000)
+		for _, p := range []Payload{RootPayload(tree), must(ProofPayload(tree, 123))} {
+			png, err := Encode(p, 512)
+			require.NoError(t, err)
+			decoded, err := DecodePNG(png)
+			require.NoError(t, err)
+			require.Equal(t, p, decoded)
+		}
+	})
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
@@ -0,0 +1,146 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrUnknownSigner = errors.New("vote from an unknown signer")
+	ErrEquivocation  = errors.New("signer voted for two roots at the same size")
+	ErrNoQuorum      = errors.New("too few valid votes for a quorum")
+)
+
+// TreeHead is the size and root of a tree, as signed by a replica
+type TreeHead struct {
+	Size int    `json:"size"`
+	Root []byte `json:"root"`
+}
+
+// RootVote is a replica's signature over a tree head
+type RootVote struct {
+	Signer    string   `json:"signer"`
+	Head      TreeHead `json:"head"`
+	Signature []byte   `json:"signature"`
+}
+
+// QuorumCertificate is a tree head with the votes of a quorum of signers
+type QuorumCertificate struct {
+	Head  TreeHead   `json:"head"`
+	Votes []RootVote `json:"votes"`
+}
+
+// SignRoot signs the tree head of a tree as the named replica
+func SignRoot(signer string, key ed25519.PrivateKey, m *MerkleTree) (RootVote, error) {
+	head := TreeHead{Size: len(m.Leaves()), Root: m.Root()}
+	msg, err := json.Marshal(head)
+	if err != nil {
+		return RootVote{}, err
+	}
+	return RootVote{Signer: signer, Head: head, Signature: ed25519.Sign(key, msg)}, nil
+}
+
+// verify checks the vote's signature with the signer's key
+func (v RootVote) verify(keys map[string]ed25519.PublicKey) error {
+	pub, ok := keys[v.Signer]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownSigner, v.Signer)
+	}
+	msg, err := json.Marshal(v.Head)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, msg, v.Signature) {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, v.Signer)
+	}
+	return nil
+}
+
+// Verify checks that threshold distinct known signers signed the head
+func (c QuorumCertificate) Verify(keys map[string]ed25519.PublicKey, threshold int) error {
+	signers := map[string]bool{}
+	for _, v := range c.Votes {
+		if signers[v.Signer] || v.Head.Size != c.Head.Size || !bytes.Equal(v.Head.Root, c.Head.Root) {
+			continue
+		}
+		if err := v.verify(keys); err != nil {
+			return err
+		}
+		signers[v.Signer] = true
+	}
+	if threshold <= 0 || len(signers) < threshold {
+		return ErrNoQuorum
+	}
+	return nil
+}
+
+// QuorumCollector collects root votes from replica signers and publishes a
+// tree head only once threshold of them signed the same root, so a single
+// compromised replica cannot publish a bogus root. It is safe for concurrent use.
+type QuorumCollector struct {
+	mu        sync.Mutex
+	keys      map[string]ed25519.PublicKey
+	threshold int
+	publish   func(QuorumCertificate) error
+	votes     map[int]map[string]RootVote
+	published map[int]bool
+}
+
+// NewQuorumCollector creates a collector for the signers' keys, by name
+func NewQuorumCollector(keys map[string]ed25519.PublicKey, threshold int, publish func(QuorumCertificate) error) *QuorumCollector {
+	return &QuorumCollector{
+		keys:      keys,
+		threshold: threshold,
+		publish:   publish,
+		votes:     map[int]map[string]RootVote{},
+		published: map[int]bool{},
+	}
+}
+
+// Add records a vote, publishing its head if it completes a quorum, and
+// reports whether it did. A signer voting for two roots at the same size is
+// rejected with ErrEquivocation. Votes for an already published size are ignored.
+func (q *QuorumCollector) Add(v RootVote) (bool, error) {
+	if err := v.verify(q.keys); err != nil {
+		return false, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.published[v.Head.Size] {
+		return false, nil
+	}
+
+	votes := q.votes[v.Head.Size]
+	if votes == nil {
+		votes = map[string]RootVote{}
+		q.votes[v.Head.Size] = votes
+	}
+	if prev, ok := votes[v.Signer]; ok && !bytes.Equal(prev.Head.Root, v.Head.Root) {
+		return false, fmt.Errorf("%w: %s at size %d", ErrEquivocation, v.Signer, v.Head.Size)
+	}
+	votes[v.Signer] = v
+
+	c := QuorumCertificate{Head: v.Head}
+	for _, vote := range votes {
+		if bytes.Equal(vote.Head.Root, v.Head.Root) {
+			c.Votes = append(c.Votes, vote)
+		}
+	}
+	if len(c.Votes) < q.threshold {
+		return false, nil
+	}
+	sort.Slice(c.Votes, func(i, j int) bool { return c.Votes[i].Signer < c.Votes[j].Signer })
+
+	if err := q.publish(c); err != nil {
+		return false, err
+	}
+	q.published[v.Head.Size] = true
+	delete(q.votes, v.Head.Size)
+	return true, nil
+}
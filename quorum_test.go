@@ -0,0 +1,89 @@
+package merkle
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_QuorumCollector(t *testing.T) {
+	keys := map[string]ed25519.PublicKey{}
+	privs := map[string]ed25519.PrivateKey{}
+	for _, name := range []string{"r1", "r2", "r3"} {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+		keys[name], privs[name] = pub, priv
+	}
+
+	tree, err := New([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	require.NoError(t, err)
+	bogus, err := New([][]byte{[]byte("a"), []byte("b"), []byte("x")})
+	require.NoError(t, err)
+
+	vote := func(name string, m *MerkleTree) RootVote {
+		v, err := SignRoot(name, privs[name], m)
+		require.NoError(t, err)
+		return v
+	}
+
+	t.Run("should publish once a quorum signs the same root", func(t *testing.T) {
+		var certs []QuorumCertificate
+		q := NewQuorumCollector(keys, 2, func(c QuorumCertificate) error {
+			certs = append(certs, c)
+			return nil
+		})
+
+		ok, err := q.Add(vote("r1", tree))
+		require.NoError(t, err)
+		require.False(t, ok)
+		ok, err = q.Add(vote("r3", bogus))
+		require.NoError(t, err)
+		require.False(t, ok)
+		ok, err = q.Add(vote("r2", tree))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Len(t, certs, 1)
+		require.Equal(t, TreeHead{Size: 3, Root: tree.Root()}, certs[0].Head)
+		require.Equal(t, "r1", certs[0].Votes[0].Signer)
+		require.NoError(t, certs[0].Verify(keys, 2))
+		require.ErrorIs(t, certs[0].Verify(keys, 3), ErrNoQuorum)
+
+		ok, err = q.Add(vote("r3", tree))
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Len(t, certs, 1)
+	})
+
+	t.Run("should reject equivocating signers", func(t *testing.T) {
+		q := NewQuorumCollector(keys, 2, func(QuorumCertificate) error { return nil })
+		_, err := q.Add(vote("r1", bogus))
+		require.NoError(t, err)
+		_, err = q.Add(vote("r1", tree))
+		require.ErrorIs(t, err, ErrEquivocation)
+
+		ok, err := q.Add(vote("r1", bogus))
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("should reject votes from unknown signers or with bad signatures", func(t *testing.T) {
+		q := NewQuorumCollector(keys, 1, func(QuorumCertificate) error { return nil })
+		v := vote("r1", tree)
+		v.Signer = "r4"
+		_, err := q.Add(v)
+		require.ErrorIs(t, err, ErrUnknownSigner)
+
+		v = vote("r1", tree)
+		v.Head.Root = bogus.Root()
+		_, err = q.Add(v)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("should not count duplicate votes in a certificate", func(t *testing.T) {
+		v := vote("r1", tree)
+		c := QuorumCertificate{Head: v.Head, Votes: []RootVote{v, v}}
+		require.ErrorIs(t, c.Verify(keys, 2), ErrNoQuorum)
+	})
+}
@@ -0,0 +1,183 @@
+// Package replicate streams the appends of a leader tree to followers, which
+// recompute the root independently and cross-check it against the leader's,
+// surfacing any divergence as an alert.
+package replicate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/chakra-guy/merkle"
+)
+
+var (
+	ErrDivergence = errors.New("follower root diverges from the leader")
+	ErrGap        = errors.New("batch does not follow the replicated leaves")
+)
+
+// Batch is a run of leaves appended by the leader after From leaves, with the
+// leader's root over the first From+len(Leaves) leaves
+type Batch struct {
+	From   int      `json:"from"`
+	Leaves [][]byte `json:"leaves"`
+	Root   []byte   `json:"root"`
+}
+
+// Divergence reports a root mismatch between the leader and a follower
+type Divergence struct {
+	Follower     string
+	Size         int
+	LeaderRoot   []byte
+	FollowerRoot []byte
+}
+
+// Leader appends leaves to its tree and sends each batch to every follower,
+// such as over a cross-region stream
+type Leader struct {
+	mu        sync.Mutex
+	opts      []merkle.Option
+	tree      *merkle.MerkleTree
+	size      int
+	followers map[string]func(Batch) error
+}
+
+// NewLeader creates a leader with an empty tree
+func NewLeader(opts ...merkle.Option) *Leader {
+	return &Leader{opts: opts, followers: map[string]func(Batch) error{}}
+}
+
+// AddFollower registers how batches are sent to a follower. A follower joining
+// a non-empty leader is first sent a snapshot of every leaf.
+func (l *Leader) AddFollower(name string, send func(Batch) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.tree != nil {
+		if err := send(l.snapshot()); err != nil {
+			return err
+		}
+	}
+	l.followers[name] = send
+	return nil
+}
+
+// RemoveFollower stops sending batches to a follower
+func (l *Leader) RemoveFollower(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.followers, name)
+}
+
+// Append adds leaves to the tree and sends the batch to every follower; once
+// the leaves are added, the returned error joins the send failures
+func (l *Leader) Append(leaves [][]byte) (Batch, error) {
+	if len(leaves) == 0 {
+		return Batch{}, merkle.ErrEmptyData
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.tree == nil {
+		tree, err := merkle.New(leaves, l.opts...)
+		if err != nil {
+			return Batch{}, err
+		}
+		l.tree = tree
+	} else if err := l.tree.AddLeaves(leaves); err != nil {
+		return Batch{}, err
+	}
+	b := Batch{From: l.size, Leaves: leaves, Root: l.tree.Root()}
+	l.size += len(leaves)
+
+	var errs []error
+	for name, send := range l.followers {
+		if err := send(b); err != nil {
+			errs = append(errs, fmt.Errorf("follower %s: %w", name, err))
+		}
+	}
+	return b, errors.Join(errs...)
+}
+
+// Root returns the leader's root, or nil before the first append
+func (l *Leader) Root() []byte {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.tree == nil {
+		return nil
+	}
+	return l.tree.Root()
+}
+
+// snapshot returns a batch of every leaf; the lock must be held
+func (l *Leader) snapshot() Batch {
+	leaves := make([][]byte, 0, l.size)
+	for _, leaf := range l.tree.Leaves() {
+		leaves = append(leaves, leaf.Data)
+	}
+	return Batch{Leaves: leaves, Root: l.tree.Root()}
+}
+
+// Follower applies the leader's batches to its own tree
+type Follower struct {
+	mu    sync.Mutex
+	name  string
+	opts  []merkle.Option
+	alert func(Divergence)
+	tree  *merkle.MerkleTree
+	size  int
+}
+
+// NewFollower creates a follower with an empty tree; alert, if set, is called
+// on every divergence
+func NewFollower(name string, alert func(Divergence), opts ...merkle.Option) *Follower {
+	return &Follower{name: name, opts: opts, alert: alert}
+}
+
+// Apply appends a batch and cross-checks the recomputed root against the
+// leader's. The follower keeps its own root on divergence, since it is
+// computed from the leaves themselves, and returns ErrDivergence.
+func (f *Follower) Apply(b Batch) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if b.From != f.size || len(b.Leaves) == 0 {
+		return fmt.Errorf("%w: batch from %d, %d leaves replicated", ErrGap, b.From, f.size)
+	}
+
+	if f.tree == nil {
+		tree, err := merkle.New(b.Leaves, f.opts...)
+		if err != nil {
+			return err
+		}
+		f.tree = tree
+	} else if err := f.tree.AddLeaves(b.Leaves); err != nil {
+		return err
+	}
+	f.size += len(b.Leaves)
+
+	if root := f.tree.Root(); !bytes.Equal(root, b.Root) {
+		d := Divergence{Follower: f.name, Size: f.size, LeaderRoot: b.Root, FollowerRoot: root}
+		if f.alert != nil {
+			f.alert(d)
+		}
+		return fmt.Errorf("%w: %s at size %d", ErrDivergence, f.name, f.size)
+	}
+	return nil
+}
+
+// Root returns the follower's root, or nil before the first batch
+func (f *Follower) Root() []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.tree == nil {
+		return nil
+	}
+	return f.tree.Root()
+}
+
+// Size returns the number of replicated leaves
+func (f *Follower) Size() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.size
+}
@@ -0,0 +1,98 @@
+package replicate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Replication(t *testing.T) {
+	batches := [][][]byte{
+		{[]byte("a"), []byte("b")},
+		{[]byte("c")},
+		{[]byte("d"), []byte("e")},
+	}
+
+	t.Run("should replicate the leader root to followers", func(t *testing.T) {
+		l := NewLeader()
+		f := NewFollower("eu", nil)
+		require.NoError(t, l.AddFollower("eu", f.Apply))
+		for _, b := range batches {
+			_, err := l.Append(b)
+			require.NoError(t, err)
+		}
+
+		expected, err := merkle.New([][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")})
+		require.NoError(t, err)
+		require.Equal(t, expected.Root(), l.Root())
+		require.Equal(t, l.Root(), f.Root())
+		require.Equal(t, 5, f.Size())
+	})
+
+	t.Run("should catch up a late follower from a snapshot", func(t *testing.T) {
+		l := NewLeader()
+		_, err := l.Append(batches[0])
+		require.NoError(t, err)
+
+		f := NewFollower("us", nil)
+		require.NoError(t, l.AddFollower("us", f.Apply))
+		_, err = l.Append(batches[1])
+		require.NoError(t, err)
+		require.Equal(t, l.Root(), f.Root())
+	})
+
+	t.Run("should alert on divergence", func(t *testing.T) {
+		var alerts []Divergence
+		l := NewLeader()
+		f := NewFollower("ap", func(d Divergence) { alerts = append(alerts, d) }, merkle.WithOddNodePromotion())
+		require.NoError(t, l.AddFollower("ap", f.Apply))
+
+		_, err := l.Append(batches[0])
+		require.NoError(t, err)
+		_, err = l.Append(batches[1])
+		require.ErrorIs(t, err, ErrDivergence)
+		require.Len(t, alerts, 1)
+		require.Equal(t, Divergence{Follower: "ap", Size: 3, LeaderRoot: l.Root(), FollowerRoot: f.Root()}, alerts[0])
+	})
+
+	t.Run("should reject batches out of sequence", func(t *testing.T) {
+		f := NewFollower("eu", nil)
+		require.ErrorIs(t, f.Apply(Batch{From: 2, Leaves: batches[1]}), ErrGap)
+		require.ErrorIs(t, f.Apply(Batch{}), ErrGap)
+	})
+
+	t.Run("should not count or send leaves the tree rejects", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+		l := NewLeader(merkle.WithPreimageAudit(), merkle.WithLogger(logger))
+		var sent int
+		require.NoError(t, l.AddFollower("eu", func(Batch) error { sent++; return nil }))
+		_, err := l.Append(batches[0])
+		require.NoError(t, err)
+
+		_, err = l.Append([][]byte{[]byte("c"), make([]byte, 2*sha256.Size)})
+		require.ErrorIs(t, err, merkle.ErrInteriorPreimage)
+		b, err := l.Append(batches[1])
+		require.NoError(t, err)
+		require.Equal(t, 2, b.From)
+		require.Equal(t, 2, sent)
+	})
+
+	t.Run("should report send failures and stop sending to removed followers", func(t *testing.T) {
+		failure := errors.New("failure")
+		l := NewLeader()
+		require.NoError(t, l.AddFollower("down", func(Batch) error { return failure }))
+		_, err := l.Append(batches[0])
+		require.ErrorIs(t, err, failure)
+
+		l.RemoveFollower("down")
+		_, err = l.Append(batches[1])
+		require.NoError(t, err)
+		_, err = l.Append(nil)
+		require.ErrorIs(t, err, merkle.ErrEmptyData)
+	})
+}
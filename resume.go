@@ -0,0 +1,95 @@
+package merkle
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrInvalidCheckpoint = errors.New("invalid appender checkpoint")
+
+// ResumableSource is a LeafSource that can report and restore its position,
+// such as a file offset or a database key
+type ResumableSource interface {
+	LeafSource
+	// Cursor returns the position right after the last leaf returned by Next
+	Cursor() ([]byte, error)
+	// Seek moves to a position returned by Cursor
+	Seek(cursor []byte) error
+}
+
+// Checkpoint is the state of an interrupted root computation: the frontier
+// and size of its compact appender, and the source position to resume from
+type Checkpoint struct {
+	Size     int      `json:"size"`
+	Frontier [][]byte `json:"frontier"`
+	Cursor   []byte   `json:"cursor,omitempty"`
+}
+
+// ResumeConfig configures RootFromResumableSource. Save is called with a
+// checkpoint every Every leaves; From, if set, resumes from a saved checkpoint.
+type ResumeConfig struct {
+	Every int
+	Save  func(Checkpoint) error
+	From  *Checkpoint
+}
+
+// Checkpoint returns the appender state; the source cursor is left to the caller
+func (c *CompactAppender) Checkpoint() Checkpoint {
+	return Checkpoint{Size: c.size, Frontier: append([][]byte{}, c.frontier...)}
+}
+
+// ResumeCompactAppender recreates an appender from a checkpoint taken with the
+// same options
+func ResumeCompactAppender(cp Checkpoint, opts ...Option) (*CompactAppender, error) {
+	if cp.Size < 0 || cp.Size>>len(cp.Frontier) != 0 {
+		return nil, ErrInvalidCheckpoint
+	}
+	for i, hash := range cp.Frontier {
+		if (cp.Size>>i&1 == 1) != (hash != nil) {
+			return nil, ErrInvalidCheckpoint
+		}
+	}
+
+	c := NewCompactAppender(opts...)
+	c.size, c.frontier = cp.Size, append([][]byte{}, cp.Frontier...)
+	return c, nil
+}
+
+// RootFromResumableSource computes the root over every leaf of the source like
+// RootFromSource, saving checkpoints along the way so a multi-hour build can
+// resume after an interruption instead of restarting from leaf zero
+func RootFromResumableSource(src ResumableSource, cfg ResumeConfig, opts ...Option) ([]byte, error) {
+	c := NewCompactAppender(opts...)
+	if cfg.From != nil {
+		var err error
+		if c, err = ResumeCompactAppender(*cfg.From, opts...); err != nil {
+			return nil, err
+		}
+		if err := src.Seek(cfg.From.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		data, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return c.Root()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := c.Append(data); err != nil {
+			return nil, err
+		}
+
+		if cfg.Save != nil && cfg.Every > 0 && c.size%cfg.Every == 0 {
+			cp := c.Checkpoint()
+			if cp.Cursor, err = src.Cursor(); err != nil {
+				return nil, err
+			}
+			if err := cfg.Save(cp); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
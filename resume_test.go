@@ -0,0 +1,87 @@
+package merkle
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// cursorSource is a resumable source over a slice, failing once it reaches failAt
+type cursorSource struct {
+	leaves [][]byte
+	pos    int
+	failAt int
+}
+
+func (s *cursorSource) Next() ([]byte, error) {
+	if s.pos == s.failAt {
+		return nil, errors.New("interrupted")
+	}
+	if s.pos == len(s.leaves) {
+		return nil, io.EOF
+	}
+	s.pos++
+	return s.leaves[s.pos-1], nil
+}
+
+func (s *cursorSource) Cursor() ([]byte, error) {
+	return binary.AppendUvarint(nil, uint64(s.pos)), nil
+}
+
+func (s *cursorSource) Seek(cursor []byte) error {
+	pos, n := binary.Uvarint(cursor)
+	if n <= 0 || pos > uint64(len(s.leaves)) {
+		return ErrInvalidCheckpoint
+	}
+	s.pos = int(pos)
+	return nil
+}
+
+func Test_RootFromResumableSource(t *testing.T) {
+	var data [][]byte
+	for i := 0; i < 37; i++ {
+		data = append(data, []byte(fmt.Sprint(i)))
+	}
+
+	for name, opts := range map[string][]Option{"default": nil, "rfc6962": {WithRFC6962()}} {
+		tree, err := New(data, opts...)
+		require.NoError(t, err)
+
+		t.Run("should resume from the last checkpoint in mode "+name, func(t *testing.T) {
+			var saved []byte
+			save := func(cp Checkpoint) error {
+				var err error
+				saved, err = json.Marshal(cp)
+				return err
+			}
+
+			_, err := RootFromResumableSource(&cursorSource{leaves: data, failAt: 23}, ResumeConfig{Every: 5, Save: save}, opts...)
+			require.Error(t, err)
+
+			var cp Checkpoint
+			require.NoError(t, json.Unmarshal(saved, &cp))
+			require.Equal(t, 20, cp.Size)
+
+			root, err := RootFromResumableSource(&cursorSource{leaves: data, failAt: -1}, ResumeConfig{From: &cp}, opts...)
+			require.NoError(t, err)
+			require.Equal(t, tree.Root(), root)
+		})
+	}
+
+	t.Run("should reject inconsistent checkpoints", func(t *testing.T) {
+		for _, cp := range []Checkpoint{
+			{Size: -1},
+			{Size: 4, Frontier: [][]byte{nil, []byte("x")}},
+			{Size: 3, Frontier: [][]byte{[]byte("x"), nil}},
+			{Size: 1, Frontier: [][]byte{nil}},
+		} {
+			_, err := ResumeCompactAppender(cp)
+			require.ErrorIs(t, err, ErrInvalidCheckpoint)
+		}
+	})
+}
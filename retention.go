@@ -0,0 +1,88 @@
+package merkle
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var ErrSnapshotPruned = errors.New("snapshot was pruned by the retention policy")
+
+// RetentionPolicy selects the snapshots a Catalog keeps, or the versions
+// WithVersionHistory keeps; a snapshot is retained if any rule keeps it. The
+// most recent snapshot and pinned snapshots are always retained, and the zero
+// policy retains every snapshot.
+type RetentionPolicy struct {
+	// KeepLast keeps the given number of most recent snapshots
+	KeepLast int
+	// KeepEvery keeps every Kth snapshot in recording order, starting with the
+	// first, as a sparse history for audits
+	KeepEvery int
+}
+
+// keeps reports whether the policy retains the snapshot recorded at seq,
+// given the number of snapshots recorded
+func (p RetentionPolicy) keeps(seq, recorded int) bool {
+	if p.KeepLast <= 0 && p.KeepEvery <= 0 {
+		return true
+	}
+	return seq == recorded-1 ||
+		p.KeepLast > 0 && seq >= recorded-p.KeepLast ||
+		p.KeepEvery > 0 && seq%p.KeepEvery == 0
+}
+
+// SetRetention sets the retention policy and prunes the snapshots it does not
+// retain; later snapshots are pruned as they are recorded
+func (c *Catalog) SetRetention(p RetentionPolicy) []string {
+	c.retention = p
+	return c.Prune()
+}
+
+// Pin retains a snapshot regardless of the retention policy
+func (c *Catalog) Pin(id string) error {
+	if _, err := c.snapshot(id); err != nil {
+		return err
+	}
+	c.pinned[id] = true
+	return nil
+}
+
+// Unpin returns a snapshot to the retention policy, which may prune it on the
+// next Prune or recorded snapshot
+func (c *Catalog) Unpin(id string) {
+	delete(c.pinned, id)
+}
+
+// Pinned reports whether a snapshot is pinned
+func (c *Catalog) Pinned(id string) bool {
+	return c.pinned[id]
+}
+
+// Prune drops the trees of the snapshots the retention policy does not retain
+// and returns their IDs. Their roots are kept, so Root still answers for them,
+// while proofs against them fail with ErrSnapshotPruned.
+func (c *Catalog) Prune() []string {
+	var removed []string
+	c.ids = slices.DeleteFunc(c.ids, func(id string) bool {
+		s := c.snapshots[id]
+		if c.pinned[id] || c.retention.keeps(s.seq, c.recorded) {
+			return false
+		}
+		c.pruned[id] = s.root()
+		delete(c.snapshots, id)
+		removed = append(removed, id)
+		return true
+	})
+	return removed
+}
+
+// Retained returns whether the catalog still holds the tree of a snapshot
+func (c *Catalog) Retained(id string) (bool, error) {
+	if _, ok := c.pruned[id]; ok {
+		return false, nil
+	}
+	if _, ok := c.snapshots[id]; !ok {
+		return false, fmt.Errorf("%w: %s", ErrUnknownSnapshot, id)
+	}
+	return true, nil
+}
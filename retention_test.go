@@ -0,0 +1,79 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// addDays records a snapshot for each day in [from, to), each with a changed file
+func addDays(t *testing.T, c *Catalog, from, to int) {
+	for i := from; i < to; i++ {
+		_, err := c.AddSnapshot(fmt.Sprint("day", i), map[string][]byte{"etc/app.conf": []byte(fmt.Sprint("v", i))})
+		require.NoError(t, err)
+	}
+}
+
+func Test_Retention(t *testing.T) {
+	t.Run("should keep every snapshot by default", func(t *testing.T) {
+		c := NewCatalog()
+		addDays(t, c, 0, 5)
+		require.Len(t, c.Snapshots(), 5)
+		require.Empty(t, c.Prune())
+	})
+
+	t.Run("should keep the last N and every Kth snapshot", func(t *testing.T) {
+		c := NewCatalog()
+		addDays(t, c, 0, 4)
+		require.Equal(t, []string{"day1", "day2"}, c.SetRetention(RetentionPolicy{KeepLast: 1, KeepEvery: 3}))
+		addDays(t, c, 4, 8)
+		require.Equal(t, []string{"day0", "day3", "day6", "day7"}, c.Snapshots())
+	})
+
+	t.Run("should always keep the most recent snapshot", func(t *testing.T) {
+		c := NewCatalog()
+		c.SetRetention(RetentionPolicy{KeepEvery: 10})
+		addDays(t, c, 0, 3)
+		require.Equal(t, []string{"day0", "day2"}, c.Snapshots())
+	})
+
+	t.Run("should retain pinned snapshots until unpinned", func(t *testing.T) {
+		c := NewCatalog()
+		addDays(t, c, 0, 2)
+		require.NoError(t, c.Pin("day0"))
+		require.True(t, c.Pinned("day0"))
+		c.SetRetention(RetentionPolicy{KeepLast: 1})
+		addDays(t, c, 2, 3)
+		require.Equal(t, []string{"day0", "day2"}, c.Snapshots())
+
+		c.Unpin("day0")
+		require.Equal(t, []string{"day0"}, c.Prune())
+		require.ErrorIs(t, c.Pin("day0"), ErrSnapshotPruned)
+		require.ErrorIs(t, c.Pin("day9"), ErrUnknownSnapshot)
+	})
+
+	t.Run("should keep roots but not proofs of pruned snapshots", func(t *testing.T) {
+		c := NewCatalog()
+		addDays(t, c, 0, 2)
+		root, err := c.Root("day0")
+		require.NoError(t, err)
+		c.SetRetention(RetentionPolicy{KeepLast: 1})
+
+		pruned, err := c.Root("day0")
+		require.NoError(t, err)
+		require.Equal(t, root, pruned)
+		retained, err := c.Retained("day0")
+		require.NoError(t, err)
+		require.False(t, retained)
+
+		_, err = c.ProveFile("day0", "etc/app.conf")
+		require.ErrorIs(t, err, ErrSnapshotPruned)
+		_, err = c.Diff("day0", "day1")
+		require.ErrorIs(t, err, ErrSnapshotPruned)
+		_, err = c.AddSnapshot("day0", map[string][]byte{"a": []byte("b")})
+		require.ErrorIs(t, err, ErrDuplicateSnapshot)
+		_, err = c.Retained("day9")
+		require.ErrorIs(t, err, ErrUnknownSnapshot)
+	})
+}
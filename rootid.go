@@ -0,0 +1,107 @@
+package merkle
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidRootID = errors.New("invalid root ID")
+	ErrUnknownHash   = errors.New("hash algorithm of the tree is not known")
+)
+
+// RootID identifies a root by its hash algorithm, as named by HashAlgorithm,
+// and digest, so roots can go through configs, URLs and databases without an
+// assumed algorithm. Its text form is "<algorithm>:<hex digest>", such as
+// "sha256:8e4f...".
+type RootID struct {
+	Algorithm string
+	Digest    []byte
+}
+
+// RootID returns the ID of the tree's root, or ErrUnknownHash if the tree
+// hashes with an algorithm HashAlgorithm cannot name
+func (m *MerkleTree) RootID() (RootID, error) {
+	name := m.HashAlgorithm()
+	if name == "" {
+		return RootID{}, ErrUnknownHash
+	}
+	return RootID{Algorithm: name, Digest: m.Root()}, nil
+}
+
+// ParseRootID parses the text form of a root ID
+func ParseRootID(s string) (RootID, error) {
+	algorithm, digest, ok := strings.Cut(s, ":")
+	if !ok || algorithm == "" || strings.ToLower(digest) != digest {
+		return RootID{}, fmt.Errorf("%w: %q", ErrInvalidRootID, s)
+	}
+	b, err := hex.DecodeString(digest)
+	if err != nil || len(b) == 0 {
+		return RootID{}, fmt.Errorf("%w: %q", ErrInvalidRootID, s)
+	}
+	return RootID{Algorithm: algorithm, Digest: b}, nil
+}
+
+// String returns the text form of the ID
+func (id RootID) String() string {
+	return id.Algorithm + ":" + hex.EncodeToString(id.Digest)
+}
+
+// IsZero reports whether the ID is unset
+func (id RootID) IsZero() bool {
+	return id.Algorithm == "" && len(id.Digest) == 0
+}
+
+// Equal reports whether both IDs name the same algorithm and digest
+func (id RootID) Equal(other RootID) bool {
+	return id.Algorithm == other.Algorithm && bytes.Equal(id.Digest, other.Digest)
+}
+
+// Matches reports whether the ID is the root of the tree
+func (id RootID) Matches(m *MerkleTree) bool {
+	current, err := m.RootID()
+	return err == nil && id.Equal(current)
+}
+
+// MarshalText encodes the ID in its text form
+func (id RootID) MarshalText() ([]byte, error) {
+	if id.Algorithm == "" || strings.Contains(id.Algorithm, ":") || len(id.Digest) == 0 {
+		return nil, ErrInvalidRootID
+	}
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText decodes the text form of an ID
+func (id *RootID) UnmarshalText(b []byte) error {
+	parsed, err := ParseRootID(string(b))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// Value stores the ID in a database as its text form
+func (id RootID) Value() (driver.Value, error) {
+	b, err := id.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan reads an ID stored by Value
+func (id *RootID) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return id.UnmarshalText([]byte(v))
+	case []byte:
+		return id.UnmarshalText(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidRootID, src)
+	}
+}
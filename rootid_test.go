@@ -0,0 +1,69 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RootID(t *testing.T) {
+	tree, err := New([][]byte{[]byte("a"), []byte("b")})
+	require.NoError(t, err)
+
+	t.Run("should name the algorithm and digest of the root", func(t *testing.T) {
+		id, err := tree.RootID()
+		require.NoError(t, err)
+		require.Equal(t, RootID{Algorithm: "sha256", Digest: tree.Root()}, id)
+		require.True(t, id.Matches(tree))
+
+		scheme, err := New([][]byte{[]byte("a"), []byte("b")}, WithScheme(SchemeRFC6962))
+		require.NoError(t, err)
+		id, err = scheme.RootID()
+		require.NoError(t, err)
+		require.Equal(t, "rfc6962", id.Algorithm)
+		require.False(t, id.Matches(tree))
+	})
+
+	t.Run("should fail for unnamed hash functions", func(t *testing.T) {
+		custom, err := New([][]byte{[]byte("a")}, WithHashFunction(mockHash))
+		require.NoError(t, err)
+		_, err = custom.RootID()
+		require.ErrorIs(t, err, ErrUnknownHash)
+	})
+
+	t.Run("should round trip through text, JSON and databases", func(t *testing.T) {
+		id, err := tree.RootID()
+		require.NoError(t, err)
+		parsed, err := ParseRootID(id.String())
+		require.NoError(t, err)
+		require.True(t, id.Equal(parsed))
+
+		b, err := json.Marshal(map[string]RootID{"root": id})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"root":"`+id.String()+`"}`, string(b))
+		var decoded map[string]RootID
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		require.True(t, id.Equal(decoded["root"]))
+
+		v, err := id.Value()
+		require.NoError(t, err)
+		var scanned RootID
+		require.NoError(t, scanned.Scan(v))
+		require.True(t, id.Equal(scanned))
+		require.NoError(t, scanned.Scan([]byte(id.String())))
+		require.ErrorIs(t, scanned.Scan(42), ErrInvalidRootID)
+	})
+
+	t.Run("should reject malformed IDs", func(t *testing.T) {
+		digest := sha256.Sum256(nil)
+		for _, s := range []string{"", "sha256", ":00", "sha256:", "sha256:zz", "sha256:ABCD"} {
+			_, err := ParseRootID(s)
+			require.ErrorIs(t, err, ErrInvalidRootID, s)
+		}
+		_, err := (RootID{Digest: digest[:]}).MarshalText()
+		require.ErrorIs(t, err, ErrInvalidRootID)
+		require.True(t, RootID{}.IsZero())
+	})
+}
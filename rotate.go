@@ -0,0 +1,134 @@
+package merkle
+
+import "time"
+
+// RotatingLog is an append-only log split into segment trees: a segment is
+// closed after maxLeaves leaves or once interval has passed, and its root
+// becomes the first leaf of the next segment, chaining every segment into
+// the current head
+type RotatingLog struct {
+	opts      []Option
+	maxLeaves int
+	interval  time.Duration
+	now       func() time.Time
+	segments  []*MerkleTree
+	opened    time.Time
+}
+
+// SpanProof proves a leaf of any segment against the head of the log: Proof
+// leads to the root of Segment, and each Chain proof leads from a segment root,
+// as the first leaf of the next segment, to that segment's root
+type SpanProof struct {
+	Segment int     `json:"segment"`
+	Proof   Proof   `json:"proof"`
+	Chain   []Proof `json:"chain"`
+}
+
+// NewRotatingLog creates an empty log; a zero maxLeaves or interval disables
+// rotation by size or by time
+func NewRotatingLog(maxLeaves int, interval time.Duration, opts ...Option) *RotatingLog {
+	return &RotatingLog{opts: opts, maxLeaves: maxLeaves, interval: interval, now: time.Now}
+}
+
+// Append adds a leaf, rotating first if the current segment is due, and
+// returns the segment and index within it of the new leaf
+func (l *RotatingLog) Append(data []byte) (segment, index int, err error) {
+	now := l.now()
+	if len(l.segments) == 0 || l.due(now) {
+		leaves := [][]byte{data}
+		if len(l.segments) > 0 {
+			leaves = [][]byte{l.Head(), data}
+		}
+		tree, err := New(leaves, l.opts...)
+		if err != nil {
+			return 0, 0, err
+		}
+		l.segments = append(l.segments, tree)
+		l.opened = now
+	} else if err := l.segments[len(l.segments)-1].AddLeaf(data); err != nil {
+		return 0, 0, err
+	}
+
+	current := l.segments[len(l.segments)-1]
+	return len(l.segments) - 1, len(current.leafs) - 1, nil
+}
+
+// Segments returns the number of segments, including the open one
+func (l *RotatingLog) Segments() int {
+	return len(l.segments)
+}
+
+// Head returns the root of the open segment, which commits to the whole log
+func (l *RotatingLog) Head() []byte {
+	if len(l.segments) == 0 {
+		return nil
+	}
+	return l.segments[len(l.segments)-1].Root()
+}
+
+// SegmentRoot returns the root of a segment
+func (l *RotatingLog) SegmentRoot(segment int) ([]byte, error) {
+	if segment < 0 || segment >= len(l.segments) {
+		return nil, ErrOutOfRange
+	}
+	return l.segments[segment].Root(), nil
+}
+
+// GenerateProof generates a proof for the leaf at index of a segment against the head
+func (l *RotatingLog) GenerateProof(segment, index int) (SpanProof, error) {
+	if segment < 0 || segment >= len(l.segments) {
+		return SpanProof{}, ErrOutOfRange
+	}
+	proof, err := l.segments[segment].GenerateProofAt(index)
+	if err != nil {
+		return SpanProof{}, err
+	}
+
+	p := SpanProof{Segment: segment, Proof: proof}
+	for _, next := range l.segments[segment+1:] {
+		link, err := next.GenerateProofAt(0)
+		if err != nil {
+			return SpanProof{}, err
+		}
+		p.Chain = append(p.Chain, link)
+	}
+	return p, nil
+}
+
+// VerifySpanProof verifies a span proof for data against the head of a log.
+// Every Chain proof must be the path of the first leaf, whose siblings are all
+// on the right, so a segment root appended as a record cannot stand in for
+// the link.
+func VerifySpanProof(head, data []byte, p SpanProof, opts ...Option) bool {
+	m := newVerifier(opts...)
+	h := m.newHash()
+	hash, err := m.hashData(data)
+	if err != nil {
+		return false
+	}
+	root := m.rootFromProof(h, hash, p.Proof)
+	for _, link := range p.Chain {
+		if root == nil || !firstLeafPath(link) {
+			return false
+		}
+		root = m.rootFromProof(h, m.hashLeaf(root), link)
+	}
+	return m.provesRoot(h, root, nil, head)
+}
+
+// firstLeafPath reports whether a proof is the path of the leaf at index 0
+func firstLeafPath(proof Proof) bool {
+	for _, pe := range proof {
+		if pe.Side != Right {
+			return false
+		}
+	}
+	return true
+}
+
+// due reports whether the open segment must be closed before the next append
+func (l *RotatingLog) due(now time.Time) bool {
+	current := l.segments[len(l.segments)-1]
+	return l.maxLeaves > 0 && len(current.leafs) >= l.maxLeaves ||
+		l.interval > 0 && now.Sub(l.opened) >= l.interval
+}
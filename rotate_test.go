@@ -0,0 +1,107 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RotatingLog(t *testing.T) {
+	t.Run("should rotate by size and chain segment roots", func(t *testing.T) {
+		l := NewRotatingLog(3, 0)
+		var positions [][2]int
+		for i := 0; i < 7; i++ {
+			segment, index, err := l.Append([]byte(fmt.Sprint(i)))
+			require.NoError(t, err)
+			positions = append(positions, [2]int{segment, index})
+		}
+		require.Equal(t, [][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 1}, {1, 2}, {2, 1}, {2, 2}}, positions)
+		require.Equal(t, 3, l.Segments())
+
+		first, err := l.SegmentRoot(0)
+		require.NoError(t, err)
+		second, err := New([][]byte{first, []byte("3"), []byte("4")})
+		require.NoError(t, err)
+		root, err := l.SegmentRoot(1)
+		require.NoError(t, err)
+		require.Equal(t, second.Root(), root)
+	})
+
+	t.Run("should rotate by time", func(t *testing.T) {
+		now := time.Unix(0, 0)
+		l := NewRotatingLog(0, time.Minute)
+		l.now = func() time.Time { return now }
+
+		_, _, err := l.Append([]byte("a"))
+		require.NoError(t, err)
+		now = now.Add(30 * time.Second)
+		segment, _, err := l.Append([]byte("b"))
+		require.NoError(t, err)
+		require.Equal(t, 0, segment)
+
+		now = now.Add(30 * time.Second)
+		segment, index, err := l.Append([]byte("c"))
+		require.NoError(t, err)
+		require.Equal(t, 1, segment)
+		require.Equal(t, 1, index)
+	})
+
+	t.Run("should prove leaves of closed segments against the head", func(t *testing.T) {
+		l := NewRotatingLog(2, 0)
+		for i := 0; i < 6; i++ {
+			_, _, err := l.Append([]byte(fmt.Sprint(i)))
+			require.NoError(t, err)
+		}
+
+		p, err := l.GenerateProof(0, 1)
+		require.NoError(t, err)
+		require.Len(t, p.Chain, l.Segments()-1)
+		require.True(t, VerifySpanProof(l.Head(), []byte("1"), p))
+		require.False(t, VerifySpanProof(l.Head(), []byte("2"), p))
+
+		p, err = l.GenerateProof(l.Segments()-1, 1)
+		require.NoError(t, err)
+		require.Empty(t, p.Chain)
+		require.True(t, VerifySpanProof(l.Head(), []byte("5"), p))
+	})
+
+	t.Run("should not verify links from a segment root appended as a record", func(t *testing.T) {
+		l := NewRotatingLog(4, 0)
+		fake, err := New([][]byte{[]byte("never appended"), []byte("x")})
+		require.NoError(t, err)
+		for _, data := range [][]byte{[]byte("a"), fake.Root()} {
+			_, _, err := l.Append(data)
+			require.NoError(t, err)
+		}
+
+		inner, err := fake.GenerateProofAt(0)
+		require.NoError(t, err)
+		link, err := l.GenerateProof(0, 1)
+		require.NoError(t, err)
+		forged := SpanProof{Proof: inner, Chain: []Proof{link.Proof}}
+		require.False(t, VerifySpanProof(l.Head(), []byte("never appended"), forged))
+	})
+
+	t.Run("should return error for leaves rejected by the preimage audit", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+		l := NewRotatingLog(3, 0, WithPreimageAudit(), WithLogger(logger))
+		_, _, err := l.Append([]byte("a"))
+		require.NoError(t, err)
+		_, _, err = l.Append(make([]byte, 2*sha256.Size))
+		require.ErrorIs(t, err, ErrInteriorPreimage)
+
+		segment, index, err := l.Append([]byte("b"))
+		require.NoError(t, err)
+		require.Equal(t, [2]int{0, 1}, [2]int{segment, index})
+	})
+
+	t.Run("should return error for segments out of range", func(t *testing.T) {
+		_, err := NewRotatingLog(2, 0).GenerateProof(0, 0)
+		require.ErrorIs(t, err, ErrOutOfRange)
+	})
+}
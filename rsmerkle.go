@@ -0,0 +1,31 @@
+package merkle
+
+import "crypto/sha256"
+
+// WithRsMerkle reproduces the defaults of the rs-merkle Rust crate: SHA-256
+// hashing with unpaired nodes promoted to the next level
+func WithRsMerkle() Option {
+	return func(m *MerkleTree) {
+		m.hashFn = sha256.New
+		m.promoteOdd = true
+	}
+}
+
+// RsMerkleBytes serializes a proof the way rs-merkle's Proof::to_bytes does,
+// as the sibling hashes concatenated from the leaf up
+func (p Proof) RsMerkleBytes() []byte {
+	var b []byte
+	for _, pe := range p {
+		b = append(b, pe.Hash...)
+	}
+	return b
+}
+
+// ParseRsMerkleProof parses an rs-merkle serialized proof for the leaf at index
+// in a tree of leafCount leaves, deriving the sides from the leaf position
+func ParseRsMerkleProof(b []byte, hashSize, index, leafCount int) (Proof, error) {
+	if hashSize <= 0 {
+		return nil, ErrMalformed
+	}
+	return splitProof(hashSize, true)(b, index, leafCount)
+}
@@ -0,0 +1,59 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithRsMerkle(t *testing.T) {
+	t.Run("should match the rs-merkle reference root", func(t *testing.T) {
+		var data [][]byte
+		for _, s := range []string{"a", "b", "c", "d", "e", "f"} {
+			data = append(data, []byte(s))
+		}
+		tree, err := New(data, WithRsMerkle())
+		require.NoError(t, err)
+		require.Equal(t, "1f7379539707bcaea00564168d1d4d626b09b73f8a2a365234c62d763f854da2", hex.EncodeToString(tree.Root()))
+	})
+}
+
+func Test_ParseRsMerkleProof(t *testing.T) {
+	t.Run("should round-trip proofs for every leaf", func(t *testing.T) {
+		for n := 1; n <= 9; n++ {
+			var data [][]byte
+			for i := 0; i < n; i++ {
+				data = append(data, []byte(fmt.Sprint(i)))
+			}
+			tree, err := New(data, WithRsMerkle())
+			require.NoError(t, err)
+
+			for i, item := range data {
+				proof, err := tree.GenerateProof(item)
+				require.NoError(t, err)
+
+				parsed, err := ParseRsMerkleProof(proof.RsMerkleBytes(), sha256.Size, i, n)
+				require.NoError(t, err)
+				require.Len(t, parsed, len(proof))
+				for j := range proof {
+					require.Equal(t, proof[j].Hash, parsed[j].Hash)
+					require.Equal(t, proof[j].Side, parsed[j].Side)
+				}
+				require.True(t, tree.VerifyData(item, parsed))
+			}
+		}
+	})
+
+	t.Run("should return error for wrong proof length", func(t *testing.T) {
+		_, err := ParseRsMerkleProof(make([]byte, sha256.Size), sha256.Size, 0, 4)
+		require.ErrorIs(t, err, ErrMalformed)
+	})
+
+	t.Run("should return error for out of range index", func(t *testing.T) {
+		_, err := ParseRsMerkleProof(nil, sha256.Size, 4, 4)
+		require.ErrorIs(t, err, ErrOutOfRange)
+	})
+}
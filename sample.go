@@ -0,0 +1,59 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// Sample is a sampled leaf with its proof
+type Sample struct {
+	Leaf  Leaf  `json:"leaf"`
+	Proof Proof `json:"proof"`
+}
+
+// SampleIndexes deterministically selects k distinct leaf indexes out of size
+// from the seed and the root, in selection order. Deriving them from the root
+// lets auditors check that a prover did not choose the sampled leaves.
+func SampleIndexes(seed, root []byte, size, k int) ([]int, error) {
+	if k < 0 || k > size {
+		return nil, ErrOutOfRange
+	}
+
+	seen := make(map[int]bool, k)
+	indexes := make([]int, 0, k)
+	var counter [8]byte
+	for i := uint64(0); len(indexes) < k; i++ {
+		h := sha256.New()
+		h.Write(seed)
+		h.Write(root)
+		binary.BigEndian.PutUint64(counter[:], i)
+		h.Write(counter[:])
+
+		index := int(binary.BigEndian.Uint64(h.Sum(nil)) % uint64(size))
+		if !seen[index] {
+			seen[index] = true
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes, nil
+}
+
+// SampleLeaves selects k leaves as SampleIndexes does and returns them with
+// their proofs, for data-availability-sampling-style audits
+func (m *MerkleTree) SampleLeaves(seed []byte, k int) ([]Sample, error) {
+	indexes, err := SampleIndexes(seed, m.root.hash, len(m.leafs), k)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]Sample, len(indexes))
+	for i, index := range indexes {
+		leaf := m.leafs[index]
+		proof, err := m.auditedProof(leaf)
+		if err != nil {
+			return nil, err
+		}
+		samples[i] = Sample{Leaf: Leaf{Index: index, Data: leaf.data, Hash: leaf.hash, Value: leaf.value}, Proof: proof}
+	}
+	return samples, nil
+}
@@ -0,0 +1,54 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SampleIndexes(t *testing.T) {
+	root := []byte("root")
+
+	t.Run("should be deterministic for a seed and root", func(t *testing.T) {
+		a, err := SampleIndexes([]byte("seed"), root, 100, 10)
+		require.NoError(t, err)
+		b, err := SampleIndexes([]byte("seed"), root, 100, 10)
+		require.NoError(t, err)
+		require.Equal(t, a, b)
+
+		c, err := SampleIndexes([]byte("other"), root, 100, 10)
+		require.NoError(t, err)
+		require.NotEqual(t, a, c)
+	})
+
+	t.Run("should select distinct indexes", func(t *testing.T) {
+		indexes, err := SampleIndexes([]byte("seed"), root, 5, 5)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int{0, 1, 2, 3, 4}, indexes)
+	})
+
+	t.Run("should return error for more samples than leaves", func(t *testing.T) {
+		_, err := SampleIndexes([]byte("seed"), root, 5, 6)
+		require.ErrorIs(t, err, ErrOutOfRange)
+	})
+}
+
+func Test_SampleLeaves(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	tree, err := New(data)
+	require.NoError(t, err)
+
+	t.Run("should return sampled leaves with proofs", func(t *testing.T) {
+		samples, err := tree.SampleLeaves([]byte("seed"), 3)
+		require.NoError(t, err)
+		require.Len(t, samples, 3)
+
+		indexes, err := SampleIndexes([]byte("seed"), tree.Root(), len(data), 3)
+		require.NoError(t, err)
+		for i, s := range samples {
+			require.Equal(t, indexes[i], s.Leaf.Index)
+			require.Equal(t, data[s.Leaf.Index], s.Leaf.Data)
+			require.True(t, tree.VerifyProof(s.Leaf.Hash, s.Proof))
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package merkle
+
+import (
+	"context"
+	"time"
+)
+
+// rootReady is the channel RootReady returns when no leaves are pending
+var rootReady = make(chan struct{})
+
+func init() {
+	close(rootReady)
+}
+
+// WithRebuildDebounce defers the rebuild after AddLeaf and AddLeaves until no
+// leaf has been added for d, so a burst of appends costs a single rebuild.
+// Until then the tree, its root and its proofs stay at the last rebuild, and
+// the new leaves are invisible. Root, the mutating methods and Flush are safe
+// to call while a rebuild is scheduled; other methods should follow a Flush.
+func WithRebuildDebounce(d time.Duration) Option {
+	return func(m *MerkleTree) {
+		m.debounce = d
+	}
+}
+
+// WithAsyncRebuild moves the rebuild after AddLeaf and AddLeaves to a
+// background goroutine, so appends return without hashing the tree; a burst of
+// appends made while a rebuild is queued shares it. The same concurrency rules
+// as for WithRebuildDebounce apply, with WaitRoot in place of Flush.
+func WithAsyncRebuild() Option {
+	return func(m *MerkleTree) {
+		m.async = true
+	}
+}
+
+// Flush rebuilds the tree now if leaves were added since the last rebuild
+func (m *MerkleTree) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flushPending()
+}
+
+// RootReady returns a channel that is closed once the root reflects every
+// leaf added before the call
+func (m *MerkleTree) RootReady() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ready == nil {
+		return rootReady
+	}
+	return m.ready
+}
+
+// WaitRoot blocks until the root reflects every leaf added before the call
+// and returns it, or returns the context's error once it is done
+func (m *MerkleTree) WaitRoot(ctx context.Context) ([]byte, error) {
+	select {
+	case <-m.RootReady():
+		return m.Root(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// scheduleRebuild rebuilds with the pending leaves, or schedules a rebuild on
+// the debounce timer or in the background; m.mu must be held
+func (m *MerkleTree) scheduleRebuild() {
+	if m.debounce <= 0 && !m.async {
+		m.flushPending()
+		return
+	}
+
+	if m.ready == nil {
+		m.ready = make(chan struct{})
+		if m.debounce <= 0 {
+			go m.Flush()
+		}
+	}
+	if m.debounce > 0 {
+		if m.timer == nil {
+			m.timer = time.AfterFunc(m.debounce, m.Flush)
+		} else {
+			m.timer.Reset(m.debounce)
+		}
+	}
+}
+
+// flushPending appends the pending leaves and rebuilds; m.mu must be held
+func (m *MerkleTree) flushPending() {
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+	if len(m.pending) == 0 {
+		return
+	}
+
+	m.leafs = append(m.leafs, m.pending...)
+	m.pending = nil
+	m.rebuild()
+
+	if m.ready != nil {
+		close(m.ready)
+		m.ready = nil
+	}
+}
@@ -0,0 +1,96 @@
+package merkle
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithRebuildDebounce(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b")}
+
+	t.Run("should keep the last root during a burst", func(t *testing.T) {
+		tree, err := New(data, WithHashFunction(mockHash), WithRebuildDebounce(time.Hour))
+		require.NoError(t, err)
+
+		tree.AddLeaf([]byte("c"))
+		tree.AddLeaves([][]byte{[]byte("d")})
+		require.Equal(t, "hash(hash(a)hash(b))", string(tree.Root()))
+
+		_, err = tree.GenerateProof([]byte("c"))
+		require.ErrorIs(t, err, ErrNotFoundData)
+	})
+
+	t.Run("should rebuild on flush", func(t *testing.T) {
+		tree, err := New(data, WithHashFunction(mockHash), WithRebuildDebounce(time.Hour))
+		require.NoError(t, err)
+
+		tree.AddLeaf([]byte("c"))
+		tree.Flush()
+		require.Equal(t, "hash(hash(hash(a)hash(b))hash(hash(c)hash(c)))", string(tree.Root()))
+	})
+
+	t.Run("should rebuild once the burst quiets down", func(t *testing.T) {
+		tree, err := New(data, WithHashFunction(mockHash), WithRebuildDebounce(time.Millisecond))
+		require.NoError(t, err)
+
+		tree.AddLeaf([]byte("c"))
+		want := []byte("hash(hash(hash(a)hash(b))hash(hash(c)hash(c)))")
+		require.Eventually(t, func() bool {
+			return bytes.Equal(want, tree.Root())
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("should include pending leaves when updating", func(t *testing.T) {
+		tree, err := New(data, WithHashFunction(mockHash), WithRebuildDebounce(time.Hour))
+		require.NoError(t, err)
+
+		tree.AddLeaf([]byte("c"))
+		require.NoError(t, tree.UpdateLeaf([]byte("c"), []byte("d")))
+		require.Equal(t, "hash(hash(hash(a)hash(b))hash(hash(d)hash(d)))", string(tree.Root()))
+	})
+}
+
+func Test_WithAsyncRebuild(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b")}
+
+	t.Run("should signal once the root is fresh", func(t *testing.T) {
+		tree, err := New(data, WithHashFunction(mockHash), WithAsyncRebuild())
+		require.NoError(t, err)
+
+		select {
+		case <-tree.RootReady():
+		default:
+			t.Fatal("root of a new tree should be ready")
+		}
+
+		tree.AddLeaf([]byte("c"))
+		tree.AddLeaves([][]byte{[]byte("d")})
+		<-tree.RootReady()
+		require.Equal(t, "hash(hash(hash(a)hash(b))hash(hash(c)hash(d)))", string(tree.Root()))
+	})
+
+	t.Run("should wait for the fresh root", func(t *testing.T) {
+		tree, err := New(data, WithHashFunction(mockHash), WithAsyncRebuild())
+		require.NoError(t, err)
+
+		tree.AddLeaf([]byte("c"))
+		root, err := tree.WaitRoot(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "hash(hash(hash(a)hash(b))hash(hash(c)hash(c)))", string(root))
+	})
+
+	t.Run("should return error once the context is done", func(t *testing.T) {
+		tree, err := New(data, WithRebuildDebounce(time.Hour))
+		require.NoError(t, err)
+
+		tree.AddLeaf([]byte("c"))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = tree.WaitRoot(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,164 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"hash"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Scheme bundles everything that defines a Merkle tree construction: leaf and
+// node hashing, the odd node policy and the proof encoding. A tree built with
+// a scheme hashes only through it, so the hash function, prefix, key,
+// personalization, truncation and byte order options have no effect.
+type Scheme struct {
+	Name       string
+	HashSize   int
+	PromoteOdd bool
+	HashLeaf   func(data []byte) []byte
+	HashNode   func(left, right []byte) []byte
+
+	// DomainSeparated reports that leaf and node hashes cannot collide, so
+	// the preimage audit does not apply
+	DomainSeparated bool
+
+	// EncodeProof serializes a proof; DecodeProof parses one for the leaf at
+	// index in a tree of leafCount leaves
+	EncodeProof func(p Proof) []byte
+	DecodeProof func(b []byte, index, leafCount int) (Proof, error)
+}
+
+var (
+	// SchemeRFC6962 is the Certificate Transparency log tree: SHA-256 with
+	// 0x00/0x01 leaf and node prefixes, unpaired nodes promoted, and proofs
+	// encoded as their concatenated audit path
+	SchemeRFC6962 = Scheme{
+		Name:            "rfc6962",
+		HashSize:        sha256.Size,
+		PromoteOdd:      true,
+		DomainSeparated: true,
+		HashLeaf:        rfc6962Leaf,
+		HashNode:        rfc6962Node,
+		EncodeProof:     concatProof,
+		DecodeProof:     splitProof(sha256.Size, true),
+	}
+
+	// SchemeBitcoin is the block transaction tree: the leaves are txids in
+	// internal byte order, nodes are double SHA-256 hashes and unpaired nodes
+	// are paired with themselves
+	SchemeBitcoin = Scheme{
+		Name:        "bitcoin",
+		HashSize:    sha256.Size,
+		HashLeaf:    func(data []byte) []byte { return bytes.Clone(data) },
+		HashNode:    bitcoinNode,
+		EncodeProof: concatProof,
+		DecodeProof: splitProof(sha256.Size, false),
+	}
+
+	// SchemeOZSorted is the tree merkletreejs builds with sortPairs for
+	// OpenZeppelin's MerkleProof: Keccak-256 leaves, nodes hashing the sorted
+	// pair of children, unpaired nodes promoted, and proofs encoded as the
+	// bytes32[] of sibling hashes
+	SchemeOZSorted = Scheme{
+		Name:        "oz-sorted",
+		HashSize:    32,
+		PromoteOdd:  true,
+		HashLeaf:    keccakLeaf,
+		HashNode:    sortedKeccakNode,
+		EncodeProof: concatProof,
+		DecodeProof: splitProof(32, true),
+	}
+
+	// SchemeCometBFT is the simple Merkle tree of CometBFT block data, which
+	// hashes as RFC 6962 does; proofs are encoded as the concatenated aunts
+	SchemeCometBFT = Scheme{
+		Name:            "cometbft",
+		HashSize:        sha256.Size,
+		PromoteOdd:      true,
+		DomainSeparated: true,
+		HashLeaf:        rfc6962Leaf,
+		HashNode:        rfc6962Node,
+		EncodeProof:     concatProof,
+		DecodeProof:     splitProof(sha256.Size, true),
+	}
+)
+
+// WithScheme builds the tree with the given scheme's hashing and odd node policy
+func WithScheme(s Scheme) Option {
+	return func(m *MerkleTree) {
+		m.scheme = &s
+		m.promoteOdd = s.PromoteOdd
+	}
+}
+
+// schemeName returns the name of the tree's scheme, or "" if it has none
+func (m *MerkleTree) schemeName() string {
+	if m.scheme == nil {
+		return ""
+	}
+	return m.scheme.Name
+}
+
+// rfc6962Leaf computes SHA-256(0x00 || data)
+func rfc6962Leaf(data []byte) []byte {
+	return sum(sha256.New, []byte{0x00}, data)
+}
+
+// rfc6962Node computes SHA-256(0x01 || left || right)
+func rfc6962Node(left, right []byte) []byte {
+	return sum(sha256.New, []byte{0x01}, left, right)
+}
+
+// bitcoinNode computes SHA-256(SHA-256(left || right))
+func bitcoinNode(left, right []byte) []byte {
+	return sum(newDoubleSHA256, left, right)
+}
+
+// keccakLeaf computes Keccak-256(data)
+func keccakLeaf(data []byte) []byte {
+	return sum(sha3.NewLegacyKeccak256, data)
+}
+
+// sortedKeccakNode hashes the children in ascending order, so proofs do not
+// depend on the sides
+func sortedKeccakNode(left, right []byte) []byte {
+	if bytes.Compare(left, right) > 0 {
+		left, right = right, left
+	}
+	return sum(sha3.NewLegacyKeccak256, left, right)
+}
+
+// sum hashes the concatenation of the parts with h
+func sum(h func() hash.Hash, parts ...[]byte) []byte {
+	d := h()
+	for _, part := range parts {
+		d.Write(part)
+	}
+	return d.Sum(nil)
+}
+
+// concatProof encodes a proof as its sibling hashes concatenated from the leaf up
+func concatProof(p Proof) []byte {
+	return p.RsMerkleBytes()
+}
+
+// splitProof returns a decoder for concatenated proofs that derives the sides
+// from the leaf position
+func splitProof(hashSize int, promoteOdd bool) func(b []byte, index, leafCount int) (Proof, error) {
+	return func(b []byte, index, leafCount int) (Proof, error) {
+		sides, err := proofSides(index, leafCount, promoteOdd)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != len(sides)*hashSize {
+			return nil, ErrMalformed
+		}
+
+		proof := make(Proof, len(sides))
+		for i, side := range sides {
+			proof[i] = ProofElement{Hash: b[i*hashSize : (i+1)*hashSize : (i+1)*hashSize], Side: side}
+		}
+		return proof, nil
+	}
+}
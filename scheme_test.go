@@ -0,0 +1,94 @@
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithScheme(t *testing.T) {
+	// 32 byte leaves, since Bitcoin leaves are txids
+	var data [][]byte
+	for i := 0; i < 7; i++ {
+		data = append(data, bytes.Repeat([]byte{byte(i)}, 32))
+	}
+
+	t.Run("should match the RFC 6962 options", func(t *testing.T) {
+		tree, err := New(data, WithScheme(SchemeRFC6962))
+		require.NoError(t, err)
+		expected, err := New(data, WithRFC6962())
+		require.NoError(t, err)
+		require.Equal(t, expected.Root(), tree.Root())
+
+		comet, err := New(data, WithScheme(SchemeCometBFT))
+		require.NoError(t, err)
+		require.Equal(t, expected.Root(), comet.Root())
+	})
+
+	t.Run("should compute Bitcoin block roots", func(t *testing.T) {
+		var txids [][]byte
+		for _, s := range block100000 {
+			txids = append(txids, reversedHex(t, s))
+		}
+		tree, err := New(txids, WithScheme(SchemeBitcoin))
+		require.NoError(t, err)
+		require.Equal(t, reversedHex(t, block100000Root), tree.Root())
+
+		odd, err := New(txids[:3], WithScheme(SchemeBitcoin))
+		require.NoError(t, err)
+		p, err := NewPartialMerkleTree(txids[:3], []bool{true, false, false})
+		require.NoError(t, err)
+		root, _, _, err := p.ExtractMatches()
+		require.NoError(t, err)
+		require.Equal(t, root, odd.Root())
+	})
+
+	t.Run("should hash sorted pairs", func(t *testing.T) {
+		tree, err := New(data, WithScheme(SchemeOZSorted))
+		require.NoError(t, err)
+
+		for i, leaf := range data {
+			proof, err := tree.GenerateProofAt(i)
+			require.NoError(t, err)
+
+			// sorted pairs make the sides irrelevant
+			for j := range proof {
+				proof[j].Side = Left
+			}
+			require.True(t, tree.VerifyData(leaf, proof))
+		}
+	})
+
+	for _, s := range []Scheme{SchemeRFC6962, SchemeBitcoin, SchemeOZSorted, SchemeCometBFT} {
+		t.Run(fmt.Sprintf("should encode and decode %s proofs", s.Name), func(t *testing.T) {
+			tree, err := New(data, WithScheme(s))
+			require.NoError(t, err)
+
+			for i, leaf := range data {
+				proof, err := tree.GenerateProofAt(i)
+				require.NoError(t, err)
+
+				b := s.EncodeProof(proof)
+				require.Len(t, b, len(proof)*s.HashSize)
+				decoded, err := s.DecodeProof(b, i, len(data))
+				require.NoError(t, err)
+				require.Equal(t, proof, decoded)
+				require.True(t, tree.VerifyData(leaf, decoded))
+			}
+
+			_, err = s.DecodeProof([]byte{1}, 0, len(data))
+			require.ErrorIs(t, err, ErrMalformed)
+		})
+	}
+
+	t.Run("should tell trees of different schemes apart", func(t *testing.T) {
+		rfc, err := New(data, WithScheme(SchemeRFC6962))
+		require.NoError(t, err)
+		comet, err := New(data, WithScheme(SchemeCometBFT))
+		require.NoError(t, err)
+		require.False(t, rfc.Equal(comet))
+		require.True(t, rfc.RootEqual(comet))
+	})
+}
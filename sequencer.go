@@ -0,0 +1,157 @@
+package merkle
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrSortedLeaves     = errors.New("leaf indices of trees with sorted leaves are not stable")
+	ErrSequencerStopped = errors.New("sequencer is not running")
+)
+
+// SequencerConfig configures the batching of a Sequencer
+type SequencerConfig struct {
+	// BatchSize is the maximum number of appends per commit; defaults to 256
+	BatchSize int
+	// FlushInterval bounds how long an append waits for a batch to fill;
+	// defaults to a millisecond
+	FlushInterval time.Duration
+	// Queue is the number of appends that can wait for a batch; defaults to BatchSize
+	Queue int
+}
+
+// Sequenced is the outcome of an append: the leaf's final index and its proof
+// against the root of the commit that included it
+type Sequenced struct {
+	Index    int
+	TreeSize int
+	Root     []byte
+	Proof    Proof
+}
+
+// Sequencer accepts appends from many goroutines, assigns them increasing
+// indices in arrival order and commits them to the tree in batches, returning
+// each caller its final index and proof. It must be the only writer of the tree.
+type Sequencer struct {
+	m        *MerkleTree
+	cfg      SequencerConfig
+	requests chan sequenceRequest
+	stopped  chan struct{}
+}
+
+// sequenceRequest is an append waiting for its batch to be committed
+type sequenceRequest struct {
+	data []byte
+	done chan sequenceResult
+}
+
+type sequenceResult struct {
+	seq Sequenced
+	err error
+}
+
+// NewSequencer creates a sequencer appending to the tree; Run must be running
+// for appends to complete
+func NewSequencer(m *MerkleTree, cfg SequencerConfig) (*Sequencer, error) {
+	if m.sortLeaves {
+		return nil, ErrSortedLeaves
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 256
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Millisecond
+	}
+	if cfg.Queue <= 0 {
+		cfg.Queue = cfg.BatchSize
+	}
+	return &Sequencer{m: m, cfg: cfg, requests: make(chan sequenceRequest, cfg.Queue), stopped: make(chan struct{})}, nil
+}
+
+// Append submits a leaf and waits for the commit that includes it. If ctx is
+// done after the leaf was queued, it may still be appended.
+func (s *Sequencer) Append(ctx context.Context, data []byte) (Sequenced, error) {
+	req := sequenceRequest{data: data, done: make(chan sequenceResult, 1)}
+	select {
+	case s.requests <- req:
+	case <-s.stopped:
+		return Sequenced{}, ErrSequencerStopped
+	case <-ctx.Done():
+		return Sequenced{}, ctx.Err()
+	}
+
+	select {
+	case r := <-req.done:
+		return r.seq, r.err
+	case <-s.stopped:
+		// Run may have committed the batch before stopping
+		select {
+		case r := <-req.done:
+			return r.seq, r.err
+		default:
+			return Sequenced{}, ErrSequencerStopped
+		}
+	case <-ctx.Done():
+		return Sequenced{}, ctx.Err()
+	}
+}
+
+// Run commits batches of appends until ctx is done; appends still queued then
+// fail with ErrSequencerStopped
+func (s *Sequencer) Run(ctx context.Context) error {
+	defer close(s.stopped)
+
+	for {
+		var batch []sequenceRequest
+		select {
+		case req := <-s.requests:
+			batch = append(batch, req)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		timer := time.NewTimer(s.cfg.FlushInterval)
+	fill:
+		for len(batch) < s.cfg.BatchSize {
+			select {
+			case req := <-s.requests:
+				batch = append(batch, req)
+			case <-timer.C:
+				break fill
+			case <-ctx.Done():
+				break fill
+			}
+		}
+		timer.Stop()
+
+		s.commit(batch)
+	}
+}
+
+// commit appends a batch with a single rebuild and answers every request;
+// leaves are built under the lock, so they are hashed with the current key
+func (s *Sequencer) commit(batch []sequenceRequest) {
+	s.m.mu.Lock()
+	s.m.flushPending()
+	base := len(s.m.leafs)
+	appended := batch[:0]
+	for _, req := range batch {
+		leaf, err := s.m.newLeaf(req.data)
+		if err != nil {
+			req.done <- sequenceResult{err: err}
+			continue
+		}
+		s.m.pending = append(s.m.pending, leaf)
+		appended = append(appended, req)
+	}
+	s.m.flushPending()
+	root, size := s.m.root.hash, len(s.m.leafs)
+	s.m.mu.Unlock()
+
+	for i, req := range appended {
+		proof, err := s.m.GenerateProofAt(base + i)
+		req.done <- sequenceResult{seq: Sequenced{Index: base + i, TreeSize: size, Root: root, Proof: proof}, err: err}
+	}
+}
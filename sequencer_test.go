@@ -0,0 +1,92 @@
+package merkle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Sequencer(t *testing.T) {
+	t.Run("should assign every concurrent append a distinct index and a valid proof", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("genesis")})
+		require.NoError(t, err)
+		s, err := NewSequencer(tree, SequencerConfig{BatchSize: 8})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go s.Run(ctx)
+
+		const n = 100
+		results := make([]Sequenced, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				seq, err := s.Append(ctx, []byte(fmt.Sprint("leaf", i)))
+				require.NoError(t, err)
+				results[i] = seq
+			}(i)
+		}
+		wg.Wait()
+
+		indices := make([]int, n)
+		for i, seq := range results {
+			indices[i] = seq.Index
+			data := []byte(fmt.Sprint("leaf", i))
+			require.Equal(t, data, tree.Leaves()[seq.Index].Data)
+
+			verifier := newVerifier()
+			require.True(t, bytes.Equal(verifier.rootFromProof(verifier.newHash(), verifier.hashLeaf(data), seq.Proof), seq.Root))
+			require.GreaterOrEqual(t, seq.TreeSize, seq.Index+1)
+		}
+		sort.Ints(indices)
+		for i, index := range indices {
+			require.Equal(t, i+1, index)
+		}
+	})
+
+	t.Run("should assign indices in arrival order", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("genesis")})
+		require.NoError(t, err)
+		s, err := NewSequencer(tree, SequencerConfig{})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go s.Run(ctx)
+
+		for i := 1; i <= 3; i++ {
+			seq, err := s.Append(ctx, []byte(fmt.Sprint(i)))
+			require.NoError(t, err)
+			require.Equal(t, i, seq.Index)
+			require.Equal(t, tree.Root(), seq.Root)
+		}
+	})
+
+	t.Run("should fail appends once stopped", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("genesis")})
+		require.NoError(t, err)
+		s, err := NewSequencer(tree, SequencerConfig{})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, s.Run(ctx), context.Canceled)
+		_, err = s.Append(context.Background(), []byte("late"))
+		require.ErrorIs(t, err, ErrSequencerStopped)
+	})
+
+	t.Run("should reject trees with sorted leaves", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("genesis")}, WithSortedLeaves())
+		require.NoError(t, err)
+		_, err = NewSequencer(tree, SequencerConfig{})
+		require.ErrorIs(t, err, ErrSortedLeaves)
+	})
+}
@@ -0,0 +1,160 @@
+package merkle
+
+import (
+	"bytes"
+	"sort"
+)
+
+// MerkleSet is a Merkle tree over a sorted, deduplicated set of items, so equal
+// sets always commit to the same root. Set operations may give an empty set,
+// which has no root and proves no membership.
+type MerkleSet struct {
+	tree  *MerkleTree
+	items [][]byte
+	opts  []Option
+}
+
+// UnionProof proves that an item belongs to the union of two committed sets,
+// by proving its membership in one of them
+type UnionProof struct {
+	InFirst bool
+	Proof   Proof
+}
+
+// NewSet creates a Merkle set from a list of items, ignoring duplicates
+func NewSet(data [][]byte, opts ...Option) (*MerkleSet, error) {
+	items := append([][]byte{}, data...)
+	sort.Slice(items, func(i, j int) bool {
+		return bytes.Compare(items[i], items[j]) < 0
+	})
+
+	unique := items[:0]
+	for i, item := range items {
+		if i == 0 || !bytes.Equal(item, items[i-1]) {
+			unique = append(unique, item)
+		}
+	}
+
+	return newSortedSet(unique, opts)
+}
+
+// Root returns the root hash of the set, or nil if it is empty
+func (s *MerkleSet) Root() []byte {
+	if s.tree == nil {
+		return nil
+	}
+	return s.tree.Root()
+}
+
+// Items returns the items of the set in sorted order
+func (s *MerkleSet) Items() [][]byte {
+	return s.items
+}
+
+// Contains reports whether the item is in the set
+func (s *MerkleSet) Contains(data []byte) bool {
+	_, found := s.search(data)
+	return found
+}
+
+// GenerateProof generates a membership proof for an item of the set
+func (s *MerkleSet) GenerateProof(data []byte) (Proof, error) {
+	i, found := s.search(data)
+	if !found {
+		return nil, ErrNotFoundData
+	}
+	return s.tree.GenerateProofAt(i)
+}
+
+// VerifyData verifies a membership proof for an item
+func (s *MerkleSet) VerifyData(data []byte, proof Proof) bool {
+	return s.tree != nil && s.tree.VerifyData(data, proof)
+}
+
+// Union returns a new set with the items of both sets
+func (s *MerkleSet) Union(other *MerkleSet) (*MerkleSet, error) {
+	return s.merge(other, true, true, true)
+}
+
+// Intersection returns a new set with the items present in both sets
+func (s *MerkleSet) Intersection(other *MerkleSet) (*MerkleSet, error) {
+	return s.merge(other, false, true, false)
+}
+
+// Difference returns a new set with the items of s that are not in other
+func (s *MerkleSet) Difference(other *MerkleSet) (*MerkleSet, error) {
+	return s.merge(other, true, false, false)
+}
+
+// ProveUnion proves that an item is in the union of sets a and b
+func ProveUnion(a, b *MerkleSet, data []byte) (UnionProof, error) {
+	if proof, err := a.GenerateProof(data); err == nil {
+		return UnionProof{InFirst: true, Proof: proof}, nil
+	}
+	proof, err := b.GenerateProof(data)
+	if err != nil {
+		return UnionProof{}, err
+	}
+	return UnionProof{Proof: proof}, nil
+}
+
+// VerifyUnion verifies that an item is in the union of the sets committed to by
+// rootA and rootB, hashed with the given options
+func VerifyUnion(rootA, rootB, data []byte, p UnionProof, opts ...Option) bool {
+	m := newVerifier(opts...)
+	root := rootB
+	if p.InFirst {
+		root = rootA
+	}
+	// the empty set has no root, so nothing proves membership in it
+	hash, err := m.hashData(data)
+	return err == nil && m.provesRoot(m.newHash(), hash, p.Proof, root)
+}
+
+// search finds the position of an item with a binary search
+func (s *MerkleSet) search(data []byte) (int, bool) {
+	i := sort.Search(len(s.items), func(i int) bool {
+		return bytes.Compare(s.items[i], data) >= 0
+	})
+	return i, i < len(s.items) && bytes.Equal(s.items[i], data)
+}
+
+// merge walks both sorted sets at once, keeping the items only in s, in both
+// or only in other as requested
+func (s *MerkleSet) merge(other *MerkleSet, onlyS, both, onlyOther bool) (*MerkleSet, error) {
+	var items [][]byte
+	a, b := s.items, other.items
+	for len(a) > 0 || len(b) > 0 {
+		switch {
+		case len(b) == 0 || len(a) > 0 && bytes.Compare(a[0], b[0]) < 0:
+			if onlyS {
+				items = append(items, a[0])
+			}
+			a = a[1:]
+		case len(a) == 0 || bytes.Compare(a[0], b[0]) > 0:
+			if onlyOther {
+				items = append(items, b[0])
+			}
+			b = b[1:]
+		default:
+			if both {
+				items = append(items, a[0])
+			}
+			a, b = a[1:], b[1:]
+		}
+	}
+
+	if len(items) == 0 {
+		return &MerkleSet{opts: s.opts}, nil
+	}
+	return newSortedSet(items, s.opts)
+}
+
+// newSortedSet builds a set from items that are already sorted and unique
+func newSortedSet(items [][]byte, opts []Option) (*MerkleSet, error) {
+	tree, err := New(items, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &MerkleSet{tree: tree, items: items, opts: opts}, nil
+}
@@ -0,0 +1,116 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewSet(t *testing.T) {
+	t.Run("should sort and deduplicate items", func(t *testing.T) {
+		set, err := NewSet([][]byte{[]byte("c"), []byte("a"), []byte("c"), []byte("b")}, WithHashFunction(mockHash))
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, set.Items())
+		require.Equal(t, "hash(hash(hash(a)hash(b))hash(hash(c)hash(c)))", string(set.Root()))
+	})
+
+	t.Run("should commit equal sets to the same root", func(t *testing.T) {
+		a, err := NewSet([][]byte{[]byte("x"), []byte("y")})
+		require.NoError(t, err)
+		b, err := NewSet([][]byte{[]byte("y"), []byte("x"), []byte("y")})
+		require.NoError(t, err)
+		require.Equal(t, a.Root(), b.Root())
+	})
+
+	t.Run("should prove membership", func(t *testing.T) {
+		set, err := NewSet([][]byte{[]byte("c"), []byte("a"), []byte("b")})
+		require.NoError(t, err)
+		require.True(t, set.Contains([]byte("b")))
+		require.False(t, set.Contains([]byte("d")))
+
+		proof, err := set.GenerateProof([]byte("b"))
+		require.NoError(t, err)
+		require.True(t, set.VerifyData([]byte("b"), proof))
+
+		_, err = set.GenerateProof([]byte("d"))
+		require.ErrorIs(t, err, ErrNotFoundData)
+	})
+}
+
+func Test_SetOperations(t *testing.T) {
+	a, err := NewSet([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	require.NoError(t, err)
+	b, err := NewSet([][]byte{[]byte("b"), []byte("c"), []byte("d")})
+	require.NoError(t, err)
+
+	t.Run("should compute the union", func(t *testing.T) {
+		union, err := a.Union(b)
+		require.NoError(t, err)
+		expected, err := NewSet([][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")})
+		require.NoError(t, err)
+		require.Equal(t, expected.Root(), union.Root())
+	})
+
+	t.Run("should compute the intersection", func(t *testing.T) {
+		intersection, err := a.Intersection(b)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("b"), []byte("c")}, intersection.Items())
+	})
+
+	t.Run("should compute the difference", func(t *testing.T) {
+		difference, err := a.Difference(b)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("a")}, difference.Items())
+	})
+
+	t.Run("should return an empty set for an empty result", func(t *testing.T) {
+		empty, err := a.Difference(a)
+		require.NoError(t, err)
+		require.Empty(t, empty.Items())
+		require.Nil(t, empty.Root())
+		require.False(t, empty.Contains([]byte("a")))
+		_, err = empty.GenerateProof([]byte("a"))
+		require.ErrorIs(t, err, ErrNotFoundData)
+
+		union, err := empty.Union(b)
+		require.NoError(t, err)
+		require.Equal(t, b.Root(), union.Root())
+		intersection, err := empty.Intersection(b)
+		require.NoError(t, err)
+		require.Empty(t, intersection.Items())
+	})
+}
+
+func Test_ProveUnion(t *testing.T) {
+	a, err := NewSet([][]byte{[]byte("a"), []byte("b")})
+	require.NoError(t, err)
+	b, err := NewSet([][]byte{[]byte("c"), []byte("d")})
+	require.NoError(t, err)
+
+	t.Run("should prove membership in either set", func(t *testing.T) {
+		for _, item := range [][]byte{[]byte("a"), []byte("d")} {
+			p, err := ProveUnion(a, b, item)
+			require.NoError(t, err)
+			require.True(t, VerifyUnion(a.Root(), b.Root(), item, p))
+		}
+	})
+
+	t.Run("should not verify against swapped roots", func(t *testing.T) {
+		p, err := ProveUnion(a, b, []byte("a"))
+		require.NoError(t, err)
+		require.False(t, VerifyUnion(b.Root(), a.Root(), []byte("a"), p))
+	})
+
+	t.Run("should return error for items in neither set", func(t *testing.T) {
+		_, err := ProveUnion(a, b, []byte("e"))
+		require.ErrorIs(t, err, ErrNotFoundData)
+	})
+
+	t.Run("should not verify proofs against an empty set", func(t *testing.T) {
+		empty, err := a.Difference(a)
+		require.NoError(t, err)
+		forged := UnionProof{InFirst: true, Proof: Proof{{Side: 9}}}
+		require.False(t, VerifyUnion(empty.Root(), b.Root(), []byte("forged"), forged))
+		require.False(t, VerifyUnion(a.Root(), b.Root(), []byte("forged"), forged))
+	})
+}
@@ -0,0 +1,46 @@
+package merkle
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidSide = errors.New("invalid proof element side")
+
+// String returns "left" or "right"
+func (s Side) String() string {
+	switch s {
+	case Left:
+		return "left"
+	case Right:
+		return "right"
+	default:
+		return fmt.Sprintf("Side(%d)", int8(s))
+	}
+}
+
+// Valid reports whether the side is Left or Right
+func (s Side) Valid() bool {
+	return s == Left || s == Right
+}
+
+// MarshalText encodes the side as "left" or "right", so it reads naturally in JSON
+func (s Side) MarshalText() ([]byte, error) {
+	if !s.Valid() {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSide, int8(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes "left" or "right"
+func (s *Side) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "left":
+		*s = Left
+	case "right":
+		*s = Right
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidSide, b)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package merkle
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Side(t *testing.T) {
+	t.Run("should stringify sides", func(t *testing.T) {
+		require.Equal(t, "left", Left.String())
+		require.Equal(t, "right", Right.String())
+		require.Equal(t, "Side(5)", Side(5).String())
+	})
+
+	t.Run("should round-trip through JSON", func(t *testing.T) {
+		b, err := json.Marshal(ProofElement{Hash: []byte{1}, Side: Right})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"Hash":"AQ==","Side":"right"}`, string(b))
+
+		var pe ProofElement
+		require.NoError(t, json.Unmarshal(b, &pe))
+		require.Equal(t, Right, pe.Side)
+	})
+
+	t.Run("should reject unknown sides", func(t *testing.T) {
+		_, err := json.Marshal(ProofElement{Side: Side(2)})
+		require.ErrorIs(t, err, ErrInvalidSide)
+
+		var pe ProofElement
+		require.ErrorIs(t, json.Unmarshal([]byte(`{"Side":"up"}`), &pe), ErrInvalidSide)
+		require.False(t, Side(-1).Valid())
+	})
+}
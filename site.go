@@ -0,0 +1,97 @@
+package merkle
+
+import (
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var ErrStaticUnsupported = errors.New("tree hashing cannot be verified by the static site verifier")
+
+//go:embed site/verify.js
+var siteVerifier []byte
+
+// SiteRoot is the root.json of a static proof site
+type SiteRoot struct {
+	TreeSize      int    `json:"tree_size"`
+	Root          []byte `json:"root"`
+	HashAlgorithm string `json:"hash_algorithm"`
+	LeafPrefix    []byte `json:"leaf_prefix,omitempty"`
+	NodePrefix    []byte `json:"node_prefix,omitempty"`
+}
+
+// SiteProof is the proof file of a leaf in a static proof site
+type SiteProof struct {
+	Index int    `json:"index"`
+	Hash  []byte `json:"hash"`
+	Proof Proof  `json:"proof"`
+	Value any    `json:"value,omitempty"`
+}
+
+// WriteStaticSite writes the tree as a static directory servable from any CDN,
+// so publishers of allowlists and registries need no backend:
+//
+//	root.json               the SiteRoot
+//	proofs/<leaf hash>.json a SiteProof per leaf, named by its hex leaf hash
+//	verify.js               an ES module verifying leaves with WebCrypto
+//
+// Only plain SHA-2 trees, optionally with leaf and node prefixes, can be
+// written; other trees return ErrStaticUnsupported.
+func (m *MerkleTree) WriteStaticSite(dir string) error {
+	if err := m.checkStatic(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "proofs"), 0o755); err != nil {
+		return err
+	}
+
+	root := SiteRoot{TreeSize: len(m.leafs), Root: m.root.hash, HashAlgorithm: m.HashAlgorithm(), LeafPrefix: m.leafPrefix, NodePrefix: m.nodePrefix}
+	if err := writeJSON(filepath.Join(dir, "root.json"), root); err != nil {
+		return err
+	}
+
+	written := map[string]bool{}
+	for i, leaf := range m.leafs {
+		name := hex.EncodeToString(leaf.hash)
+		if written[name] {
+			// a duplicate leaf is proven by its first occurrence
+			continue
+		}
+		written[name] = true
+		proof, err := m.auditedProof(leaf)
+		if err != nil {
+			return err
+		}
+		if err := writeJSON(filepath.Join(dir, "proofs", name+".json"), SiteProof{Index: i, Hash: leaf.hash, Proof: proof, Value: leaf.value}); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filepath.Join(dir, "verify.js"), siteVerifier, 0o644)
+}
+
+// checkStatic returns ErrStaticUnsupported unless the leaf and node hashes can
+// be recomputed by the site verifier
+func (m *MerkleTree) checkStatic() error {
+	switch name := m.HashAlgorithm(); {
+	case m.scheme != nil:
+		return fmt.Errorf("%w: scheme %s", ErrStaticUnsupported, name)
+	case name != "sha256" && name != "sha384" && name != "sha512":
+		return fmt.Errorf("%w: hash %q", ErrStaticUnsupported, name)
+	case m.blinding || m.swapOrder || m.reverse || len(m.personalization) > 0:
+		return fmt.Errorf("%w: custom leaf or node encoding", ErrStaticUnsupported)
+	}
+	return nil
+}
+
+// writeJSON writes v as JSON to path
+func writeJSON(path string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
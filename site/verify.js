@@ -0,0 +1,87 @@
+// Verifier for proof sites written by WriteStaticSite. It needs no backend:
+// the root and proofs are fetched as static files from the site's base URL
+// and hashes are computed with WebCrypto.
+
+const algorithms = { sha256: "SHA-256", sha384: "SHA-384", sha512: "SHA-512" };
+
+const decode = (b64) => Uint8Array.from(atob(b64 ?? ""), (c) => c.charCodeAt(0));
+
+const hex = (bytes) => Array.from(bytes, (b) => b.toString(16).padStart(2, "0")).join("");
+
+const bytes = (data) => (typeof data === "string" ? new TextEncoder().encode(data) : new Uint8Array(data));
+
+const equal = (a, b) => a.length === b.length && a.every((v, i) => v === b[i]);
+
+function concat(...parts) {
+  const out = new Uint8Array(parts.reduce((n, p) => n + p.length, 0));
+  let offset = 0;
+  for (const p of parts) {
+    out.set(p, offset);
+    offset += p.length;
+  }
+  return out;
+}
+
+async function digest(site, ...parts) {
+  return new Uint8Array(await crypto.subtle.digest(site.algorithm, concat(...parts)));
+}
+
+async function fetchJSON(url) {
+  const res = await fetch(url);
+  if (res.status === 404) {
+    return null;
+  }
+  if (!res.ok) {
+    throw new Error(`merkle: fetching ${url}: ${res.status}`);
+  }
+  return res.json();
+}
+
+// loadSite fetches the root of the site at base
+export async function loadSite(base) {
+  const root = await fetchJSON(`${base}/root.json`);
+  if (root === null) {
+    throw new Error(`merkle: no root at ${base}`);
+  }
+  const algorithm = algorithms[root.hash_algorithm];
+  if (!algorithm) {
+    throw new Error(`merkle: unsupported hash algorithm ${root.hash_algorithm}`);
+  }
+  return {
+    base,
+    algorithm,
+    treeSize: root.tree_size,
+    root: decode(root.root),
+    leafPrefix: decode(root.leaf_prefix),
+    nodePrefix: decode(root.node_prefix),
+  };
+}
+
+// leafHash returns the hex leaf hash of data, which names its proof file
+export async function leafHash(site, data) {
+  return hex(await digest(site, site.leafPrefix, bytes(data)));
+}
+
+// verifyProof reports whether proof includes data under the site's root
+export async function verifyProof(site, data, proof) {
+  let hash = await digest(site, site.leafPrefix, bytes(data));
+  for (const { Hash, Side } of proof ?? []) {
+    const sibling = decode(Hash);
+    if (Side === "left") {
+      hash = await digest(site, site.nodePrefix, sibling, hash);
+    } else if (Side === "right") {
+      hash = await digest(site, site.nodePrefix, hash, sibling);
+    } else {
+      return false;
+    }
+  }
+  return equal(hash, site.root);
+}
+
+// verify reports whether data, a string or bytes, is a leaf of the site at
+// base, such as an address on an allowlist
+export async function verify(base, data) {
+  const site = typeof base === "string" ? await loadSite(base) : base;
+  const leaf = await fetchJSON(`${site.base}/proofs/${await leafHash(site, data)}.json`);
+  return leaf !== null && verifyProof(site, data, leaf.proof);
+}
@@ -0,0 +1,65 @@
+package merkle
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WriteStaticSite(t *testing.T) {
+	t.Run("should write the root and a verifying proof per leaf", func(t *testing.T) {
+		for _, opts := range [][]Option{nil, {WithDomainSeparation([]byte{0}, []byte{1})}, {WithHashFunction(sha512.New384)}} {
+			var data [][]byte
+			for i := 0; i < 5; i++ {
+				data = append(data, []byte(fmt.Sprint("0xaddr", i)))
+			}
+			data = append(data, data[0])
+			tree, err := New(data, opts...)
+			require.NoError(t, err)
+
+			dir := t.TempDir()
+			require.NoError(t, tree.WriteStaticSite(dir))
+
+			var root SiteRoot
+			readJSON(t, filepath.Join(dir, "root.json"), &root)
+			require.Equal(t, 6, root.TreeSize)
+			require.Equal(t, tree.Root(), root.Root)
+			require.Equal(t, tree.HashAlgorithm(), root.HashAlgorithm)
+
+			entries, err := os.ReadDir(filepath.Join(dir, "proofs"))
+			require.NoError(t, err)
+			require.Len(t, entries, 5)
+			for i, item := range data[:5] {
+				var proof SiteProof
+				readJSON(t, filepath.Join(dir, "proofs", hex.EncodeToString(tree.hashLeaf(item))+".json"), &proof)
+				require.Equal(t, i, proof.Index)
+				require.True(t, tree.VerifyData(item, proof.Proof))
+			}
+
+			js, err := os.ReadFile(filepath.Join(dir, "verify.js"))
+			require.NoError(t, err)
+			require.Equal(t, siteVerifier, js)
+		}
+	})
+
+	t.Run("should reject hashing the verifier cannot reproduce", func(t *testing.T) {
+		data := [][]byte{[]byte("a"), []byte("b")}
+		for _, opt := range []Option{WithHMACKey("k1", []byte("key")), WithBlinding(), WithPersonalization("app"), WithHashTruncation(16), WithScheme(SchemeRFC6962)} {
+			tree, err := New(data, opt)
+			require.NoError(t, err)
+			require.ErrorIs(t, tree.WriteStaticSite(t.TempDir()), ErrStaticUnsupported)
+		}
+	})
+}
+
+func readJSON(t *testing.T, path string, v any) {
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, v))
+}
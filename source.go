@@ -0,0 +1,102 @@
+package merkle
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// LeafSource streams leaf data; Next returns io.EOF once exhausted
+type LeafSource interface {
+	Next() ([]byte, error)
+}
+
+// NewFromSource creates a Merkle tree from every leaf of the source
+func NewFromSource(src LeafSource, opts ...Option) (*MerkleTree, error) {
+	b := NewBuilder(opts...)
+	err := eachLeaf(src, func(data []byte) error {
+		b.Add(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.Build()
+}
+
+// RootFromSource computes the root over every leaf of the source while keeping
+// only O(log n) hashes, so large sources never have to fit in memory. Leaves
+// are committed in source order, so sorting must happen upstream.
+func RootFromSource(src LeafSource, opts ...Option) ([]byte, error) {
+	c := NewCompactAppender(opts...)
+	if err := eachLeaf(src, c.Append); err != nil {
+		return nil, err
+	}
+	return c.Root()
+}
+
+// RowsSource is a LeafSource over the rows of a query result, encoding each
+// row as its columns in order: a zero byte for NULL, or a one byte followed
+// by the uvarint length and bytes of the column's text form
+type RowsSource struct {
+	rows *sql.Rows
+	cols []sql.NullString // Valid tells NULL from the empty string, which RawBytes scans as nil too
+	dest []any
+}
+
+// NewRowsSource creates a leaf source over the remaining rows; the caller
+// still owns rows and must close it
+func NewRowsSource(rows *sql.Rows) (*RowsSource, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &RowsSource{rows: rows, cols: make([]sql.NullString, len(columns)), dest: make([]any, len(columns))}
+	for i := range s.cols {
+		s.dest[i] = &s.cols[i]
+	}
+	return s, nil
+}
+
+// Next returns the encoding of the next row
+func (s *RowsSource) Next() ([]byte, error) {
+	if !s.rows.Next() {
+		if err := s.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	if err := s.rows.Scan(s.dest...); err != nil {
+		return nil, err
+	}
+
+	var row []byte
+	for _, col := range s.cols {
+		if !col.Valid {
+			row = append(row, 0)
+			continue
+		}
+		row = append(row, 1)
+		row = binary.AppendUvarint(row, uint64(len(col.String)))
+		row = append(row, col.String...)
+	}
+	return row, nil
+}
+
+// eachLeaf calls fn with every leaf of the source
+func eachLeaf(src LeafSource, fn func(data []byte) error) error {
+	for {
+		data, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+}
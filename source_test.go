@@ -0,0 +1,152 @@
+package merkle
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewFromSource(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+
+	t.Run("should match a tree built from the same leaves", func(t *testing.T) {
+		tree, err := NewFromSource(&sliceSource{leaves: data}, WithHashFunction(mockHash))
+		require.NoError(t, err)
+		require.Equal(t, "hash(hash(hash(a)hash(b))hash(hash(c)hash(c)))", string(tree.Root()))
+	})
+
+	t.Run("should return error for empty sources", func(t *testing.T) {
+		_, err := NewFromSource(&sliceSource{})
+		require.ErrorIs(t, err, ErrEmptyData)
+	})
+
+	t.Run("should return source errors", func(t *testing.T) {
+		failure := errors.New("failure")
+		_, err := NewFromSource(&sliceSource{leaves: data, err: failure})
+		require.ErrorIs(t, err, failure)
+	})
+}
+
+func Test_RootFromSource(t *testing.T) {
+	t.Run("should match the root of the full tree", func(t *testing.T) {
+		data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+		tree, err := New(data)
+		require.NoError(t, err)
+
+		root, err := RootFromSource(&sliceSource{leaves: data})
+		require.NoError(t, err)
+		require.Equal(t, tree.Root(), root)
+	})
+}
+
+func Test_RowsSource(t *testing.T) {
+	db := sql.OpenDB(fakeConnector{
+		columns: []string{"id", "name"},
+		rows:    [][]driver.Value{{int64(1), "alice"}, {int64(2), nil}},
+	})
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, name FROM users ORDER BY id")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	src, err := NewRowsSource(rows)
+	require.NoError(t, err)
+
+	t.Run("should encode rows column by column", func(t *testing.T) {
+		row, err := src.Next()
+		require.NoError(t, err)
+		require.Equal(t, []byte("\x01\x011\x01\x05alice"), row)
+
+		row, err = src.Next()
+		require.NoError(t, err)
+		require.Equal(t, []byte("\x01\x012\x00"), row)
+
+		_, err = src.Next()
+		require.ErrorIs(t, err, io.EOF)
+	})
+	t.Run("should not confuse NULL with the empty string", func(t *testing.T) {
+		root := func(value driver.Value) []byte {
+			db := sql.OpenDB(fakeConnector{
+				columns: []string{"name"},
+				rows:    [][]driver.Value{{value}},
+			})
+			defer db.Close()
+
+			rows, err := db.Query("SELECT name FROM users")
+			require.NoError(t, err)
+			defer rows.Close()
+
+			src, err := NewRowsSource(rows)
+			require.NoError(t, err)
+			root, err := RootFromSource(src)
+			require.NoError(t, err)
+			return root
+		}
+
+		require.NotEqual(t, root(""), root(nil))
+	})
+}
+
+// sliceSource is a LeafSource over a slice that fails with err once exhausted, if set
+type sliceSource struct {
+	leaves [][]byte
+	err    error
+}
+
+func (s *sliceSource) Next() ([]byte, error) {
+	if len(s.leaves) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	data := s.leaves[0]
+	s.leaves = s.leaves[1:]
+	return data, nil
+}
+
+// fakeConnector is a database/sql driver answering every query with fixed rows
+type fakeConnector struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (c fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn fakeConnector
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return fakeStmt(c), nil }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, errors.ErrUnsupported }
+
+type fakeStmt fakeConn
+
+func (s fakeStmt) Close() error                               { return nil }
+func (s fakeStmt) NumInput() int                              { return -1 }
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) { return nil, errors.ErrUnsupported }
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{columns: s.columns, rows: s.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
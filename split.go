@@ -0,0 +1,100 @@
+package merkle
+
+// Split partitions the tree into trees over the leaves [0,i) and [i,n) with
+// the same options. Subtrees that stay complete and aligned in a new tree keep
+// their hash instead of being rehashed, so splitting at a multiple of a large
+// power of two costs little hashing.
+func (m *MerkleTree) Split(i int) (*MerkleTree, *MerkleTree, error) {
+	m.Flush()
+	if i <= 0 || i >= len(m.leafs) {
+		return nil, nil, ErrOutOfRange
+	}
+	return m.subtree(0, i), m.subtree(i, len(m.leafs)-i), nil
+}
+
+// subtree builds a tree over count leaves starting at offset, reusing the
+// hashes of aligned complete subtrees
+func (m *MerkleTree) subtree(offset, count int) *MerkleTree {
+	t := m.cloneOptions()
+	t.leafs = make([]*Node, count, max(count, t.capacity))
+	for i, leaf := range m.leafs[offset : offset+count] {
+		t.leafs[i] = &Node{hash: leaf.hash, data: t.owned(leaf.data), value: leaf.value, blind: t.owned(leaf.blind)}
+	}
+
+	nodes := t.leafs
+	for level := 1; ; level++ {
+		t.levels = append(t.levels, nodes)
+		if len(nodes) == 1 {
+			break
+		}
+
+		parents := make([]*Node, 0, (len(nodes)+1)/2)
+		for i := 0; i < len(nodes); i += 2 {
+			left, right := nodes[i], nodes[i]
+			if i+1 < len(nodes) {
+				right = nodes[i+1]
+			} else if t.promoteOdd {
+				parents = append(parents, left)
+				continue
+			}
+
+			parent := &Node{left: left, right: right, hash: m.alignedHash(level, offset, len(parents), count)}
+			if parent.hash == nil {
+				parent.hash = t.hashNode(left.hash, right.hash)
+			}
+			left.parent, right.parent = parent, parent
+			parents = append(parents, parent)
+		}
+		nodes = parents
+	}
+	t.root = nodes[0]
+
+	return t
+}
+
+// alignedHash returns the hash of the node at the given level and index of a
+// subtree over count leaves starting at offset, if the same complete subtree
+// exists in this tree, or nil
+func (m *MerkleTree) alignedHash(level, offset, index, count int) []byte {
+	size := 1 << level
+	if offset%size != 0 || (index+1)*size > count || level >= len(m.levels) {
+		return nil
+	}
+	return m.levels[level][offset/size+index].hash
+}
+
+// cloneOptions returns an empty tree with the same options
+func (m *MerkleTree) cloneOptions() *MerkleTree {
+	t := &MerkleTree{
+		hashFn:          m.hashFn,
+		promoteOdd:      m.promoteOdd,
+		dropData:        m.dropData,
+		sortLeaves:      m.sortLeaves,
+		capacity:        m.capacity,
+		arity:           m.arity,
+		commitment:      m.commitment,
+		scheme:          m.scheme,
+		truncate:        m.truncate,
+		swapOrder:       m.swapOrder,
+		reverse:         m.reverse,
+		preimageAudit:   m.preimageAudit,
+		logger:          m.logger,
+		blinding:        m.blinding,
+		audited:         m.audited,
+		constantTime:    m.constantTime,
+		fipsOnly:        m.fipsOnly,
+		leafPrefix:      m.leafPrefix,
+		nodePrefix:      m.nodePrefix,
+		textEncoding:    m.textEncoding,
+		personalization: m.personalization,
+		keyID:           m.keyID,
+		hmacKey:         m.hmacKey,
+		history:         m.history,
+		debounce:        m.debounce,
+		async:           m.async,
+	}
+	if m.zeroize {
+		WithZeroizeOnDrop()(t)
+	}
+	return t
+}
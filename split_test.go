@@ -0,0 +1,66 @@
+package merkle
+
+import (
+	"hash"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Split(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+
+	for _, mode := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "duplicate odd nodes"},
+		{name: "promote odd nodes", opts: []Option{WithOddNodePromotion()}},
+	} {
+		t.Run("should match trees built from each part with "+mode.name, func(t *testing.T) {
+			tree, err := New(data, append(mode.opts, WithHashFunction(mockHash))...)
+			require.NoError(t, err)
+
+			for i := 1; i < len(data); i++ {
+				left, right, err := tree.Split(i)
+				require.NoError(t, err)
+
+				wantLeft, err := New(data[:i], append(mode.opts, WithHashFunction(mockHash))...)
+				require.NoError(t, err)
+				wantRight, err := New(data[i:], append(mode.opts, WithHashFunction(mockHash))...)
+				require.NoError(t, err)
+				require.True(t, wantLeft.Equal(left))
+				require.True(t, wantRight.Equal(right))
+
+				proof, err := right.GenerateProof([]byte("e"))
+				require.NoError(t, err)
+				require.True(t, right.VerifyData([]byte("e"), proof))
+			}
+		})
+	}
+
+	t.Run("should reuse aligned subtree hashes", func(t *testing.T) {
+		calls := 0
+		counting := func() hash.Hash {
+			calls++
+			return mockHash()
+		}
+		tree, err := New(append(data, []byte("f"), []byte("g"), []byte("h")), WithHashFunction(counting))
+		require.NoError(t, err)
+
+		calls = 0
+		_, _, err = tree.Split(4)
+		require.NoError(t, err)
+		require.Zero(t, calls)
+	})
+
+	t.Run("should return error for indexes not inside the tree", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+
+		for _, i := range []int{0, len(data), -1} {
+			_, _, err := tree.Split(i)
+			require.ErrorIs(t, err, ErrOutOfRange)
+		}
+	})
+}
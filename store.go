@@ -0,0 +1,147 @@
+package merkle
+
+import (
+	"bytes"
+	"sync"
+)
+
+// ChunkStore is a content-addressed store of leaf data keyed by leaf hash and
+// reference counted across the trees built from it, so trees sharing chunks
+// store each of them once. It is safe for concurrent use.
+type ChunkStore struct {
+	mu     sync.Mutex
+	m      *MerkleTree
+	opts   []Option
+	chunks map[string]*storedChunk
+	size   int
+}
+
+// storedChunk is the data of a chunk and the number of references to it
+type storedChunk struct {
+	data []byte
+	refs int
+}
+
+// NewChunkStore creates an empty store for trees with the given options
+func NewChunkStore(opts ...Option) *ChunkStore {
+	return &ChunkStore{m: newVerifier(opts...), opts: opts, chunks: map[string]*storedChunk{}}
+}
+
+// Put stores a copy of the data if it is not stored yet, adds a reference to
+// it and returns its leaf hash
+func (s *ChunkStore) Put(data []byte) []byte {
+	hash := s.m.hashLeaf(data)
+	s.put(hash, data)
+	return hash
+}
+
+// put stores a copy of the data under its leaf hash if it is not stored yet
+// and adds a reference to it
+func (s *ChunkStore) put(hash, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chunk, ok := s.chunks[string(hash)]
+	if !ok {
+		chunk = &storedChunk{data: bytes.Clone(data)}
+		s.chunks[string(hash)] = chunk
+		s.size += len(data)
+	}
+	chunk.refs++
+}
+
+// Get returns the data of the chunk with the given leaf hash
+func (s *ChunkStore) Get(hash []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chunk, ok := s.chunks[string(hash)]
+	if !ok {
+		return nil, ErrNotFoundData
+	}
+	return chunk.data, nil
+}
+
+// Release drops a reference to the chunk with the given leaf hash, deleting
+// it once no references remain
+func (s *ChunkStore) Release(hash []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chunk, ok := s.chunks[string(hash)]
+	if !ok {
+		return ErrNotFoundData
+	}
+	if chunk.refs--; chunk.refs == 0 {
+		delete(s.chunks, string(hash))
+		s.size -= len(chunk.data)
+	}
+	return nil
+}
+
+// Len returns the number of distinct chunks stored
+func (s *ChunkStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.chunks)
+}
+
+// Size returns the total size in bytes of the distinct chunks stored
+func (s *ChunkStore) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// NewTree stores every chunk and builds a tree over them with the store's
+// options; the tree keeps only leaf hashes, since the data lives in the store
+func (s *ChunkStore) NewTree(chunks [][]byte) (*MerkleTree, error) {
+	if len(chunks) == 0 {
+		return nil, ErrEmptyData
+	}
+
+	hashes := make([][]byte, len(chunks))
+	for i, chunk := range chunks {
+		hash, err := s.m.hashData(chunk)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = hash
+	}
+	b := NewBuilder(s.opts...).Grow(len(chunks))
+	for i, chunk := range chunks {
+		s.put(hashes[i], chunk)
+		b.AddHash(hashes[i])
+	}
+	t, err := b.Build()
+	if err != nil {
+		for _, hash := range hashes {
+			s.Release(hash)
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// Chunks returns the data of every leaf of a tree built by NewTree
+func (s *ChunkStore) Chunks(t *MerkleTree) ([][]byte, error) {
+	chunks := make([][]byte, len(t.leafs))
+	for i, leaf := range t.leafs {
+		data, err := s.Get(leaf.hash)
+		if err != nil {
+			return nil, err
+		}
+		chunks[i] = data
+	}
+	return chunks, nil
+}
+
+// ReleaseTree drops the references of every leaf of a tree built by NewTree
+func (s *ChunkStore) ReleaseTree(t *MerkleTree) error {
+	for _, leaf := range t.leafs {
+		if err := s.Release(leaf.hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
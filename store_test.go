@@ -0,0 +1,75 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ChunkStore(t *testing.T) {
+	store := NewChunkStore()
+
+	monday, err := store.NewTree([][]byte{[]byte("base"), []byte("config-v1"), []byte("data")})
+	require.NoError(t, err)
+	tuesday, err := store.NewTree([][]byte{[]byte("base"), []byte("config-v2"), []byte("data")})
+	require.NoError(t, err)
+
+	t.Run("should store shared chunks once", func(t *testing.T) {
+		require.Equal(t, 4, store.Len())
+		require.Equal(t, len("base")+len("config-v1")+len("config-v2")+len("data"), store.Size())
+	})
+
+	t.Run("should build the same roots as from the data", func(t *testing.T) {
+		want, err := New([][]byte{[]byte("base"), []byte("config-v1"), []byte("data")})
+		require.NoError(t, err)
+		require.Equal(t, want.Root(), monday.Root())
+
+		proof, err := monday.GenerateProofAt(1)
+		require.NoError(t, err)
+		require.True(t, monday.VerifyData([]byte("config-v1"), proof))
+	})
+
+	t.Run("should return the chunks of a tree", func(t *testing.T) {
+		chunks, err := store.Chunks(tuesday)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("base"), []byte("config-v2"), []byte("data")}, chunks)
+	})
+
+	t.Run("should delete chunks once unreferenced", func(t *testing.T) {
+		require.NoError(t, store.ReleaseTree(monday))
+		require.Equal(t, 3, store.Len())
+
+		chunks, err := store.Chunks(tuesday)
+		require.NoError(t, err)
+		require.Len(t, chunks, 3)
+
+		require.NoError(t, store.ReleaseTree(tuesday))
+		require.Zero(t, store.Len())
+		require.Zero(t, store.Size())
+	})
+
+	t.Run("should keep a copy of the data", func(t *testing.T) {
+		store := NewChunkStore()
+		chunk := []byte("base")
+		hash := store.Put(chunk)
+		copy(chunk, "edit")
+
+		data, err := store.Get(hash)
+		require.NoError(t, err)
+		require.Equal(t, []byte("base"), data)
+	})
+
+	t.Run("should release the chunks of trees that fail to build", func(t *testing.T) {
+		store := NewChunkStore(WithArity(4))
+		_, err := store.NewTree([][]byte{[]byte("base"), []byte("data")})
+		require.ErrorIs(t, err, ErrArity)
+		require.Zero(t, store.Len())
+		require.Zero(t, store.Size())
+	})
+
+	t.Run("should return error for unknown chunks", func(t *testing.T) {
+		_, err := store.Get([]byte("missing"))
+		require.ErrorIs(t, err, ErrNotFoundData)
+		require.ErrorIs(t, store.Release([]byte("missing")), ErrNotFoundData)
+	})
+}
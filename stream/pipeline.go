@@ -0,0 +1,251 @@
+package stream
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chakra-guy/merkle"
+)
+
+var (
+	ErrClosed  = errors.New("pipeline is closed")
+	ErrDropped = errors.New("record dropped by a full pipeline")
+)
+
+// Policy decides what Push does when the pipeline buffer is full
+type Policy int
+
+const (
+	// Block makes Push wait for room, pushing back on the producer
+	Block Policy = iota
+	// DropNewest drops the pushed record and returns ErrDropped
+	DropNewest
+	// DropOldest evicts the oldest buffered record to make room
+	DropOldest
+)
+
+// PipelineConfig configures a Pipeline. Config.Signer is optional: without
+// it, published roots carry no signature.
+type PipelineConfig struct {
+	Config
+	// Buffer is the capacity of the bounded input; defaults to 1024
+	Buffer int
+	// Policy is applied when the buffer is full; defaults to Block
+	Policy Policy
+	// PublishInterval is the period roots are published at, if any leaves
+	// were appended since the last one; zero publishes after every batch
+	PublishInterval time.Duration
+}
+
+// Pipeline ingests records through a bounded buffer into batches appended to
+// a tree, and periodically publishes the root, so the tree can sit behind a
+// high-throughput feed. Close flushes what is buffered; cancelling Run drops it.
+type Pipeline struct {
+	cfg     PipelineConfig
+	in      chan []byte
+	stopped chan struct{} // closed when Run returns, as nothing drains in any more
+	mu      sync.RWMutex
+	closed  bool
+	dropped atomic.Int64
+
+	tree *merkle.MerkleTree
+	size int
+}
+
+// NewPipeline creates a pipeline; Run must be running for pushes to drain
+func NewPipeline(cfg PipelineConfig) *Pipeline {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 1024
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1
+	}
+	if cfg.SignerOpts == nil {
+		cfg.SignerOpts = crypto.Hash(0)
+	}
+	return &Pipeline{cfg: cfg, in: make(chan []byte, cfg.Buffer), stopped: make(chan struct{})}
+}
+
+// Push offers a record to the pipeline, applying the policy when it is full;
+// once Run has returned, pushes fail with ErrClosed
+func (p *Pipeline) Push(ctx context.Context, data []byte) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	select {
+	case <-p.stopped:
+		return ErrClosed
+	default:
+	}
+	if p.closed {
+		return ErrClosed
+	}
+
+	for {
+		select {
+		case p.in <- data:
+			return nil
+		default:
+		}
+
+		switch p.cfg.Policy {
+		case DropNewest:
+			p.dropped.Add(1)
+			return ErrDropped
+		case DropOldest:
+			select {
+			case <-p.in:
+				p.dropped.Add(1)
+			default:
+			}
+		default:
+			select {
+			case p.in <- data:
+				return nil
+			case <-p.stopped:
+				return ErrClosed
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// Close stops accepting records; Run then appends everything buffered,
+// publishes the final root and returns
+func (p *Pipeline) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.in)
+	}
+}
+
+// Dropped returns the number of records dropped by the policy so far
+func (p *Pipeline) Dropped() int64 {
+	return p.dropped.Load()
+}
+
+// Tree returns the tree built so far, or nil before the first batch; it must
+// not be used concurrently with Run
+func (p *Pipeline) Tree() *merkle.MerkleTree {
+	return p.tree
+}
+
+// Run drains the pipeline until it is closed or ctx is done, calling publish
+// with the root at every publication
+func (p *Pipeline) Run(ctx context.Context, publish func(SignedRoot) error) error {
+	defer close(p.stopped)
+
+	var pending [][]byte
+	published := 0
+
+	var flushTimer *time.Timer
+	var flushTimeout <-chan time.Time
+	var ticks <-chan time.Time
+	if p.cfg.PublishInterval > 0 {
+		ticker := time.NewTicker(p.cfg.PublishInterval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	appendPending := func() error {
+		if flushTimer != nil {
+			flushTimer.Stop()
+			flushTimer, flushTimeout = nil, nil
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		err := p.append(pending)
+		pending = nil
+		return err
+	}
+	publishRoot := func() error {
+		if p.size == published {
+			return nil
+		}
+		root, err := p.root()
+		if err != nil {
+			return err
+		}
+		published = p.size
+		return publish(root)
+	}
+
+	for {
+		select {
+		case data, ok := <-p.in:
+			if !ok {
+				if err := appendPending(); err != nil {
+					return err
+				}
+				return publishRoot()
+			}
+			pending = append(pending, data)
+			if len(pending) < p.cfg.BatchSize {
+				if flushTimer == nil && p.cfg.FlushInterval > 0 {
+					flushTimer = time.NewTimer(p.cfg.FlushInterval)
+					flushTimeout = flushTimer.C
+				}
+				continue
+			}
+			if err := appendPending(); err != nil {
+				return err
+			}
+			if ticks == nil {
+				if err := publishRoot(); err != nil {
+					return err
+				}
+			}
+		case <-flushTimeout:
+			if err := appendPending(); err != nil {
+				return err
+			}
+			if ticks == nil {
+				if err := publishRoot(); err != nil {
+					return err
+				}
+			}
+		case <-ticks:
+			if err := publishRoot(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// append adds a batch of records to the tree
+func (p *Pipeline) append(batch [][]byte) error {
+	if p.tree == nil {
+		tree, err := merkle.New(batch, p.cfg.Options...)
+		if err != nil {
+			return err
+		}
+		p.tree = tree
+	} else if err := p.tree.AddLeaves(batch); err != nil {
+		return err
+	}
+	p.size += len(batch)
+	return nil
+}
+
+// root returns the current root, signed if the pipeline has a signer
+func (p *Pipeline) root() (SignedRoot, error) {
+	r := SignedRoot{Size: p.size, Root: p.tree.Root()}
+	if p.cfg.Signer != nil {
+		sig, err := p.cfg.Signer.Sign(rand.Reader, r.Root, p.cfg.SignerOpts)
+		if err != nil {
+			return SignedRoot{}, err
+		}
+		r.Signature = sig
+	}
+	return r, nil
+}
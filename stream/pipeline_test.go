@@ -0,0 +1,157 @@
+package stream
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Pipeline(t *testing.T) {
+	records := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	expected, err := merkle.New(records)
+	require.NoError(t, err)
+
+	t.Run("should flush buffered records and publish the final root on close", func(t *testing.T) {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+		p := NewPipeline(PipelineConfig{Config: Config{BatchSize: 2, Signer: priv}, Buffer: len(records)})
+		for _, r := range records {
+			require.NoError(t, p.Push(context.Background(), r))
+		}
+		p.Close()
+
+		var roots []SignedRoot
+		require.NoError(t, p.Run(context.Background(), func(r SignedRoot) error {
+			roots = append(roots, r)
+			return nil
+		}))
+		require.Len(t, roots, 3)
+		require.Equal(t, []int{2, 4, 5}, []int{roots[0].Size, roots[1].Size, roots[2].Size})
+		require.Equal(t, expected.Root(), roots[2].Root)
+		require.True(t, ed25519.Verify(pub, roots[2].Root, roots[2].Signature))
+	})
+
+	t.Run("should publish unsigned roots without a signer", func(t *testing.T) {
+		p := NewPipeline(PipelineConfig{Buffer: len(records)})
+		for _, r := range records {
+			require.NoError(t, p.Push(context.Background(), r))
+		}
+		p.Close()
+
+		var last SignedRoot
+		require.NoError(t, p.Run(context.Background(), func(r SignedRoot) error {
+			last = r
+			return nil
+		}))
+		require.Equal(t, expected.Root(), last.Root)
+		require.Nil(t, last.Signature)
+		require.Equal(t, expected.Root(), p.Tree().Root())
+	})
+
+	t.Run("should publish only at the publish interval", func(t *testing.T) {
+		p := NewPipeline(PipelineConfig{Config: Config{BatchSize: 1}, PublishInterval: time.Hour})
+		for _, r := range records {
+			require.NoError(t, p.Push(context.Background(), r))
+		}
+		p.Close()
+
+		var roots []SignedRoot
+		require.NoError(t, p.Run(context.Background(), func(r SignedRoot) error {
+			roots = append(roots, r)
+			return nil
+		}))
+		require.Len(t, roots, 1)
+		require.Equal(t, 5, roots[0].Size)
+	})
+
+	t.Run("should block producers when the buffer is full", func(t *testing.T) {
+		p := NewPipeline(PipelineConfig{Buffer: 1})
+		require.NoError(t, p.Push(context.Background(), records[0]))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, p.Push(ctx, records[1]), context.DeadlineExceeded)
+		require.Zero(t, p.Dropped())
+	})
+
+	t.Run("should reject pushes once Run has returned", func(t *testing.T) {
+		p := NewPipeline(PipelineConfig{Buffer: 1})
+		require.NoError(t, p.Push(context.Background(), records[0]))
+
+		failure := errors.New("publish failed")
+		require.ErrorIs(t, p.Run(context.Background(), func(SignedRoot) error { return failure }), failure)
+		for _, r := range records[1:3] {
+			require.ErrorIs(t, p.Push(context.Background(), r), ErrClosed)
+		}
+		p.Close()
+	})
+
+	t.Run("should drop the newest record when the buffer is full", func(t *testing.T) {
+		p := NewPipeline(PipelineConfig{Buffer: 2, Policy: DropNewest})
+		for _, r := range records[:2] {
+			require.NoError(t, p.Push(context.Background(), r))
+		}
+		require.ErrorIs(t, p.Push(context.Background(), records[2]), ErrDropped)
+		p.Close()
+
+		var last SignedRoot
+		require.NoError(t, p.Run(context.Background(), func(r SignedRoot) error {
+			last = r
+			return nil
+		}))
+		want, err := merkle.New(records[:2])
+		require.NoError(t, err)
+		require.Equal(t, want.Root(), last.Root)
+		require.EqualValues(t, 1, p.Dropped())
+	})
+
+	t.Run("should drop the oldest record when the buffer is full", func(t *testing.T) {
+		p := NewPipeline(PipelineConfig{Buffer: 2, Policy: DropOldest})
+		for _, r := range records {
+			require.NoError(t, p.Push(context.Background(), r))
+		}
+		p.Close()
+
+		var last SignedRoot
+		require.NoError(t, p.Run(context.Background(), func(r SignedRoot) error {
+			last = r
+			return nil
+		}))
+		want, err := merkle.New(records[3:])
+		require.NoError(t, err)
+		require.Equal(t, want.Root(), last.Root)
+		require.EqualValues(t, 3, p.Dropped())
+	})
+
+	t.Run("should reject pushes after close", func(t *testing.T) {
+		p := NewPipeline(PipelineConfig{})
+		p.Close()
+		require.ErrorIs(t, p.Push(context.Background(), records[0]), ErrClosed)
+	})
+
+	t.Run("should flush partial batches after the flush interval", func(t *testing.T) {
+		p := NewPipeline(PipelineConfig{Config: Config{BatchSize: 10, FlushInterval: time.Millisecond}})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		roots := make(chan SignedRoot, 1)
+		done := make(chan error, 1)
+		go func() {
+			done <- p.Run(ctx, func(r SignedRoot) error {
+				roots <- r
+				return nil
+			})
+		}()
+		require.NoError(t, p.Push(ctx, records[0]))
+
+		r := <-roots
+		require.Equal(t, 1, r.Size)
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+}
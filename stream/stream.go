@@ -0,0 +1,176 @@
+// Package stream appends records consumed from a message stream to a Merkle
+// tree in batches and periodically publishes signed roots over it.
+package stream
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/chakra-guy/merkle"
+)
+
+var ErrNoSigner = errors.New("no signer configured")
+
+// Source is a stream of records; Receive blocks until the next record is
+// available and returns io.EOF once the stream is closed
+type Source interface {
+	Receive(ctx context.Context) ([]byte, error)
+}
+
+// SourceFunc adapts a function to a Source, such as a Kafka reader:
+//
+//	stream.SourceFunc(func(ctx context.Context) ([]byte, error) {
+//		msg, err := reader.ReadMessage(ctx)
+//		return msg.Value, err
+//	})
+type SourceFunc func(ctx context.Context) ([]byte, error)
+
+// Receive calls f
+func (f SourceFunc) Receive(ctx context.Context) ([]byte, error) {
+	return f(ctx)
+}
+
+// SignedRoot is the root of the tree after Size records, signed by the consumer
+type SignedRoot struct {
+	Size      int
+	Root      []byte
+	Signature []byte
+}
+
+// Config configures batching, signing and the hashing of the tree
+type Config struct {
+	// BatchSize is the number of records appended per rebuild; defaults to 1
+	BatchSize int
+	// FlushInterval bounds how long a partial batch waits for more records;
+	// zero waits until the batch is full or the stream ends
+	FlushInterval time.Duration
+	// Signer signs every emitted root, which is passed as the digest
+	Signer crypto.Signer
+	// SignerOpts are passed to Signer; defaults to crypto.Hash(0), as Ed25519 expects
+	SignerOpts crypto.SignerOpts
+	// Options are the tree options
+	Options []merkle.Option
+}
+
+// Consumer appends the records of a source to a tree
+type Consumer struct {
+	src  Source
+	cfg  Config
+	tree *merkle.MerkleTree
+	size int
+}
+
+// NewConsumer creates a consumer of the source
+func NewConsumer(src Source, cfg Config) (*Consumer, error) {
+	if cfg.Signer == nil {
+		return nil, ErrNoSigner
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1
+	}
+	if cfg.SignerOpts == nil {
+		cfg.SignerOpts = crypto.Hash(0)
+	}
+	return &Consumer{src: src, cfg: cfg}, nil
+}
+
+// Tree returns the tree built so far, or nil before the first batch; it must
+// not be used concurrently with Run
+func (c *Consumer) Tree() *merkle.MerkleTree {
+	return c.tree
+}
+
+// Run consumes the source until it ends or ctx is done, calling emit with the
+// signed root after every batch. A pending partial batch is flushed when the
+// source ends, but dropped when ctx is done.
+func (c *Consumer) Run(ctx context.Context, emit func(SignedRoot) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	records := make(chan []byte)
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			data, err := c.src.Receive(ctx)
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case records <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var pending [][]byte
+	var timer *time.Timer
+	var timeout <-chan time.Time
+	flush := func() error {
+		if timer != nil {
+			timer.Stop()
+			timer, timeout = nil, nil
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		root, err := c.append(pending)
+		pending = nil
+		if err != nil {
+			return err
+		}
+		return emit(root)
+	}
+
+	for {
+		select {
+		case data := <-records:
+			pending = append(pending, data)
+			if len(pending) >= c.cfg.BatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			} else if timer == nil && c.cfg.FlushInterval > 0 {
+				timer = time.NewTimer(c.cfg.FlushInterval)
+				timeout = timer.C
+			}
+		case <-timeout:
+			if err := flush(); err != nil {
+				return err
+			}
+		case err := <-errc:
+			if errors.Is(err, io.EOF) {
+				return flush()
+			}
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// append adds a batch of records to the tree and signs the new root
+func (c *Consumer) append(batch [][]byte) (SignedRoot, error) {
+	if c.tree == nil {
+		tree, err := merkle.New(batch, c.cfg.Options...)
+		if err != nil {
+			return SignedRoot{}, err
+		}
+		c.tree = tree
+	} else if err := c.tree.AddLeaves(batch); err != nil {
+		return SignedRoot{}, err
+	}
+	c.size += len(batch)
+
+	root := c.tree.Root()
+	sig, err := c.cfg.Signer.Sign(rand.Reader, root, c.cfg.SignerOpts)
+	if err != nil {
+		return SignedRoot{}, err
+	}
+	return SignedRoot{Size: c.size, Root: root, Signature: sig}, nil
+}
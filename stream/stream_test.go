@@ -0,0 +1,96 @@
+package stream
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewConsumer(t *testing.T) {
+	t.Run("should return error without a signer", func(t *testing.T) {
+		_, err := NewConsumer(chanSource(nil), Config{})
+		require.ErrorIs(t, err, ErrNoSigner)
+	})
+}
+
+func Test_Run(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	records := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+
+	t.Run("should emit signed roots per batch", func(t *testing.T) {
+		c, err := NewConsumer(sliceSource(records), Config{BatchSize: 2, Signer: priv})
+		require.NoError(t, err)
+
+		var roots []SignedRoot
+		require.NoError(t, c.Run(context.Background(), func(r SignedRoot) error {
+			roots = append(roots, r)
+			return nil
+		}))
+
+		require.Len(t, roots, 3)
+		require.Equal(t, []int{2, 4, 5}, []int{roots[0].Size, roots[1].Size, roots[2].Size})
+		for _, r := range roots {
+			require.True(t, ed25519.Verify(pub, r.Root, r.Signature))
+		}
+
+		tree, err := merkle.New(records)
+		require.NoError(t, err)
+		require.Equal(t, tree.Root(), roots[2].Root)
+		require.Equal(t, tree.Root(), c.Tree().Root())
+	})
+
+	t.Run("should flush partial batches after the interval", func(t *testing.T) {
+		ch := make(chan []byte, 1)
+		c, err := NewConsumer(chanSource(ch), Config{BatchSize: 10, FlushInterval: time.Millisecond, Signer: priv})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ch <- []byte("a")
+		err = c.Run(ctx, func(r SignedRoot) error {
+			require.Equal(t, 1, r.Size)
+			cancel()
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("should return emit errors", func(t *testing.T) {
+		failure := errors.New("failure")
+		c, err := NewConsumer(sliceSource(records), Config{Signer: priv})
+		require.NoError(t, err)
+
+		err = c.Run(context.Background(), func(SignedRoot) error { return failure })
+		require.ErrorIs(t, err, failure)
+	})
+}
+
+// sliceSource returns a source of the given records that then ends
+func sliceSource(records [][]byte) Source {
+	return SourceFunc(func(context.Context) ([]byte, error) {
+		if len(records) == 0 {
+			return nil, io.EOF
+		}
+		data := records[0]
+		records = records[1:]
+		return data, nil
+	})
+}
+
+// chanSource returns a source receiving from a channel until ctx is done
+func chanSource(ch chan []byte) Source {
+	return SourceFunc(func(ctx context.Context) ([]byte, error) {
+		select {
+		case data := <-ch:
+			return data, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+}
@@ -0,0 +1,160 @@
+// Package subscribe pushes updated inclusion proofs to clients whenever the
+// root of a tree changes, so clients holding leaves of interest need not poll.
+package subscribe
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/chakra-guy/merkle"
+)
+
+// Update is the proof of a subscribed leaf against a newly published root
+type Update struct {
+	TreeSize int          `json:"tree_size"`
+	Root     []byte       `json:"root"`
+	Leaf     []byte       `json:"leaf"`
+	Proof    merkle.Proof `json:"proof"`
+}
+
+// Hub tracks subscriptions and sends them proofs on each published tree
+type Hub struct {
+	mu   sync.Mutex
+	tree *merkle.MerkleTree
+	subs map[*Subscription]struct{}
+}
+
+// Subscription receives updates for a set of leaf hashes
+type Subscription struct {
+	hub     *Hub
+	leaves  [][]byte
+	updates chan Update
+}
+
+// NewHub creates a hub with no published tree
+func NewHub() *Hub {
+	return &Hub{subs: map[*Subscription]struct{}{}}
+}
+
+// Subscribe registers leaf hashes of interest. Updates are buffered; once the
+// buffer is full the oldest update is dropped, as a newer proof supersedes it.
+// Proofs against the current tree are sent straight away.
+func (h *Hub) Subscribe(leaves [][]byte, buffer int) *Subscription {
+	s := &Subscription{hub: h, leaves: leaves, updates: make(chan Update, max(buffer, 1))}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs[s] = struct{}{}
+	if h.tree != nil {
+		s.notify(h.tree)
+	}
+	return s
+}
+
+// Publish makes the tree current and sends every subscription the new proofs
+// of its leaves that are in the tree; the tree must not change afterwards
+func (h *Hub) Publish(tree *merkle.MerkleTree) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tree = tree
+	for s := range h.subs {
+		s.notify(tree)
+	}
+}
+
+// Updates returns the channel of updates, closed by Close
+func (s *Subscription) Updates() <-chan Update {
+	return s.updates
+}
+
+// Close unregisters the subscription
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if _, ok := s.hub.subs[s]; ok {
+		delete(s.hub.subs, s)
+		close(s.updates)
+	}
+}
+
+// notify sends the proofs of the subscribed leaves in the tree; the hub lock
+// must be held
+func (s *Subscription) notify(tree *merkle.MerkleTree) {
+	root, size := tree.Root(), len(tree.Leaves())
+	for _, leaf := range s.leaves {
+		proof, err := tree.GenerateProofByHash(leaf)
+		if err != nil {
+			continue
+		}
+		u := Update{TreeSize: size, Root: root, Leaf: leaf, Proof: proof}
+		select {
+		case s.updates <- u:
+			continue
+		default:
+		}
+		// only notify sends, under the hub lock, so the freed slot stays free
+		select {
+		case <-s.updates:
+		default:
+		}
+		s.updates <- u
+	}
+}
+
+// ServeHTTP streams updates as Server-Sent Events to a client subscribing to
+// the hex leaf hashes given by the repeated query parameter leaf:
+//
+//	GET /proofs?leaf=<hex>&leaf=<hex>
+//
+//	data: {"tree_size":5,"root":"...","leaf":"...","proof":[...]}
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	var leaves [][]byte
+	for _, param := range r.URL.Query()["leaf"] {
+		leaf, err := hex.DecodeString(param)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid leaf hash %q", param), http.StatusBadRequest)
+			return
+		}
+		leaves = append(leaves, leaf)
+	}
+	if len(leaves) == 0 {
+		http.Error(w, "no leaf hashes to subscribe to", http.StatusBadRequest)
+		return
+	}
+
+	s := h.Subscribe(leaves, 16*len(leaves))
+	defer s.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case u, ok := <-s.Updates():
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(u)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
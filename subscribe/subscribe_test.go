@@ -0,0 +1,104 @@
+package subscribe
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Hub(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	tree, err := merkle.New(data)
+	require.NoError(t, err)
+	bigger, err := merkle.New(append(data, []byte("d")))
+	require.NoError(t, err)
+	leaf := tree.Leaves()[1].Hash
+
+	t.Run("should send proofs on every published root", func(t *testing.T) {
+		h := NewHub()
+		s := h.Subscribe([][]byte{leaf}, 4)
+		defer s.Close()
+
+		h.Publish(tree)
+		u := <-s.Updates()
+		require.Equal(t, 3, u.TreeSize)
+		require.True(t, tree.VerifyProof(leaf, u.Proof))
+
+		h.Publish(bigger)
+		u = <-s.Updates()
+		require.Equal(t, bigger.Root(), u.Root)
+		require.True(t, bigger.VerifyProof(leaf, u.Proof))
+	})
+
+	t.Run("should send proofs against the current tree on subscribe", func(t *testing.T) {
+		h := NewHub()
+		h.Publish(tree)
+		s := h.Subscribe([][]byte{leaf, []byte("unknown")}, 4)
+		defer s.Close()
+
+		u := <-s.Updates()
+		require.Equal(t, leaf, u.Leaf)
+		require.Empty(t, s.Updates())
+	})
+
+	t.Run("should drop the oldest updates of a slow subscriber", func(t *testing.T) {
+		h := NewHub()
+		s := h.Subscribe([][]byte{leaf}, 1)
+		defer s.Close()
+
+		h.Publish(tree)
+		h.Publish(bigger)
+		u := <-s.Updates()
+		require.Equal(t, bigger.Root(), u.Root)
+	})
+
+	t.Run("should close updates on close", func(t *testing.T) {
+		h := NewHub()
+		s := h.Subscribe([][]byte{leaf}, 1)
+		s.Close()
+		s.Close()
+		h.Publish(tree)
+		_, ok := <-s.Updates()
+		require.False(t, ok)
+	})
+}
+
+func Test_ServeHTTP(t *testing.T) {
+	tree, err := merkle.New([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	require.NoError(t, err)
+	leaf := tree.Leaves()[2].Hash
+
+	h := NewHub()
+	h.Publish(tree)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	t.Run("should stream updates as server-sent events", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "?leaf=" + hex.EncodeToString(leaf))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		require.NoError(t, err)
+		var u Update
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "data: ")), &u))
+		require.True(t, tree.VerifyProof(leaf, u.Proof))
+	})
+
+	t.Run("should reject invalid or missing leaf hashes", func(t *testing.T) {
+		for _, query := range []string{"", "?leaf=zz"} {
+			resp, err := http.Get(srv.URL + query)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		}
+	})
+}
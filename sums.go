@@ -0,0 +1,134 @@
+package merkle
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidSums = errors.New("invalid checksum file")
+
+// SumEntry is a line of a coreutils checksum file such as SHA256SUMS
+type SumEntry struct {
+	Name   string
+	Digest []byte
+	Binary bool // the name was marked with '*'
+}
+
+// GoSumEntry is a line of a go.sum file
+type GoSumEntry struct {
+	Module  string
+	Version string // ends in "/go.mod" for the hash of the go.mod file alone
+	Hash    string // such as "h1:..."
+}
+
+// ParseSums parses the output of sha256sum and similar tools, one
+// "<hex digest>  <name>" or "<hex digest> *<name>" line per file, including
+// lines with escaped names that start with a backslash
+func ParseSums(b []byte) ([]SumEntry, error) {
+	var entries []SumEntry
+	for n, line := range sumLines(b) {
+		if line == "" {
+			continue
+		}
+		escaped := strings.HasPrefix(line, `\`)
+		line = strings.TrimPrefix(line, `\`)
+
+		digest, rest, ok := strings.Cut(line, " ")
+		sum, err := hex.DecodeString(digest)
+		if !ok || err != nil || len(sum) == 0 || rest == "" || rest[0] != ' ' && rest[0] != '*' {
+			return nil, fmt.Errorf("%w: line %d", ErrInvalidSums, n+1)
+		}
+		e := SumEntry{Name: rest[1:], Digest: sum, Binary: rest[0] == '*'}
+		if escaped {
+			e.Name = strings.NewReplacer(`\\`, `\`, `\n`, "\n").Replace(e.Name)
+		}
+		if e.Name == "" {
+			return nil, fmt.Errorf("%w: line %d has no file name", ErrInvalidSums, n+1)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// SumLeaf encodes an entry as leaf data: its digest in lowercase hex, two
+// spaces, its name and a newline. For SHA-256 this is the DirhashLeaf of the
+// file, so both kinds of trees commit to the same leaves.
+func SumLeaf(e SumEntry) []byte {
+	return fmt.Appendf(nil, "%x  %s\n", e.Digest, e.Name)
+}
+
+// NewFromSums creates a Merkle tree with a SumLeaf for every entry of a
+// checksum file, in file order, so its files gain inclusion proofs without
+// being hashed again
+func NewFromSums(b []byte, opts ...Option) (*MerkleTree, []SumEntry, error) {
+	entries, err := ParseSums(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := make([][]byte, len(entries))
+	for i, e := range entries {
+		if strings.Contains(e.Name, "\n") {
+			return nil, nil, ErrInvalidFileName
+		}
+		data[i] = SumLeaf(e)
+	}
+	tree, err := New(data, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tree, entries, nil
+}
+
+// ParseGoSum parses a go.sum file, one "<module> <version> <hash>" line per entry
+func ParseGoSum(b []byte) ([]GoSumEntry, error) {
+	var entries []GoSumEntry
+	for n, line := range sumLines(b) {
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 || !strings.Contains(fields[2], ":") {
+			return nil, fmt.Errorf("%w: line %d", ErrInvalidSums, n+1)
+		}
+		entries = append(entries, GoSumEntry{Module: fields[0], Version: fields[1], Hash: fields[2]})
+	}
+	return entries, nil
+}
+
+// GoSumLeaf encodes an entry as leaf data: its go.sum line, with a newline
+func GoSumLeaf(e GoSumEntry) []byte {
+	return fmt.Appendf(nil, "%s %s %s\n", e.Module, e.Version, e.Hash)
+}
+
+// NewFromGoSum creates a Merkle tree with a GoSumLeaf for every entry of a
+// go.sum file, in file order
+func NewFromGoSum(b []byte, opts ...Option) (*MerkleTree, []GoSumEntry, error) {
+	entries, err := ParseGoSum(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := make([][]byte, len(entries))
+	for i, e := range entries {
+		data[i] = GoSumLeaf(e)
+	}
+	tree, err := New(data, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tree, entries, nil
+}
+
+// sumLines splits a checksum file into lines, without their line endings
+func sumLines(b []byte) []string {
+	var lines []string
+	s := bufio.NewScanner(bytes.NewReader(b))
+	s.Buffer(nil, len(b)+1)
+	for s.Scan() {
+		lines = append(lines, strings.TrimSuffix(s.Text(), "\r"))
+	}
+	return lines
+}
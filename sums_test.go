@@ -0,0 +1,93 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Sums(t *testing.T) {
+	t.Run("should parse coreutils checksum lines", func(t *testing.T) {
+		a, b := sha256.Sum256([]byte("a")), sha256.Sum256([]byte("b"))
+		sums := fmt.Sprintf("%x  dist/a.tar.gz\r\n%X *b.bin\n\n\\%x  weird\\nname\\\\x\n", a, b, a)
+
+		entries, err := ParseSums([]byte(sums))
+		require.NoError(t, err)
+		require.Equal(t, []SumEntry{
+			{Name: "dist/a.tar.gz", Digest: a[:]},
+			{Name: "b.bin", Digest: b[:], Binary: true},
+			{Name: "weird\nname\\x", Digest: a[:]},
+		}, entries)
+	})
+
+	t.Run("should reject malformed lines", func(t *testing.T) {
+		for _, sums := range []string{"abcd", "zz  file", "abcd file", "abcd  ", "  file"} {
+			_, err := ParseSums([]byte(sums))
+			require.ErrorIs(t, err, ErrInvalidSums, sums)
+		}
+	})
+
+	t.Run("should build the same tree as the files' dirhash leaves", func(t *testing.T) {
+		dir := t.TempDir()
+		var sums string
+		for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+			content := []byte("content of " + name)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), content, 0o644))
+			sums += fmt.Sprintf("%x  %s\n", sha256.Sum256(content), name)
+		}
+
+		tree, entries, err := NewFromSums([]byte(sums))
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		fromDir, _, err := NewFromDir(dir, "")
+		require.NoError(t, err)
+		require.Equal(t, fromDir.Root(), tree.Root())
+
+		content := []byte("content of b.txt")
+		leaf, err := DirhashLeaf("b.txt", content)
+		require.NoError(t, err)
+		proof, err := tree.GenerateProofAt(1)
+		require.NoError(t, err)
+		require.True(t, tree.VerifyData(leaf, proof))
+	})
+
+	t.Run("should reject escaped names with newlines", func(t *testing.T) {
+		_, _, err := NewFromSums([]byte(fmt.Sprintf("\\%x  a\\nb\n", sha256.Sum256(nil))))
+		require.ErrorIs(t, err, ErrInvalidFileName)
+	})
+
+	t.Run("should fail for empty files", func(t *testing.T) {
+		_, _, err := NewFromSums(nil)
+		require.ErrorIs(t, err, ErrEmptyData)
+	})
+}
+
+func Test_GoSum(t *testing.T) {
+	const goSum = `github.com/stretchr/testify v1.9.0 h1:HtqpIVDClZ4nwg75+f6Lvsy/wHu+3BoSGCbBAcpTsTg=
+github.com/stretchr/testify v1.9.0/go.mod h1:r2ic/lqez/lEtzL7wO/rwa5dbSLXVDPFyf8C91i36aY=
+`
+
+	t.Run("should parse go.sum lines", func(t *testing.T) {
+		entries, err := ParseGoSum([]byte(goSum))
+		require.NoError(t, err)
+		require.Equal(t, GoSumEntry{Module: "github.com/stretchr/testify", Version: "v1.9.0/go.mod", Hash: "h1:r2ic/lqez/lEtzL7wO/rwa5dbSLXVDPFyf8C91i36aY="}, entries[1])
+
+		_, err = ParseGoSum([]byte("github.com/x v1.0.0\n"))
+		require.ErrorIs(t, err, ErrInvalidSums)
+	})
+
+	t.Run("should prove each go.sum line", func(t *testing.T) {
+		tree, entries, err := NewFromGoSum([]byte(goSum))
+		require.NoError(t, err)
+		for i, e := range entries {
+			proof, err := tree.GenerateProofAt(i)
+			require.NoError(t, err)
+			require.True(t, tree.VerifyData(GoSumLeaf(e), proof))
+		}
+		require.Equal(t, goSum[:len(GoSumLeaf(entries[0]))], string(GoSumLeaf(entries[0])))
+	})
+}
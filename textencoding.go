@@ -0,0 +1,145 @@
+package merkle
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var ErrUnknownTextEncoding = errors.New("unknown text encoding")
+
+// TextEncoding renders hashes as text; base64.Encoding and base32.Encoding
+// satisfy it
+type TextEncoding interface {
+	EncodeToString(b []byte) string
+	DecodeString(s string) ([]byte, error)
+}
+
+var (
+	// Hex is lowercase hexadecimal, the default
+	Hex TextEncoding = hexEncoding{}
+	// Base64URL is unpadded URL-safe base64
+	Base64URL TextEncoding = base64.RawURLEncoding
+	// Base32 is unpadded RFC 4648 base32
+	Base32 TextEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+	// Base58 is base58 with the Bitcoin alphabet
+	Base58 TextEncoding = base58Encoding{}
+)
+
+// textEncodings are the built-in encodings by name
+var textEncodings = map[string]TextEncoding{
+	"hex":       Hex,
+	"base64url": Base64URL,
+	"base32":    Base32,
+	"base58":    Base58,
+}
+
+// TextEncodingByName returns the built-in encoding named hex, base64url,
+// base32 or base58, or Hex for the empty name
+func TextEncodingByName(name string) (TextEncoding, error) {
+	if name == "" {
+		return Hex, nil
+	}
+	e, ok := textEncodings[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTextEncoding, name)
+	}
+	return e, nil
+}
+
+// WithTextEncoding sets the encoding of the hashes in the tree's textual
+// output, such as Explain and TextProof
+func WithTextEncoding(e TextEncoding) Option {
+	return func(m *MerkleTree) {
+		m.textEncoding = e
+	}
+}
+
+// TextEncoding returns the encoding of the tree's textual output
+func (m *MerkleTree) TextEncoding() TextEncoding {
+	if m.textEncoding == nil {
+		return Hex
+	}
+	return m.textEncoding
+}
+
+// TextElement is a proof element with its hash in a text encoding
+type TextElement struct {
+	Hash string `json:"hash"`
+	Side Side   `json:"side"`
+}
+
+// Text returns the proof with its hashes in the given encoding, for JSON
+func (p Proof) Text(e TextEncoding) []TextElement {
+	elements := make([]TextElement, len(p))
+	for i, pe := range p {
+		elements[i] = TextElement{Hash: e.EncodeToString(pe.Hash), Side: pe.Side}
+	}
+	return elements
+}
+
+// ParseTextProof decodes a proof whose hashes are in the given encoding
+func ParseTextProof(elements []TextElement, e TextEncoding) (Proof, error) {
+	p := make(Proof, len(elements))
+	for i, te := range elements {
+		hash, err := e.DecodeString(te.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("proof element %d: %w", i, err)
+		}
+		p[i] = ProofElement{Hash: hash, Side: te.Side}
+	}
+	return p, nil
+}
+
+// hexEncoding adapts encoding/hex to TextEncoding
+type hexEncoding struct{}
+
+func (hexEncoding) EncodeToString(b []byte) string { return hex.EncodeToString(b) }
+
+func (hexEncoding) DecodeString(s string) ([]byte, error) { return hex.DecodeString(s) }
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Encoding encodes bytes as a base58 number, with a leading '1' for
+// every leading zero byte
+type base58Encoding struct{}
+
+func (base58Encoding) EncodeToString(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+	var digits []byte
+	n, radix, mod := new(big.Int).SetBytes(b), big.NewInt(58), new(big.Int)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		digits = append(digits, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		digits = append(digits, '1')
+	}
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return string(digits)
+}
+
+func (base58Encoding) DecodeString(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	n, radix := new(big.Int), big.NewInt(58)
+	for i := zeros; i < len(s); i++ {
+		d := strings.IndexByte(base58Alphabet, s[i])
+		if d < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q at %d", s[i], i)
+		}
+		n.Mul(n, radix).Add(n, big.NewInt(int64(d)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
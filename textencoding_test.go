@@ -0,0 +1,62 @@
+package merkle
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TextEncoding(t *testing.T) {
+	t.Run("should round trip bytes in every encoding", func(t *testing.T) {
+		for _, name := range []string{"hex", "base64url", "base32", "base58"} {
+			e, err := TextEncodingByName(name)
+			require.NoError(t, err)
+			for _, b := range [][]byte{{}, {0}, {0, 0, 1}, []byte("hash(a)"), {0xff, 0xfe}} {
+				decoded, err := e.DecodeString(e.EncodeToString(b))
+				require.NoError(t, err, name)
+				require.Equal(t, b, decoded, name)
+			}
+		}
+	})
+
+	t.Run("should match known base58 vectors", func(t *testing.T) {
+		require.Equal(t, "2NEpo7TZRRrLZSi2U", Base58.EncodeToString([]byte("Hello World!")))
+		require.Equal(t, "11233QC4", Base58.EncodeToString([]byte{0, 0, 0x28, 0x7f, 0xb4, 0xcd}))
+		_, err := Base58.DecodeString("0OIl")
+		require.Error(t, err)
+	})
+
+	t.Run("should default to hex", func(t *testing.T) {
+		e, err := TextEncodingByName("")
+		require.NoError(t, err)
+		require.Equal(t, Hex, e)
+		_, err = TextEncodingByName("base62")
+		require.ErrorIs(t, err, ErrUnknownTextEncoding)
+
+		tree, err := New([][]byte{[]byte("a")})
+		require.NoError(t, err)
+		require.Equal(t, Hex, tree.TextEncoding())
+		tree, err = New([][]byte{[]byte("a")}, WithTextEncoding(Base58))
+		require.NoError(t, err)
+		require.Equal(t, Base58, tree.TextEncoding())
+	})
+
+	t.Run("should round trip text proofs through JSON", func(t *testing.T) {
+		tree, err := New([][]byte{[]byte("a"), []byte("b"), []byte("c")}, WithHashFunction(mockHash))
+		require.NoError(t, err)
+		proof, err := tree.GenerateProofAt(0)
+		require.NoError(t, err)
+
+		b, err := json.Marshal(proof.Text(Base32))
+		require.NoError(t, err)
+		var elements []TextElement
+		require.NoError(t, json.Unmarshal(b, &elements))
+		parsed, err := ParseTextProof(elements, Base32)
+		require.NoError(t, err)
+		require.Equal(t, proof, parsed)
+
+		_, err = ParseTextProof([]TextElement{{Hash: "!", Side: Left}}, Base32)
+		require.Error(t, err)
+	})
+}
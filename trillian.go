@@ -0,0 +1,85 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"hash"
+)
+
+// WithRFC6962 selects the RFC 6962 hashing strategy used by Certificate
+// Transparency and Trillian logs: SHA-256 with 0x00/0x01 leaf and node
+// prefixes, and unpaired nodes promoted as the largest-power-of-two split does
+func WithRFC6962() Option {
+	return func(m *MerkleTree) {
+		m.hashFn = sha256.New
+		m.promoteOdd = true
+		m.leafPrefix = []byte{0x00}
+		m.nodePrefix = []byte{0x01}
+	}
+}
+
+var (
+	coniksLeafIdentifier  = []byte("L")
+	coniksEmptyIdentifier = []byte("E")
+)
+
+// ConiksHasher implements the CONIKS sparse map hashing strategy used by
+// Trillian maps, where leaves and empty subtrees commit to their tree ID and position
+type ConiksHasher struct {
+	hashFn func() hash.Hash
+}
+
+// NewConiksHasher creates a CONIKS hasher using the given hash function
+func NewConiksHasher(h func() hash.Hash) *ConiksHasher {
+	return &ConiksHasher{hashFn: h}
+}
+
+// DefaultConiksHasher is the CONIKS hasher over SHA-512/256 that Trillian uses by default
+var DefaultConiksHasher = NewConiksHasher(sha512.New512_256)
+
+// HashLeaf computes H("L" || treeID || index || depth || leaf) for the leaf at
+// the first depth bits of index
+func (c *ConiksHasher) HashLeaf(treeID int64, index []byte, depth int, leaf []byte) ([]byte, error) {
+	return c.hashPosition(coniksLeafIdentifier, treeID, index, depth, leaf)
+}
+
+// HashEmpty computes H("E" || treeID || index || depth) for the empty subtree
+// rooted at the first depth bits of index
+func (c *ConiksHasher) HashEmpty(treeID int64, index []byte, depth int) ([]byte, error) {
+	return c.hashPosition(coniksEmptyIdentifier, treeID, index, depth, nil)
+}
+
+// HashChildren computes H(left || right) for an interior map node
+func (c *ConiksHasher) HashChildren(left, right []byte) []byte {
+	h := c.hashFn()
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// hashPosition hashes an identifier, tree ID and masked node position, followed by an optional value
+func (c *ConiksHasher) hashPosition(identifier []byte, treeID int64, index []byte, depth int, value []byte) ([]byte, error) {
+	h := c.hashFn()
+	if depth < 0 || depth > h.Size()*8 || depth > len(index)*8 {
+		return nil, ErrOutOfRange
+	}
+
+	// the node ID is the index truncated to depth bits, padded with zeroes to the hash size
+	id := make([]byte, h.Size())
+	n := copy(id, index[:(depth+7)/8])
+	if bits := depth % 8; bits != 0 {
+		id[n-1] &= 0xff << (8 - bits)
+	}
+
+	var buf [12]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(treeID))
+	binary.BigEndian.PutUint32(buf[8:], uint32(depth))
+
+	h.Write(identifier)
+	h.Write(buf[:8])
+	h.Write(id)
+	h.Write(buf[8:])
+	h.Write(value)
+	return h.Sum(nil), nil
+}
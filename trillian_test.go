@@ -0,0 +1,84 @@
+package merkle
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithRFC6962(t *testing.T) {
+	data := [][]byte{
+		{},
+		{0x00},
+		{0x10},
+		{0x20, 0x21},
+		{0x30, 0x31},
+		{0x40, 0x41, 0x42, 0x43},
+		{0x50, 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57},
+		{0x60, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69, 0x6a, 0x6b, 0x6c, 0x6d, 0x6e, 0x6f},
+	}
+	roots := []string{
+		"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+		"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+		"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+		"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+	}
+
+	t.Run("should match the RFC 6962 reference roots", func(t *testing.T) {
+		for n := 1; n <= len(data); n++ {
+			tree, err := New(data[:n], WithRFC6962())
+			require.NoError(t, err)
+			require.Equal(t, roots[n-1], hex.EncodeToString(tree.Root()))
+		}
+	})
+
+	t.Run("should verify proofs for every leaf", func(t *testing.T) {
+		tree, err := New(data, WithRFC6962())
+		require.NoError(t, err)
+		for _, item := range data {
+			proof, err := tree.GenerateProof(item)
+			require.NoError(t, err)
+			require.True(t, tree.VerifyData(item, proof))
+		}
+	})
+}
+
+func Test_ConiksHasher(t *testing.T) {
+	index := []byte{0xff, 0xff}
+
+	t.Run("should ignore index bits beyond depth", func(t *testing.T) {
+		a, err := DefaultConiksHasher.HashLeaf(1, index, 12, []byte("v"))
+		require.NoError(t, err)
+		b, err := DefaultConiksHasher.HashLeaf(1, []byte{0xff, 0xf0}, 12, []byte("v"))
+		require.NoError(t, err)
+		require.Equal(t, a, b)
+		require.Len(t, a, sha512.Size256)
+	})
+
+	t.Run("should separate leaves, empty subtrees and tree IDs", func(t *testing.T) {
+		leaf, err := DefaultConiksHasher.HashLeaf(1, index, 16, nil)
+		require.NoError(t, err)
+		empty, err := DefaultConiksHasher.HashEmpty(1, index, 16)
+		require.NoError(t, err)
+		other, err := DefaultConiksHasher.HashEmpty(2, index, 16)
+		require.NoError(t, err)
+		require.NotEqual(t, leaf, empty)
+		require.NotEqual(t, empty, other)
+	})
+
+	t.Run("should hash children without a prefix", func(t *testing.T) {
+		hasher := NewConiksHasher(mockHash)
+		require.Equal(t, "hash(lr)", string(hasher.HashChildren([]byte("l"), []byte("r"))))
+	})
+
+	t.Run("should return error for depth beyond the index", func(t *testing.T) {
+		_, err := DefaultConiksHasher.HashEmpty(1, index, 17)
+		require.ErrorIs(t, err, ErrOutOfRange)
+	})
+}
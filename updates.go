@@ -0,0 +1,191 @@
+package merkle
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"slices"
+)
+
+var ErrUnpredictableRoot = errors.New("the root of a blinded tree depends on the blinds drawn when updating")
+
+// LeafUpdate replaces the data of the leaf at Index
+type LeafUpdate struct {
+	Index int
+	Data  []byte
+}
+
+// ApplyUpdates replaces the data of several leaves at once. Updates are sorted
+// by index and every affected interior node is rehashed once, so updates in
+// the same subtree share the recomputation of their common ancestors; the
+// last update of an index wins. Trees with sorted leaves are rebuilt instead,
+// since updates may reorder them. No update is applied if any is invalid.
+func (m *MerkleTree) ApplyUpdates(updates []LeafUpdate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flushPending()
+
+	if err := m.checkUpdates(updates); err != nil {
+		return err
+	}
+
+	sorted := slices.Clone(updates)
+	slices.SortStableFunc(sorted, func(a, b LeafUpdate) int { return cmp.Compare(a.Index, b.Index) })
+
+	leaves := make([]*Node, len(sorted))
+	for i, u := range sorted {
+		leaf, err := m.newLeaf(u.Data)
+		if err != nil {
+			return err
+		}
+		leaves[i] = leaf
+	}
+
+	dirty := make([]int, 0, len(sorted))
+	for i, u := range sorted {
+		node, updated := m.leafs[u.Index], leaves[i]
+		if m.zeroize {
+			clear(node.data)
+			clear(node.blind)
+		}
+		node.data, node.hash, node.blind = updated.data, updated.hash, updated.blind
+		if len(dirty) == 0 || dirty[len(dirty)-1] != u.Index {
+			dirty = append(dirty, u.Index)
+		}
+	}
+
+	if m.sortLeaves {
+		m.rebuild()
+		return nil
+	}
+	m.rehash(dirty)
+	m.commit()
+	return nil
+}
+
+// rehash recomputes, level by level, the ancestors of the leaves at the given
+// sorted indices, each of them once
+func (m *MerkleTree) rehash(dirty []int) {
+	for k, level := range m.levels[:len(m.levels)-1] {
+		n := 0
+		for _, i := range dirty {
+			j := i >> 1
+			if n > 0 && dirty[n-1] == j {
+				continue
+			}
+			dirty[n] = j
+			n++
+
+			// a promoted node is its own parent, already up to date
+			if 2*j+1 >= len(level) && m.promoteOdd {
+				continue
+			}
+			parent := m.levels[k+1][j]
+			parent.hash = m.hashNode(parent.left.hash, parent.right.hash)
+		}
+		dirty = dirty[:n]
+	}
+}
+
+// PreviewRoot returns the root that ApplyUpdates would produce for the
+// updates, without changing the tree, so the new root can be validated or
+// signed off before it is published. It fails as ApplyUpdates would, and with
+// ErrUnpredictableRoot for blinded trees.
+func (m *MerkleTree) PreviewRoot(updates []LeafUpdate) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flushPending()
+
+	if err := m.checkUpdates(updates); err != nil {
+		return nil, err
+	}
+	if m.blinding && len(updates) > 0 {
+		return nil, ErrUnpredictableRoot
+	}
+
+	// later updates of an index win, as in ApplyUpdates
+	changed := make(map[int][]byte, len(updates))
+	for _, u := range updates {
+		hash, err := m.hashData(u.Data)
+		if err != nil {
+			return nil, err
+		}
+		changed[u.Index] = hash
+	}
+
+	if m.sortLeaves {
+		hashes := make([][]byte, len(m.leafs))
+		for i, leaf := range m.leafs {
+			hashes[i] = leaf.hash
+			if hash, ok := changed[i]; ok {
+				hashes[i] = hash
+			}
+		}
+		slices.SortStableFunc(hashes, bytes.Compare)
+		return m.rootOfHashes(hashes), nil
+	}
+
+	for _, level := range m.levels[:len(m.levels)-1] {
+		parents := make(map[int][]byte, len(changed))
+		for i := range changed {
+			j := i >> 1
+			if _, ok := parents[j]; ok {
+				continue
+			}
+			left, right := m.previewHash(level, changed, 2*j), []byte(nil)
+			switch {
+			case 2*j+1 < len(level):
+				right = m.previewHash(level, changed, 2*j+1)
+			case m.promoteOdd:
+				parents[j] = left
+				continue
+			default:
+				right = left
+			}
+			parents[j] = m.hashNode(left, right)
+		}
+		changed = parents
+	}
+	if root, ok := changed[0]; ok {
+		return root, nil
+	}
+	return m.root.hash, nil
+}
+
+// checkUpdates checks the indices of updates before any is applied
+func (m *MerkleTree) checkUpdates(updates []LeafUpdate) error {
+	for _, u := range updates {
+		if u.Index < 0 || u.Index >= len(m.leafs) {
+			return ErrOutOfRange
+		}
+	}
+	return nil
+}
+
+// previewHash returns the hash of the node at index i of a level, as changed
+// by a preview
+func (m *MerkleTree) previewHash(level []*Node, changed map[int][]byte, i int) []byte {
+	if hash, ok := changed[i]; ok {
+		return hash
+	}
+	return level[i].hash
+}
+
+// rootOfHashes computes the root of a tree over the given leaf hashes
+func (m *MerkleTree) rootOfHashes(hashes [][]byte) []byte {
+	for len(hashes) > 1 {
+		parents := make([][]byte, 0, (len(hashes)+1)/2)
+		for i := 0; i < len(hashes); i += 2 {
+			switch {
+			case i+1 < len(hashes):
+				parents = append(parents, m.hashNode(hashes[i], hashes[i+1]))
+			case m.promoteOdd:
+				parents = append(parents, hashes[i])
+			default:
+				parents = append(parents, m.hashNode(hashes[i], hashes[i]))
+			}
+		}
+		hashes = parents
+	}
+	return hashes[0]
+}
@@ -0,0 +1,132 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ApplyUpdates(t *testing.T) {
+	var data [][]byte
+	for i := 0; i < 11; i++ {
+		data = append(data, []byte(fmt.Sprint(i)))
+	}
+	updates := []LeafUpdate{
+		{Index: 9, Data: []byte("x")},
+		{Index: 0, Data: []byte("y")},
+		{Index: 10, Data: []byte("z")},
+		{Index: 1, Data: []byte("w")},
+		{Index: 0, Data: []byte("v")},
+	}
+
+	for name, opts := range map[string][]Option{
+		"duplicated": nil,
+		"promoted":   {WithOddNodePromotion()},
+		"sorted":     {WithSortedLeaves()},
+	} {
+		t.Run("should match a rebuilt tree for "+name+" trees", func(t *testing.T) {
+			tree, err := New(data, opts...)
+			require.NoError(t, err)
+			// indices are positions in the tree, after sorting if leaves are sorted
+			var updated [][]byte
+			for _, leaf := range tree.Leaves() {
+				updated = append(updated, leaf.Data)
+			}
+			updated[0], updated[1], updated[9], updated[10] = []byte("v"), []byte("w"), []byte("x"), []byte("z")
+			require.NoError(t, tree.ApplyUpdates(updates))
+
+			expected, err := New(updated, opts...)
+			require.NoError(t, err)
+			require.Equal(t, expected.Root(), tree.Root())
+			require.NoError(t, CheckInvariants(tree))
+		})
+	}
+
+	t.Run("should rehash shared ancestors once", func(t *testing.T) {
+		var calls int
+		counting := func() hash.Hash {
+			calls++
+			return sha256.New()
+		}
+		tree, err := New(data[:8], WithHashFunction(counting))
+		require.NoError(t, err)
+
+		calls = 0
+		require.NoError(t, tree.ApplyUpdates([]LeafUpdate{{Index: 1, Data: []byte("a")}, {Index: 0, Data: []byte("b")}}))
+		require.Equal(t, 2+3, calls)
+	})
+
+	t.Run("should apply nothing if an update is invalid", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		root := tree.Root()
+		require.ErrorIs(t, tree.ApplyUpdates([]LeafUpdate{{Index: 0, Data: []byte("a")}, {Index: 11}}), ErrOutOfRange)
+		require.Equal(t, root, tree.Root())
+	})
+}
+
+func Test_PreviewRoot(t *testing.T) {
+	var data [][]byte
+	for i := 0; i < 11; i++ {
+		data = append(data, []byte(fmt.Sprint(i)))
+	}
+	updates := []LeafUpdate{
+		{Index: 9, Data: []byte("x")},
+		{Index: 0, Data: []byte("y")},
+		{Index: 10, Data: []byte("z")},
+		{Index: 0, Data: []byte("v")},
+	}
+
+	for name, opts := range map[string][]Option{
+		"duplicated": nil,
+		"promoted":   {WithOddNodePromotion()},
+		"sorted":     {WithSortedLeaves()},
+		"rfc6962":    {WithScheme(SchemeRFC6962)},
+	} {
+		t.Run("should return the root ApplyUpdates commits for "+name+" trees", func(t *testing.T) {
+			for n := 1; n <= len(data); n++ {
+				tree, err := New(data[:n], opts...)
+				require.NoError(t, err)
+				root := tree.Root()
+
+				var batch []LeafUpdate
+				for _, u := range updates {
+					if u.Index < n {
+						batch = append(batch, u)
+					}
+				}
+				preview, err := tree.PreviewRoot(batch)
+				require.NoError(t, err)
+				require.Equal(t, root, tree.Root())
+
+				require.NoError(t, tree.ApplyUpdates(batch))
+				require.Equal(t, tree.Root(), preview, "n=%d", n)
+			}
+		})
+	}
+
+	t.Run("should return the current root for no updates", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		preview, err := tree.PreviewRoot(nil)
+		require.NoError(t, err)
+		require.Equal(t, tree.Root(), preview)
+	})
+
+	t.Run("should fail as ApplyUpdates would", func(t *testing.T) {
+		tree, err := New(data)
+		require.NoError(t, err)
+		_, err = tree.PreviewRoot([]LeafUpdate{{Index: 11, Data: []byte("x")}})
+		require.ErrorIs(t, err, ErrOutOfRange)
+	})
+
+	t.Run("should refuse to predict blinded roots", func(t *testing.T) {
+		tree, err := New(data, WithBlinding())
+		require.NoError(t, err)
+		_, err = tree.PreviewRoot([]LeafUpdate{{Index: 0, Data: []byte("x")}})
+		require.ErrorIs(t, err, ErrUnpredictableRoot)
+	})
+}
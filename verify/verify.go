@@ -0,0 +1,105 @@
+// Package verify is a dependency-free inclusion proof verifier for constrained
+// targets such as TinyGo on microcontrollers. It does not import the merkle
+// package, and a Verifier reuses its buffers so verification does not allocate.
+package verify
+
+import (
+	"bytes"
+	"hash"
+)
+
+// Side is the side of a sibling, matching merkle.Left and merkle.Right
+type Side int8
+
+const (
+	Left Side = iota
+	Right
+)
+
+// maxHashSize bounds the hash size, so the buffers can live in the Verifier
+const maxHashSize = 64
+
+// Verifier recomputes roots from proofs with the hashing configuration of the
+// tree. It is not safe for concurrent use.
+type Verifier struct {
+	h          hash.Hash
+	leafPrefix []byte
+	nodePrefix []byte
+	promoteOdd bool
+	buf        [maxHashSize]byte
+}
+
+// New creates a verifier using h, the leaf and node prefixes of the tree (nil
+// for none) and whether the tree promotes odd nodes
+func New(h hash.Hash, leafPrefix, nodePrefix []byte, promoteOdd bool) *Verifier {
+	return &Verifier{h: h, leafPrefix: leafPrefix, nodePrefix: nodePrefix, promoteOdd: promoteOdd}
+}
+
+// Verify verifies a proof for data against root, given the sibling hashes from
+// the leaf up and their sides
+func (v *Verifier) Verify(root, data []byte, siblings [][]byte, sides []Side) bool {
+	if len(siblings) != len(sides) || v.h.Size() > maxHashSize {
+		return false
+	}
+
+	cur := v.leaf(data)
+	for i, sibling := range siblings {
+		switch sides[i] {
+		case Left:
+			cur = v.node(sibling, cur)
+		case Right:
+			cur = v.node(cur, sibling)
+		default:
+			return false
+		}
+	}
+	return bytes.Equal(cur, root)
+}
+
+// VerifyAt verifies a proof for the data of the leaf at index in a tree of
+// leafCount leaves against root. The sibling hashes are concatenated from the
+// leaf up and their sides are derived from the leaf position, so a proof fits
+// in a single buffer.
+func (v *Verifier) VerifyAt(root, data, siblings []byte, index, leafCount int) bool {
+	size := v.h.Size()
+	if index < 0 || index >= leafCount || size > maxHashSize || len(siblings)%size != 0 {
+		return false
+	}
+
+	cur := v.leaf(data)
+	for width := leafCount; width > 1; width = (width + 1) / 2 {
+		paired := index%2 == 1 || index+1 < width || !v.promoteOdd
+		if paired {
+			if len(siblings) == 0 {
+				return false
+			}
+			sibling := siblings[:size]
+			siblings = siblings[size:]
+			if index%2 == 1 {
+				cur = v.node(sibling, cur)
+			} else {
+				cur = v.node(cur, sibling)
+			}
+		}
+		index /= 2
+	}
+	return len(siblings) == 0 && bytes.Equal(cur, root)
+}
+
+// leaf hashes a leaf into the verifier's buffer
+func (v *Verifier) leaf(data []byte) []byte {
+	v.h.Reset()
+	v.h.Write(v.leafPrefix)
+	v.h.Write(data)
+	return v.h.Sum(v.buf[:0])
+}
+
+// node hashes an interior node into the verifier's buffer; either child may
+// alias the buffer, since both are written before the sum
+func (v *Verifier) node(left, right []byte) []byte {
+	v.h.Reset()
+	v.h.Write(v.nodePrefix)
+	v.h.Write(left)
+	v.h.Write(right)
+	return v.h.Sum(v.buf[:0])
+}
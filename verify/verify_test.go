@@ -0,0 +1,79 @@
+package verify
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Verifier(t *testing.T) {
+	var data [][]byte
+	for i := 0; i < 11; i++ {
+		data = append(data, []byte(fmt.Sprint(i)))
+	}
+
+	modes := map[string]struct {
+		opts     []merkle.Option
+		verifier *Verifier
+	}{
+		"default":  {nil, New(sha256.New(), nil, nil, false)},
+		"promoted": {[]merkle.Option{merkle.WithOddNodePromotion()}, New(sha256.New(), nil, nil, true)},
+		"rfc6962":  {[]merkle.Option{merkle.WithRFC6962()}, New(sha256.New(), []byte{0x00}, []byte{0x01}, true)},
+	}
+
+	for name, mode := range modes {
+		tree, err := merkle.New(data, mode.opts...)
+		require.NoError(t, err)
+		v := mode.verifier
+
+		t.Run("should verify proofs of the merkle package in mode "+name, func(t *testing.T) {
+			for i, leaf := range data {
+				proof, err := tree.GenerateProofAt(i)
+				require.NoError(t, err)
+
+				siblings := make([][]byte, len(proof))
+				sides := make([]Side, len(proof))
+				for j, pe := range proof {
+					siblings[j], sides[j] = pe.Hash, Side(pe.Side)
+				}
+				require.True(t, v.Verify(tree.Root(), leaf, siblings, sides))
+				require.False(t, v.Verify(tree.Root(), []byte("x"), siblings, sides))
+
+				require.True(t, v.VerifyAt(tree.Root(), leaf, proof.RsMerkleBytes(), i, len(data)))
+				require.False(t, v.VerifyAt(tree.Root(), leaf, proof.RsMerkleBytes(), (i+1)%len(data), len(data)))
+			}
+		})
+	}
+
+	t.Run("should reject malformed proofs", func(t *testing.T) {
+		tree, err := merkle.New(data)
+		require.NoError(t, err)
+		proof, err := tree.GenerateProofAt(3)
+		require.NoError(t, err)
+		b := proof.RsMerkleBytes()
+
+		v := New(sha256.New(), nil, nil, false)
+		require.False(t, v.VerifyAt(tree.Root(), data[3], b[:len(b)-1], 3, len(data)))
+		require.False(t, v.VerifyAt(tree.Root(), data[3], b[:len(b)-sha256.Size], 3, len(data)))
+		require.False(t, v.VerifyAt(tree.Root(), data[3], append(b, b[:sha256.Size]...), 3, len(data)))
+		require.False(t, v.VerifyAt(tree.Root(), data[3], b, 11, len(data)))
+		require.False(t, v.Verify(tree.Root(), data[3], [][]byte{b}, []Side{Side(2)}))
+	})
+
+	t.Run("should not allocate", func(t *testing.T) {
+		tree, err := merkle.New(data)
+		require.NoError(t, err)
+		proof, err := tree.GenerateProofAt(5)
+		require.NoError(t, err)
+		root, b := tree.Root(), proof.RsMerkleBytes()
+
+		v := New(sha256.New(), nil, nil, false)
+		allocs := testing.AllocsPerRun(100, func() {
+			v.VerifyAt(root, data[5], b, 5, len(data))
+		})
+		require.Zero(t, allocs)
+	})
+}
@@ -0,0 +1,46 @@
+package merkle
+
+// TraversalOrder selects the order in which Walk visits nodes
+type TraversalOrder int
+
+const (
+	DepthFirst TraversalOrder = iota
+	BreadthFirst
+)
+
+// Walk visits every node position of the tree in the given order, calling fn
+// with the node's level (0 for leaves), its index within the level and its
+// hash. Walking stops as soon as fn returns false.
+func (m *MerkleTree) Walk(order TraversalOrder, fn func(level, index int, hash []byte, isLeaf bool) bool) {
+	if order == BreadthFirst {
+		for level := len(m.levels) - 1; level >= 0; level-- {
+			for index, node := range m.levels[level] {
+				if !fn(level, index, node.hash, level == 0) {
+					return
+				}
+			}
+		}
+		return
+	}
+
+	m.walkDepthFirst(len(m.levels)-1, 0, fn)
+}
+
+// walkDepthFirst visits a node and then its children in pre-order, reporting
+// whether walking should continue
+func (m *MerkleTree) walkDepthFirst(level, index int, fn func(level, index int, hash []byte, isLeaf bool) bool) bool {
+	if !fn(level, index, m.levels[level][index].hash, level == 0) {
+		return false
+	}
+	if level == 0 {
+		return true
+	}
+
+	if !m.walkDepthFirst(level-1, index*2, fn) {
+		return false
+	}
+	if index*2+1 < len(m.levels[level-1]) {
+		return m.walkDepthFirst(level-1, index*2+1, fn)
+	}
+	return true
+}
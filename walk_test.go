@@ -0,0 +1,62 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Walk(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	tree, err := New(data, WithHashFunction(mockHash))
+	require.NoError(t, err)
+
+	collect := func(order TraversalOrder, limit int) []string {
+		var visited []string
+		tree.Walk(order, func(level, index int, hash []byte, isLeaf bool) bool {
+			visited = append(visited, fmt.Sprintf("%d/%d %s %t", level, index, hash, isLeaf))
+			return len(visited) < limit
+		})
+		return visited
+	}
+
+	t.Run("should walk depth first", func(t *testing.T) {
+		require.Equal(t, []string{
+			"2/0 hash(hash(hash(a)hash(b))hash(hash(c)hash(c))) false",
+			"1/0 hash(hash(a)hash(b)) false",
+			"0/0 hash(a) true",
+			"0/1 hash(b) true",
+			"1/1 hash(hash(c)hash(c)) false",
+			"0/2 hash(c) true",
+		}, collect(DepthFirst, 100))
+	})
+
+	t.Run("should walk breadth first", func(t *testing.T) {
+		require.Equal(t, []string{
+			"2/0 hash(hash(hash(a)hash(b))hash(hash(c)hash(c))) false",
+			"1/0 hash(hash(a)hash(b)) false",
+			"1/1 hash(hash(c)hash(c)) false",
+			"0/0 hash(a) true",
+			"0/1 hash(b) true",
+			"0/2 hash(c) true",
+		}, collect(BreadthFirst, 100))
+	})
+
+	t.Run("should stop when the visitor returns false", func(t *testing.T) {
+		require.Len(t, collect(DepthFirst, 3), 3)
+		require.Len(t, collect(BreadthFirst, 2), 2)
+	})
+
+	t.Run("should reflect added leaves", func(t *testing.T) {
+		tree.AddLeaf([]byte("d"))
+		leaves := 0
+		tree.Walk(DepthFirst, func(level, index int, hash []byte, isLeaf bool) bool {
+			if isLeaf {
+				leaves++
+			}
+			return true
+		})
+		require.Equal(t, 4, leaves)
+	})
+}
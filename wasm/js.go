@@ -0,0 +1,64 @@
+//go:build js && wasm
+
+package wasm
+
+import "syscall/js"
+
+// Register exposes merkleRoot, merkleProve and merkleVerify as JS globals.
+// They take and return strings, and return an Error on failure:
+//
+//	merkleRoot(leaves: string[], scheme?: string, encoding?: string): string
+//	merkleProve(leaves: string[], index: number, scheme?: string, encoding?: string): string
+//	merkleVerify(root: string, leaf: string, proof: string, scheme?: string, encoding?: string): boolean
+func Register() {
+	js.Global().Set("merkleRoot", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) < 1 {
+			return jsError("merkleRoot: expected leaves")
+		}
+		return result(Root(strings(args[0]), optional(args, 1), optional(args, 2)))
+	}))
+
+	js.Global().Set("merkleProve", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) < 2 {
+			return jsError("merkleProve: expected leaves and an index")
+		}
+		return result(Prove(strings(args[0]), args[1].Int(), optional(args, 2), optional(args, 3)))
+	}))
+
+	js.Global().Set("merkleVerify", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) < 3 {
+			return jsError("merkleVerify: expected a root, leaf and proof")
+		}
+		return result(Verify(args[0].String(), args[1].String(), args[2].String(), optional(args, 3), optional(args, 4)))
+	}))
+}
+
+// result returns the value, or a JS Error if err is set
+func result[T any](v T, err error) any {
+	if err != nil {
+		return jsError(err.Error())
+	}
+	return v
+}
+
+// jsError creates a JS Error with the given message
+func jsError(msg string) js.Value {
+	return js.Global().Get("Error").New(msg)
+}
+
+// strings converts a JS array of strings
+func strings(v js.Value) []string {
+	s := make([]string, v.Length())
+	for i := range s {
+		s[i] = v.Index(i).String()
+	}
+	return s
+}
+
+// optional returns the string argument at i, or "" if it is not given
+func optional(args []js.Value, i int) string {
+	if i >= len(args) || args[i].IsUndefined() || args[i].IsNull() {
+		return ""
+	}
+	return args[i].String()
+}
@@ -0,0 +1,119 @@
+// Package wasm wraps building and verifying proofs in string in/out calls, so
+// the JS bindings registered by Register can hand plain strings to browsers.
+// Leaves are hex encoded; roots and proof hashes are in the named text
+// encoding (hex, base64url, base32 or base58), hex if it is empty.
+package wasm
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/chakra-guy/merkle"
+)
+
+var ErrUnknownScheme = errors.New("unknown scheme")
+
+// ProofElement is a proof element with its hash in the text encoding
+type ProofElement = merkle.TextElement
+
+// schemes are the built-in schemes by name; the empty name is the default tree
+var schemes = map[string]merkle.Scheme{
+	merkle.SchemeRFC6962.Name:  merkle.SchemeRFC6962,
+	merkle.SchemeBitcoin.Name:  merkle.SchemeBitcoin,
+	merkle.SchemeOZSorted.Name: merkle.SchemeOZSorted,
+	merkle.SchemeCometBFT.Name: merkle.SchemeCometBFT,
+}
+
+// Root returns the encoded root of the tree over the hex encoded leaves
+func Root(leaves []string, scheme, encoding string) (string, error) {
+	e, err := merkle.TextEncodingByName(encoding)
+	if err != nil {
+		return "", err
+	}
+	tree, err := newTree(leaves, scheme)
+	if err != nil {
+		return "", err
+	}
+	return e.EncodeToString(tree.Root()), nil
+}
+
+// Prove returns the JSON proof for the leaf at index, with encoded hashes
+func Prove(leaves []string, index int, scheme, encoding string) (string, error) {
+	e, err := merkle.TextEncodingByName(encoding)
+	if err != nil {
+		return "", err
+	}
+	tree, err := newTree(leaves, scheme)
+	if err != nil {
+		return "", err
+	}
+	proof, err := tree.GenerateProofAt(index)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(proof.Text(e))
+	return string(b), err
+}
+
+// Verify verifies a JSON proof, as returned by Prove, for the hex encoded leaf
+// against the encoded root
+func Verify(root, leaf, proof, scheme, encoding string) (bool, error) {
+	e, err := merkle.TextEncodingByName(encoding)
+	if err != nil {
+		return false, err
+	}
+	opts, err := options(scheme)
+	if err != nil {
+		return false, err
+	}
+	rootBytes, err := e.DecodeString(root)
+	if err != nil {
+		return false, fmt.Errorf("root: %w", err)
+	}
+	data, err := hex.DecodeString(leaf)
+	if err != nil {
+		return false, fmt.Errorf("leaf: %w", err)
+	}
+
+	var elements []ProofElement
+	if err := json.Unmarshal([]byte(proof), &elements); err != nil {
+		return false, fmt.Errorf("proof: %w", err)
+	}
+	p, err := merkle.ParseTextProof(elements, e)
+	if err != nil {
+		return false, fmt.Errorf("proof: %w", err)
+	}
+
+	return merkle.VerifyDataProof(rootBytes, data, p, opts...), nil
+}
+
+// newTree builds a tree over hex encoded leaves with the named scheme
+func newTree(leaves []string, scheme string) (*merkle.MerkleTree, error) {
+	opts, err := options(scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		if data[i], err = hex.DecodeString(leaf); err != nil {
+			return nil, fmt.Errorf("leaf %d: %w", i, err)
+		}
+	}
+	return merkle.New(data, opts...)
+}
+
+// options returns the tree options of the named scheme
+func options(scheme string) ([]merkle.Option, error) {
+	if scheme == "" {
+		return nil, nil
+	}
+	s, ok := schemes[scheme]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownScheme, scheme)
+	}
+	return []merkle.Option{merkle.WithScheme(s)}, nil
+}
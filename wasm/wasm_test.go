@@ -0,0 +1,80 @@
+package wasm
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Verify(t *testing.T) {
+	leaves := []string{"61", "62", "63", "64", "65"}
+
+	for _, scheme := range []string{"", "rfc6962", "oz-sorted"} {
+		t.Run("should verify proofs generated by Prove with scheme "+scheme, func(t *testing.T) {
+			root, err := Root(leaves, scheme, "")
+			require.NoError(t, err)
+
+			for i, leaf := range leaves {
+				proof, err := Prove(leaves, i, scheme, "")
+				require.NoError(t, err)
+
+				valid, err := Verify(root, leaf, proof, scheme, "")
+				require.NoError(t, err)
+				require.True(t, valid)
+
+				valid, err = Verify(root, "66", proof, scheme, "")
+				require.NoError(t, err)
+				require.False(t, valid)
+			}
+		})
+	}
+
+	t.Run("should match the library root", func(t *testing.T) {
+		tree, err := merkle.New([][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")})
+		require.NoError(t, err)
+
+		root, err := Root(leaves, "", "")
+		require.NoError(t, err)
+		require.Equal(t, hex.EncodeToString(tree.Root()), root)
+	})
+
+	t.Run("should encode proofs as hex", func(t *testing.T) {
+		proof, err := Prove(leaves[:2], 0, "rfc6962", "")
+		require.NoError(t, err)
+		require.Equal(t, `[{"hash":"`+hex.EncodeToString(merkle.SchemeRFC6962.HashLeaf([]byte("b")))+`","side":"right"}]`, proof)
+	})
+
+	t.Run("should encode roots and proofs in the named encoding", func(t *testing.T) {
+		for _, encoding := range []string{"base64url", "base32", "base58"} {
+			root, err := Root(leaves, "", encoding)
+			require.NoError(t, err)
+			e, err := merkle.TextEncodingByName(encoding)
+			require.NoError(t, err)
+			tree, err := merkle.New([][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")})
+			require.NoError(t, err)
+			require.Equal(t, e.EncodeToString(tree.Root()), root)
+
+			proof, err := Prove(leaves, 3, "", encoding)
+			require.NoError(t, err)
+			valid, err := Verify(root, leaves[3], proof, "", encoding)
+			require.NoError(t, err)
+			require.True(t, valid)
+		}
+
+		_, err := Root(leaves, "", "base62")
+		require.ErrorIs(t, err, merkle.ErrUnknownTextEncoding)
+	})
+
+	t.Run("should reject invalid input", func(t *testing.T) {
+		_, err := Root([]string{"zz"}, "", "")
+		require.ErrorIs(t, err, hex.InvalidByteError('z'))
+
+		_, err = Root(leaves, "sha1", "")
+		require.ErrorIs(t, err, ErrUnknownScheme)
+
+		_, err = Verify("00", "61", "{", "", "")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,185 @@
+// Package watch maintains a live Merkle root over the files of a directory,
+// re-hashing files as they change.
+package watch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watcher keeps a Merkle tree whose leaves are the regular files below a
+// directory, ordered by their slash-separated relative path
+type Watcher struct {
+	dir    string
+	opts   []merkle.Option
+	fs     *fsnotify.Watcher
+	leaves map[string][]byte
+
+	mu   sync.Mutex // guards tree, which scan replaces while Root reads it
+	tree *merkle.MerkleTree
+}
+
+// New hashes every file below dir and starts watching it for changes
+func New(dir string, opts ...merkle.Option) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{dir: dir, opts: opts, fs: fsw}
+	if err := w.scan(); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Close stops watching the directory
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
+
+// Root returns the current root, or nil while the directory holds no files
+func (w *Watcher) Root() []byte {
+	tree := w.Tree()
+	if tree == nil {
+		return nil
+	}
+	return tree.Root()
+}
+
+// Tree returns the current tree, or nil while the directory holds no files;
+// it must not be used concurrently with Run
+func (w *Watcher) Tree() *merkle.MerkleTree {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.tree
+}
+
+// Run applies file changes to the tree until ctx is done, calling emit with
+// the new root after each change that alters it
+func (w *Watcher) Run(ctx context.Context, emit func(root []byte) error) error {
+	for {
+		select {
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return nil
+			}
+			before := w.Root()
+			if err := w.apply(event); err != nil {
+				return err
+			}
+			if root := w.Root(); !bytes.Equal(root, before) {
+				if err := emit(root); err != nil {
+					return err
+				}
+			}
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// apply updates the tree for a file system event; content changes of known
+// files update their leaf, anything else rescans the directory
+func (w *Watcher) apply(event fsnotify.Event) error {
+	rel, err := w.rel(event.Name)
+	if err != nil {
+		return err
+	}
+
+	old, known := w.leaves[rel]
+	if known && event.Op&(fsnotify.Write|fsnotify.Chmod) != 0 {
+		content, err := os.ReadFile(event.Name)
+		if err == nil {
+			leaf := merkle.FileLeaf(rel, content)
+			w.leaves[rel] = leaf
+			return w.tree.UpdateLeaf(old, leaf)
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return w.scan()
+}
+
+// scan rebuilds the tree from every file below the directory and watches
+// every subdirectory
+func (w *Watcher) scan() error {
+	leaves := map[string][]byte{}
+	err := filepath.WalkDir(w.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return w.fs.Add(path)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := w.rel(path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		leaves[rel] = merkle.FileLeaf(rel, content)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	w.leaves = leaves
+	if len(leaves) == 0 {
+		w.setTree(nil)
+		return nil
+	}
+
+	paths := make([]string, 0, len(leaves))
+	for rel := range leaves {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	data := make([][]byte, len(paths))
+	for i, rel := range paths {
+		data[i] = leaves[rel]
+	}
+
+	tree, err := merkle.New(data, w.opts...)
+	w.setTree(tree)
+	return err
+}
+
+// setTree replaces the tree
+func (w *Watcher) setTree(tree *merkle.MerkleTree) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tree = tree
+}
+
+// rel returns the slash-separated path relative to the watched directory
+func (w *Watcher) rel(path string) (string, error) {
+	rel, err := filepath.Rel(w.dir, path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
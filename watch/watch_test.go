@@ -0,0 +1,82 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chakra-guy/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_New(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.conf"), []byte("b"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "a.conf"), []byte("a"), 0o644))
+
+	t.Run("should hash every file in path order", func(t *testing.T) {
+		w, err := New(dir)
+		require.NoError(t, err)
+		defer w.Close()
+
+		tree, err := merkle.New([][]byte{merkle.FileLeaf("b.conf", []byte("b")), merkle.FileLeaf("sub/a.conf", []byte("a"))})
+		require.NoError(t, err)
+		require.Equal(t, tree.Root(), w.Root())
+	})
+
+	t.Run("should have no root for empty directories", func(t *testing.T) {
+		w, err := New(t.TempDir())
+		require.NoError(t, err)
+		defer w.Close()
+		require.Nil(t, w.Root())
+	})
+}
+
+func Test_Run(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.conf")
+	require.NoError(t, os.WriteFile(path, []byte("v1"), 0o644))
+
+	w, err := New(dir)
+	require.NoError(t, err)
+	defer w.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	roots := make(chan []byte, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(ctx, func(root []byte) error {
+			roots <- root
+			return nil
+		})
+	}()
+
+	t.Run("should publish the root after a change", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("v2"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "new.conf"), []byte("new"), 0o644))
+
+		tree, err := merkle.New([][]byte{merkle.FileLeaf("app.conf", []byte("v2")), merkle.FileLeaf("new.conf", []byte("new"))})
+		require.NoError(t, err)
+		for {
+			// Root is read while Run replaces the tree
+			require.NotNil(t, w.Root())
+			select {
+			case root := <-roots:
+				if bytes.Equal(root, tree.Root()) {
+					return
+				}
+			case <-ctx.Done():
+				t.Fatal("root was not published")
+			}
+		}
+	})
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
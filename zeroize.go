@@ -0,0 +1,68 @@
+package merkle
+
+import (
+	"bytes"
+	"runtime"
+)
+
+// WithZeroizeOnDrop is meant for trees over credentials or personal data: the
+// tree keeps its own copies of leaf data and of the HMAC key, and overwrites
+// them, along with leaf blinds, with zeros on Close or once the tree is
+// garbage collected. Hashes are not wiped. Wiping is best effort, as the Go
+// runtime may have copied the buffers, such as when growing a slice.
+func WithZeroizeOnDrop() Option {
+	return func(m *MerkleTree) {
+		if m.zeroize {
+			return
+		}
+		m.zeroize = true
+		m.hmacKey = m.owned(m.hmacKey)
+		runtime.SetFinalizer(m, (*MerkleTree).wipe)
+	}
+}
+
+// Close releases the leaf data, blinds and key of the tree, overwriting them
+// with zeros first if WithZeroizeOnDrop is set; the tree must not be used
+// afterwards
+func (m *MerkleTree) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flushPending()
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+
+	if m.zeroize {
+		m.wipe()
+		runtime.SetFinalizer(m, nil)
+	}
+	for _, leaf := range m.leafs {
+		leaf.data, leaf.blind = nil, nil
+	}
+	m.versions = nil
+	m.hmacKey = nil
+	return nil
+}
+
+// wipe overwrites the leaf data, blinds and key with zeros
+func (m *MerkleTree) wipe() {
+	for _, leaf := range m.leafs {
+		clear(leaf.data)
+		clear(leaf.blind)
+	}
+	for _, leaf := range m.pending {
+		clear(leaf.data)
+		clear(leaf.blind)
+	}
+	wipeVersions(m.versions)
+	clear(m.hmacKey)
+}
+
+// owned returns a copy of b the tree can wipe if WithZeroizeOnDrop is set,
+// or b itself
+func (m *MerkleTree) owned(b []byte) []byte {
+	if !m.zeroize || b == nil {
+		return b
+	}
+	return bytes.Clone(b)
+}
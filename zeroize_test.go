@@ -0,0 +1,78 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithZeroizeOnDrop(t *testing.T) {
+	secret := func() [][]byte {
+		return [][]byte{[]byte("password1"), []byte("password2"), []byte("password3")}
+	}
+
+	t.Run("should wipe leaf data, blinds and keys on close", func(t *testing.T) {
+		data := secret()
+		key := []byte("hmac-key")
+		tree, err := New(data, WithHMACKey("k1", key), WithBlinding(), WithZeroizeOnDrop())
+		require.NoError(t, err)
+		tree.AddLeaf([]byte("password4"))
+		root := tree.Root()
+
+		buffers := [][]byte{tree.hmacKey}
+		for _, leaf := range tree.leafs {
+			buffers = append(buffers, leaf.data, leaf.blind)
+		}
+
+		require.NoError(t, tree.Close())
+		for _, b := range buffers {
+			require.Equal(t, make([]byte, len(b)), b)
+		}
+		require.Nil(t, tree.leafs[0].data)
+		require.Equal(t, root, tree.Root())
+
+		require.Equal(t, secret(), data, "caller buffers are not wiped")
+		require.Equal(t, []byte("hmac-key"), key)
+	})
+
+	t.Run("should wipe replaced data and rotated keys", func(t *testing.T) {
+		tree, err := New(secret(), WithZeroizeOnDrop(), WithHMACKey("k1", []byte("old-key")))
+		require.NoError(t, err)
+		old, oldKey := tree.leafs[1].data, tree.hmacKey
+
+		require.NoError(t, tree.UpdateLeaf([]byte("password2"), []byte("changed")))
+		require.Equal(t, make([]byte, len(old)), old)
+		require.NoError(t, tree.RotateKey("k2", []byte("new-key")))
+		require.Equal(t, make([]byte, len(oldKey)), oldKey)
+		require.True(t, tree.VerifyData([]byte("changed"), mustProof(t, tree, []byte("changed"))))
+	})
+
+	t.Run("should give split trees their own buffers", func(t *testing.T) {
+		tree, err := New(secret(), WithZeroizeOnDrop())
+		require.NoError(t, err)
+		left, right, err := tree.Split(1)
+		require.NoError(t, err)
+		require.True(t, right.zeroize)
+
+		require.NoError(t, tree.Close())
+		require.Equal(t, []byte("password1"), left.leafs[0].data)
+		require.Equal(t, []byte("password3"), right.leafs[1].data)
+	})
+
+	t.Run("should only drop references without the option", func(t *testing.T) {
+		data := secret()
+		tree, err := New(data)
+		require.NoError(t, err)
+		require.NoError(t, tree.Close())
+		require.Nil(t, tree.leafs[0].data)
+		require.True(t, bytes.Equal([]byte("password1"), data[0]))
+	})
+}
+
+// mustProof generates the proof of data or fails the test
+func mustProof(t *testing.T, tree *MerkleTree, data []byte) Proof {
+	proof, err := tree.GenerateProof(data)
+	require.NoError(t, err)
+	return proof
+}